@@ -0,0 +1,193 @@
+// Package audit ведёт журнал изменяющих действий (таблица audit_event).
+//
+// Сервисы записывают действие через Write в той же транзакции, что и само
+// изменение: действие попадает в журнал, только если оно зафиксировано.
+// Middleware добавляет к таким записям метод, путь, IP и User-Agent
+// запроса, а изменяющий запрос, для которого сервис ничего не записал,
+// журналирует после ответа как ActionRequest. Повтор ответа по ключу
+// идемпотентности не журналируется: действие уже записано при первом
+// запросе.
+package audit
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	ActionCreate   = "create"
+	ActionEdit     = "edit"
+	ActionStatus   = "status"
+	ActionRollback = "rollback"
+	ActionDecision = "decision"
+	ActionFeedback = "feedback"
+	ActionUnseal   = "unseal"
+	ActionOwners   = "owners"
+	ActionTransfer = "transfer"
+	ActionCriteria = "criteria"
+	ActionScore    = "score"
+	ActionDelete   = "delete"
+	// ActionDeactivate — деактивация сотрудника.
+	ActionDeactivate = "deactivate"
+	// ActionAddResponsible и ActionRemoveResponsible записываются для
+	// организации.
+	ActionAddResponsible    = "add_responsible"
+	ActionRemoveResponsible = "remove_responsible"
+	// ActionRetry — возврат мёртвой доставки вебхука в очередь.
+	ActionRetry = "retry"
+	// ActionRequest — изменяющий запрос, для которого сервис не записал
+	// действие.
+	ActionRequest = "request"
+
+	EntityTender          = "tender"
+	EntityBid             = "bid"
+	EntityOrganization    = "organization"
+	EntityEmployee        = "employee"
+	EntityAttachment      = "attachment"
+	EntityWebhook         = "webhook"
+	EntityWebhookDelivery = "webhook_delivery"
+)
+
+const skipKey = "auditSkip"
+
+// Event — действие для журнала. Нулевая версия означает, что версии у
+// действия нет. Actor — кто выполнил действие; nil для планировщика.
+type Event struct {
+	Action        string
+	EntityType    string
+	EntityID      uuid.UUID
+	VersionBefore int
+	VersionAfter  int
+	Actor         *models.Employee
+}
+
+// request — сведения об HTTP-запросе, от имени которого пишутся записи.
+// written отмечает, что сервис уже записал действия запроса.
+type request struct {
+	method    string
+	path      string
+	ip        string
+	userAgent string
+	written   bool
+}
+
+type requestKey struct{}
+
+// Write сохраняет события через repositories. Внутри
+// repositories.Transaction запись фиксируется и откатывается вместе с
+// изменением.
+func Write(ctx context.Context, repositories repository.Repositories, events ...Event) error {
+	req, _ := ctx.Value(requestKey{}).(*request)
+
+	records := make([]models.AuditEvent, 0, len(events))
+	for _, event := range events {
+		record := newRecord(event)
+		if req != nil {
+			// Сервис возвращает ошибку вместо успешного ответа, поэтому
+			// зафиксированное действие соответствует ответу 200.
+			record.Method = req.method
+			record.Path = req.path
+			record.Status = http.StatusOK
+			record.IP = req.ip
+			record.UserAgent = req.userAgent
+		}
+		records = append(records, record)
+	}
+
+	if err := repositories.Audit.Create(ctx, records); err != nil {
+		return err
+	}
+	if req != nil {
+		req.written = true
+	}
+	return nil
+}
+
+// Skip исключает текущий запрос из журнала.
+func Skip(c *gin.Context) {
+	c.Set(skipKey, true)
+}
+
+// Middleware передаёт сведения об изменяющих запросах (POST, PUT, PATCH,
+// DELETE) в контекст запроса для Write. Если запрос завершился с кодом
+// меньше 400, а сервис ничего не записал, сохраняет ActionRequest.
+// Ошибка записи журнала не влияет на уже отправленный ответ.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		req := &request{
+			method:    c.Request.Method,
+			path:      c.Request.URL.RequestURI(),
+			ip:        c.ClientIP(),
+			userAgent: c.Request.UserAgent(),
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestKey{}, req))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusBadRequest || req.written || c.GetBool(skipKey) {
+			return
+		}
+
+		// utils.GetRepositories здесь не подходит: ответ уже отправлен.
+		value, _ := c.Get("repositories")
+		repositories, ok := value.(repository.Repositories)
+		if !ok {
+			return
+		}
+
+		record := newRecord(Event{Action: ActionRequest, Actor: auth.OptionalEmployee(c)})
+		record.Method = req.method
+		record.Path = req.path
+		record.Status = status
+		record.IP = req.ip
+		record.UserAgent = req.userAgent
+
+		// Клиент мог уже отключиться, но запись о выполненном запросе нужна.
+		ctx := context.WithoutCancel(c.Request.Context())
+		if err := repositories.Audit.Create(ctx, []models.AuditEvent{record}); err != nil {
+			log.Printf("Failed to write audit event for %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+	}
+}
+
+func newRecord(event Event) models.AuditEvent {
+	record := models.AuditEvent{
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		CreatedAt:  time.Now(),
+	}
+	if event.Actor != nil {
+		actorID := event.Actor.ID
+		record.ActorID = &actorID
+		record.ActorUsername = event.Actor.Username
+	}
+	if event.EntityID != uuid.Nil {
+		entityID := event.EntityID
+		record.EntityID = &entityID
+	}
+	if event.VersionBefore > 0 {
+		before := event.VersionBefore
+		record.VersionBefore = &before
+	}
+	if event.VersionAfter > 0 {
+		after := event.VersionAfter
+		record.VersionAfter = &after
+	}
+	return record
+}
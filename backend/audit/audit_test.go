@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newRouter(repositories repository.Repositories) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("repositories", repositories)
+		c.Next()
+	})
+	r.Use(Middleware())
+	return r
+}
+
+func records(t *testing.T, repositories repository.Repositories) []models.AuditEvent {
+	t.Helper()
+	result, err := repositories.Audit.List(context.Background(), repository.AuditFilter{}, pagination.Page{Limit: pagination.MaxLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Items
+}
+
+func TestMiddleware(t *testing.T) {
+	entityID := uuid.New()
+	actor := &models.Employee{ID: uuid.New(), Username: "alice"}
+
+	tests := []struct {
+		name    string
+		method  string
+		handler gin.HandlerFunc
+		want    []string
+	}{
+		{
+			name:   "action written by the service",
+			method: http.MethodPatch,
+			handler: func(c *gin.Context) {
+				repositories := c.MustGet("repositories").(repository.Repositories)
+				err := repositories.Transaction(c.Request.Context(), func(r repository.Repositories) error {
+					return Write(c.Request.Context(), r, Event{Action: ActionEdit, EntityType: EntityTender, EntityID: entityID, Actor: actor})
+				})
+				if err != nil {
+					c.Status(http.StatusInternalServerError)
+					return
+				}
+				c.Status(http.StatusOK)
+			},
+			want: []string{ActionEdit},
+		},
+		{
+			name:   "rolled back action",
+			method: http.MethodPatch,
+			handler: func(c *gin.Context) {
+				repositories := c.MustGet("repositories").(repository.Repositories)
+				_ = repositories.Transaction(c.Request.Context(), func(r repository.Repositories) error {
+					if err := Write(c.Request.Context(), r, Event{Action: ActionEdit, EntityType: EntityTender, EntityID: entityID}); err != nil {
+						return err
+					}
+					return errors.New("conflict")
+				})
+				c.Status(http.StatusConflict)
+			},
+		},
+		{
+			name:    "request without a service action",
+			method:  http.MethodPost,
+			handler: func(c *gin.Context) { c.Status(http.StatusOK) },
+			want:    []string{ActionRequest},
+		},
+		{
+			name:    "failed request",
+			method:  http.MethodPost,
+			handler: func(c *gin.Context) { c.Status(http.StatusBadRequest) },
+		},
+		{
+			name:    "read request",
+			method:  http.MethodGet,
+			handler: func(c *gin.Context) { c.Status(http.StatusOK) },
+		},
+		{
+			name:   "idempotent replay",
+			method: http.MethodPost,
+			handler: func(c *gin.Context) {
+				Skip(c)
+				c.Status(http.StatusOK)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repositories := repository.NewMemory()
+			router := newRouter(repositories)
+			router.Handle(test.method, "/tenders/:id", test.handler)
+
+			request := httptest.NewRequest(test.method, "/tenders/1?x=y", nil)
+			request.Header.Set("User-Agent", "audit-test")
+			router.ServeHTTP(httptest.NewRecorder(), request)
+
+			got := records(t, repositories)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d records, want %v", len(got), test.want)
+			}
+			for i, record := range got {
+				if record.Action != test.want[i] {
+					t.Fatalf("record %d action %s, want %s", i, record.Action, test.want[i])
+				}
+				if record.Method != test.method || record.Path != "/tenders/1?x=y" || record.UserAgent != "audit-test" || record.Status != http.StatusOK {
+					t.Fatalf("record %d request fields %+v", i, record)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteOutsideRequest(t *testing.T) {
+	repositories := repository.NewMemory()
+	entityID := uuid.New()
+
+	err := Write(context.Background(), repositories, Event{Action: ActionUnseal, EntityType: EntityTender, EntityID: entityID, VersionBefore: 1, VersionAfter: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := records(t, repositories)
+	if len(got) != 1 {
+		t.Fatalf("got %d records", len(got))
+	}
+	record := got[0]
+	if record.Method != "" || record.ActorID != nil || *record.EntityID != entityID || *record.VersionBefore != 1 || *record.VersionAfter != 2 {
+		t.Fatalf("record %+v", record)
+	}
+}
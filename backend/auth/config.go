@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// AllowUsernameParam включает старый режим, в котором вызывающий
+	// определяется по параметру ?username= без токена.
+	AllowUsernameParam bool
+}
+
+func LoadConfig() Config {
+	cfg := Config{
+		Secret:     []byte(os.Getenv("AUTH_SECRET")),
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 30 * 24 * time.Hour,
+	}
+
+	if len(cfg.Secret) == 0 {
+		log.Println("AUTH_SECRET is not set, tokens will not survive a restart")
+		cfg.Secret = randomSecret()
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("AUTH_ACCESS_TTL")); err == nil && ttl > 0 {
+		cfg.AccessTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("AUTH_REFRESH_TTL")); err == nil && ttl > 0 {
+		cfg.RefreshTTL = ttl
+	}
+	if allow, err := strconv.ParseBool(os.Getenv("AUTH_ALLOW_USERNAME_PARAM")); err == nil {
+		cfg.AllowUsernameParam = allow
+	}
+
+	return cfg
+}
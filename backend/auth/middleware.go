@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	managerKey  = "auth"
+	employeeKey = "employee"
+)
+
+// Параметры запроса, по которым вызывающий определялся до появления токенов.
+var legacyUsernameParams = []string{"username", "requesterUsername"}
+
+// Middleware определяет вызывающего сотрудника по заголовку
+// Authorization: Bearer <token> и кладёт его в контекст запроса.
+// Запросы без токена пропускаются дальше: обработчики сами решают,
+// нужен ли им вызывающий, через CurrentEmployee.
+func Middleware(m *Manager, employees repository.EmployeeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(managerKey, m)
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			if m.AllowUsernameParam() {
+				resolveLegacyUsername(c, employees)
+			}
+			c.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"reason": "Invalid authorization header"})
+			return
+		}
+
+		employeeID, err := m.ParseAccess(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"reason": err.Error()})
+			return
+		}
+
+		employee, err := employees.FindByID(c.Request.Context(), employeeID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+			return
+		}
+
+		if !employee.IsActive() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"reason": ErrDeactivated.Error()})
+			return
+		}
+
+		c.Set(employeeKey, &employee)
+		c.Next()
+	}
+}
+
+var ErrDeactivated = errors.New("employee is deactivated")
+
+func resolveLegacyUsername(c *gin.Context, employees repository.EmployeeRepository) {
+	for _, param := range legacyUsernameParams {
+		username := c.Query(param)
+		if username == "" {
+			continue
+		}
+
+		employee, err := employees.FindByUsername(c.Request.Context(), username)
+		if err != nil {
+			return
+		}
+
+		if !employee.IsActive() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"reason": ErrDeactivated.Error()})
+			return
+		}
+
+		c.Set(employeeKey, &employee)
+		return
+	}
+}
+
+// CurrentEmployee возвращает аутентифицированного сотрудника.
+// Если вызывающий не определён, отвечает 401 и возвращает false.
+func CurrentEmployee(c *gin.Context) (*models.Employee, bool) {
+	if employee := OptionalEmployee(c); employee != nil {
+		return employee, true
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	return nil, false
+}
+
+// OptionalEmployee возвращает вызывающего сотрудника или nil для
+// анонимного запроса, не отвечая клиенту.
+func OptionalEmployee(c *gin.Context) *models.Employee {
+	if employee, ok := c.Get(employeeKey); ok {
+		if e, ok := employee.(*models.Employee); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// LegacyEnabled сообщает, разрешено ли определять вызывающего по полям
+// запроса (creatorUsername, authorId) при отсутствии токена.
+func LegacyEnabled(c *gin.Context) bool {
+	if _, authenticated := c.Get(employeeKey); authenticated {
+		return false
+	}
+	m, ok := GetManager(c)
+	return ok && m.AllowUsernameParam()
+}
+
+func GetManager(c *gin.Context) (*Manager, bool) {
+	value, exists := c.Get(managerKey)
+	if !exists {
+		return nil, false
+	}
+	m, ok := value.(*Manager)
+	return m, ok
+}
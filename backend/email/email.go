@@ -0,0 +1,67 @@
+// Package email отправляет сотрудникам письма-уведомления.
+//
+// Письма отрисовываются из шаблонов html/template на языке сотрудника,
+// ставятся в очередь (таблица email_message) в транзакции события и
+// отправляются Worker через Mailer с повторами при ошибках.
+package email
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Message — готовое к отправке письмо.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+}
+
+// Mailer доставляет письмо получателю.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// FromEnv создаёт Mailer по MAIL_BACKEND: smtp или log (по умолчанию —
+// только пишет письма в журнал).
+func FromEnv() (Mailer, error) {
+	switch backend := os.Getenv("MAIL_BACKEND"); backend {
+	case "", "log":
+		return LogMailer{}, nil
+	case "smtp":
+		mailer := &SMTPMailer{
+			Addr:     os.Getenv("SMTP_ADDR"),
+			From:     os.Getenv("MAIL_FROM"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			Timeout:  30 * time.Second,
+		}
+		if mailer.Addr == "" || mailer.From == "" {
+			return nil, fmt.Errorf("SMTP_ADDR and MAIL_FROM are required for the smtp mail backend")
+		}
+		return mailer, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_BACKEND %q", backend)
+	}
+}
+
+// IntervalFromEnv возвращает период отправки очереди из MAIL_INTERVAL
+// (по умолчанию 10 секунд).
+func IntervalFromEnv() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("MAIL_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 10 * time.Second
+	}
+	return interval
+}
+
+// LogMailer не отправляет письма, а пишет их заголовки в журнал.
+type LogMailer struct{}
+
+func (LogMailer) Send(_ context.Context, message Message) error {
+	log.Printf("Email to %s: %s", message.To, message.Subject)
+	return nil
+}
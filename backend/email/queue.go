@@ -0,0 +1,81 @@
+package email
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/retry"
+	"context"
+	"time"
+)
+
+// Enqueue отрисовывает письмо сотруднику и ставит его в очередь.
+// Сотрудникам без адреса и деактивированным письма не ставятся.
+func Enqueue(ctx context.Context, repositories repository.Repositories, employee models.Employee, name string, data Data) error {
+	if employee.Email == nil || *employee.Email == "" || !employee.IsActive() {
+		return nil
+	}
+
+	data.Employee = employee
+	subject, body, err := Render(name, employee.Language, data)
+	if err != nil {
+		return err
+	}
+
+	return repositories.Emails.Enqueue(ctx, &models.EmailMessage{
+		EmployeeID:    &employee.ID,
+		To:            *employee.Email,
+		Template:      name,
+		Subject:       subject,
+		Body:          body,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+	})
+}
+
+// Worker отправляет письма из очереди с повторами при ошибках.
+type Worker struct {
+	retry.Worker[models.EmailMessage]
+	Mailer Mailer
+}
+
+// NewWorker создаёт обработчик очереди с настройками по умолчанию.
+func NewWorker(repositories repository.Repositories, mailer Mailer) *Worker {
+	w := &Worker{Mailer: mailer}
+	w.Worker = retry.Worker[models.EmailMessage]{
+		Name:        "email",
+		Queue:       queue{repositories.Emails},
+		Run:         w.send,
+		MaxAttempts: 6,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+		Lease:       2 * time.Minute,
+		BatchSize:   20,
+	}
+	return w
+}
+
+// Start запускает цикл отправки и возвращается после отмены ctx.
+func (w *Worker) Start(ctx context.Context, interval time.Duration) {
+	retry.Every(ctx, interval, "Email worker", w.RunOnce)
+}
+
+func (w *Worker) send(ctx context.Context, message models.EmailMessage) (int, error) {
+	return 0, w.Mailer.Send(ctx, Message{To: message.To, Subject: message.Subject, HTML: message.Body})
+}
+
+// queue — очередь писем в хранилище.
+type queue struct {
+	emails repository.EmailRepository
+}
+
+func (q queue) Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.EmailMessage, error) {
+	return q.emails.Lease(ctx, now, until, limit)
+}
+
+func (q queue) Record(ctx context.Context, message models.EmailMessage, attempt repository.Attempt) error {
+	return q.emails.Record(ctx, message.ID, attempt)
+}
+
+func (queue) Attempts(message models.EmailMessage) int {
+	return message.Attempts
+}
@@ -0,0 +1,165 @@
+package email
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+var ctx = context.Background()
+
+// fakeSMTP — SMTP-сервер, который принимает письма в received. При
+// reject он отклоняет получателя.
+func fakeSMTP(t *testing.T, reject bool) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(textproto.NewConn(conn), reject, received)
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func serveSMTP(conn *textproto.Conn, reject bool, received chan<- string) {
+	defer conn.Close()
+
+	conn.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.Fields(line + " ")[0]); command {
+		case "EHLO", "HELO":
+			conn.PrintfLine("250 localhost")
+		case "RCPT":
+			if reject {
+				conn.PrintfLine("550 No such user")
+				continue
+			}
+			conn.PrintfLine("250 OK")
+		case "DATA":
+			conn.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			received <- string(data)
+			conn.PrintfLine("250 OK")
+		case "QUIT":
+			conn.PrintfLine("221 Bye")
+			return
+		default:
+			conn.PrintfLine("250 OK")
+		}
+	}
+}
+
+func newQueue(t *testing.T, addr string) (repository.Repositories, *Worker) {
+	t.Helper()
+	repositories := repository.NewMemory()
+	worker := NewWorker(repositories, &SMTPMailer{Addr: addr, From: "tenders@example.com", Timeout: 5 * time.Second})
+
+	err := repositories.Emails.Enqueue(ctx, &models.EmailMessage{
+		To:            "alice@example.com",
+		Template:      "bid_created",
+		Subject:       "New bid",
+		Body:          "<p>A new bid was submitted</p>",
+		NextAttemptAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repositories, worker
+}
+
+// pending возвращает неотправленные письма, которые ещё будут отправлены,
+// и делает их доступными для отправки сейчас, как будто прошла задержка.
+func pending(t *testing.T, repositories repository.Repositories) []models.EmailMessage {
+	t.Helper()
+	messages, err := repositories.Emails.Lease(ctx, time.Now().Add(24*time.Hour), time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+func TestWorkerSendsThroughSMTP(t *testing.T) {
+	addr, received := fakeSMTP(t, false)
+	repositories, worker := newQueue(t, addr)
+
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-received:
+		for _, want := range []string{"To: <alice@example.com>", "Subject: New bid", "A new bid was submitted"} {
+			if !strings.Contains(data, want) {
+				t.Fatalf("message has no %q:\n%s", want, data)
+			}
+		}
+	default:
+		t.Fatal("server received no message")
+	}
+
+	if messages := pending(t, repositories); len(messages) != 0 {
+		t.Fatalf("sent message is still queued: %+v", messages)
+	}
+}
+
+func TestWorkerRetriesRejectedMessage(t *testing.T) {
+	addr, received := fakeSMTP(t, true)
+	repositories, worker := newQueue(t, addr)
+	worker.MaxAttempts = 2
+
+	start := time.Now()
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := repositories.Emails.Lease(ctx, start.Add(worker.BaseDelay-time.Second), time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 0 {
+		t.Fatal("message is retried before its backoff")
+	}
+
+	messages := pending(t, repositories)
+	if len(messages) != 1 {
+		t.Fatalf("%d queued messages, want 1", len(messages))
+	}
+	message := messages[0]
+	if message.Attempts != 1 || !strings.Contains(message.LastError, "550") || message.SentAt != nil {
+		t.Fatalf("message after failed attempt %+v", message)
+	}
+
+	// pending сделал письмо доступным сейчас. Вторая попытка — последняя:
+	// после неё письмо больше не отправляется.
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if messages := pending(t, repositories); len(messages) != 0 {
+		t.Fatalf("message is retried after the last attempt: %+v", messages)
+	}
+	if len(received) != 0 {
+		t.Fatal("rejected message was delivered")
+	}
+}
@@ -0,0 +1,80 @@
+package email
+
+import (
+	"ZADANIE-6105/models"
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"strings"
+)
+
+const (
+	TemplateTenderPublished = "tender.published"
+	TemplateBidDecision     = "bid.decision"
+	TemplateBidFeedback     = "bid.feedback"
+
+	// DefaultLanguage — язык, если у сотрудника не задан или для его языка
+	// нет шаблона.
+	DefaultLanguage = "ru"
+)
+
+// Шаблон называется <имя>.<язык>.html и определяет блоки subject и body.
+//
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var templates = mustParseTemplates()
+
+// Data — данные шаблона письма.
+type Data struct {
+	Employee models.Employee
+	Tender   models.Tender
+	Bid      models.Bid
+	Decision string
+	Feedback string
+}
+
+func mustParseTemplates() map[string]*template.Template {
+	paths, err := fs.Glob(templateFiles, "templates/*.html")
+	if err != nil {
+		panic(err)
+	}
+
+	parsed := make(map[string]*template.Template, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(path, "templates/"), ".html")
+		parsed[name] = template.Must(template.ParseFS(templateFiles, path))
+	}
+	return parsed
+}
+
+// HasTemplate сообщает, есть ли шаблон с таким именем.
+func HasTemplate(name string) bool {
+	_, ok := templates[name+"."+DefaultLanguage]
+	return ok
+}
+
+// Render отрисовывает тему и HTML-тело письма на языке language.
+func Render(name string, language string, data Data) (string, string, error) {
+	tmpl, ok := templates[name+"."+language]
+	if !ok {
+		tmpl, ok = templates[name+"."+DefaultLanguage]
+	}
+	if !ok {
+		return "", "", fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", err
+	}
+
+	// Тема — заголовок письма, а не HTML: экранирование не нужно.
+	return html.UnescapeString(strings.TrimSpace(subject.String())), body.String(), nil
+}
@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/service"
+	"ZADANIE-6105/storage"
+	"ZADANIE-6105/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var attachmentLimits = storage.LimitsFromEnv()
+
+func UploadTenderAttachment(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
+		return
+	}
+
+	if !canManageTender(c, repositories, tender, employee, "Unauthorized to update this tender") {
+		return
+	}
+
+	uploadAttachment(c, repositories, models.AttachmentEntityTender, tender.ID, employee)
+}
+
+func GetTenderAttachments(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := viewableTender(c)
+	if !ok {
+		return
+	}
+
+	listAttachments(c, repositories.Attachments, models.AttachmentEntityTender, tender.ID)
+}
+
+func DownloadTenderAttachment(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := viewableTender(c)
+	if !ok {
+		return
+	}
+
+	downloadAttachment(c, repositories.Attachments, models.AttachmentEntityTender, tender.ID)
+}
+
+func DeleteTenderAttachment(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
+		return
+	}
+
+	if !canManageTender(c, repositories, tender, employee, "Unauthorized to update this tender") {
+		return
+	}
+
+	deleteAttachment(c, repositories, models.AttachmentEntityTender, tender.ID, employee)
+}
+
+// UploadBidAttachment прикладывает файл к предложению. Загружать файлы
+// может только автор и только пока тендер принимает предложения.
+func UploadBidAttachment(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	bid, ok := editableBid(c, repositories, employee)
+	if !ok {
+		return
+	}
+
+	uploadAttachment(c, repositories, models.AttachmentEntityBid, bid.ID, employee)
+}
+
+func GetBidAttachments(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	bid, ok := viewableBid(c)
+	if !ok {
+		return
+	}
+
+	listAttachments(c, repositories.Attachments, models.AttachmentEntityBid, bid.ID)
+}
+
+func DownloadBidAttachment(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	bid, ok := viewableBid(c)
+	if !ok {
+		return
+	}
+
+	downloadAttachment(c, repositories.Attachments, models.AttachmentEntityBid, bid.ID)
+}
+
+func DeleteBidAttachment(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	bid, ok := editableBid(c, repositories, employee)
+	if !ok {
+		return
+	}
+
+	deleteAttachment(c, repositories, models.AttachmentEntityBid, bid.ID, employee)
+}
+
+func viewableTender(c *gin.Context) (models.Tender, bool) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return models.Tender{}, false
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
+		return tender, false
+	}
+
+	viewer, ok := loadViewer(c, auth.OptionalEmployee(c))
+	if !ok {
+		return tender, false
+	}
+
+	if !viewer.CanViewTender(tender) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to view this tender"})
+		return tender, false
+	}
+
+	return tender, true
+}
+
+// viewableBid загружает предложение из c.GetString("bidId") и проверяет
+// доступ к нему. Пока тендер запечатан, файлы видит только автор.
+func viewableBid(c *gin.Context) (models.Bid, bool) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return models.Bid{}, false
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return models.Bid{}, false
+	}
+
+	bid, ok := findBid(c, repositories.Bids, c.GetString("bidId"))
+	if !ok {
+		return bid, false
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, bid.TenderID.String())
+	if !ok {
+		return bid, false
+	}
+
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
+		return bid, false
+	}
+
+	if !viewer.CanViewBid(bid, tender) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to access this bid"})
+		return bid, false
+	}
+
+	if tender.IsSealed(time.Now()) && bid.AuthorID != employee.ID {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Bids are sealed until the submission deadline"})
+		return bid, false
+	}
+
+	return bid, true
+}
+
+func editableBid(c *gin.Context, repositories repository.Repositories, employee *models.Employee) (models.Bid, bool) {
+	bid, ok := findBid(c, repositories.Bids, c.Param("bidId"))
+	if !ok {
+		return bid, false
+	}
+
+	if bid.AuthorID != employee.ID {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to edit this bid"})
+		return bid, false
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, bid.TenderID.String())
+	if !ok {
+		return bid, false
+	}
+
+	if err := service.AcceptingBids(tender); err != nil {
+		respondError(c, err, "Failed to retrieve tender")
+		return bid, false
+	}
+
+	return bid, true
+}
+
+// uploadAttachment сохраняет файл из поля формы "file". Тип файла
+// определяется по содержимому, а не по имени или заголовку клиента.
+// SHA-256 считается по ходу записи в хранилище.
+func uploadAttachment(c *gin.Context, repositories repository.Repositories, entityType string, entityID uuid.UUID, uploader *models.Employee) {
+	store, ok := utils.GetStorage(c)
+	if !ok {
+		return
+	}
+
+	// Запас в 1 МБ на заголовки и прочие поля multipart-формы.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, attachmentLimits.MaxSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"reason": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "File is required"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 3072)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Failed to read file"})
+		return
+	}
+	head = head[:n]
+
+	contentType, err := attachmentLimits.Check(header.Size, head)
+	switch {
+	case errors.Is(err, storage.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"reason": "File is too large"})
+		return
+	case errors.Is(err, storage.ErrEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "File is empty"})
+		return
+	case errors.Is(err, storage.ErrTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"reason": "File type " + contentType.String() + " is not allowed"})
+		return
+	}
+
+	attachment := models.Attachment{
+		ID:          uuid.New(),
+		EntityType:  entityType,
+		EntityID:    entityID,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType.String(),
+		Size:        header.Size,
+		UploadedBy:  uploader.ID,
+		CreatedAt:   time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("%ss/%s/%s", entityType, entityID, attachment.ID)
+
+	hash := sha256.New()
+	content := io.TeeReader(io.MultiReader(bytes.NewReader(head), file), hash)
+	if err := store.Put(c.Request.Context(), attachment.StorageKey, content, header.Size, attachment.ContentType); err != nil {
+		log.Printf("Failed to store attachment %s: %v", attachment.StorageKey, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to store file"})
+		return
+	}
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	ctx := c.Request.Context()
+	err = repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Attachments.Create(ctx, &attachment); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionCreate,
+			EntityType: audit.EntityAttachment,
+			EntityID:   attachment.ID,
+			Actor:      uploader,
+		})
+	})
+	if err != nil {
+		if err := store.Delete(c.Request.Context(), attachment.StorageKey); err != nil {
+			log.Printf("Failed to remove orphaned attachment %s: %v", attachment.StorageKey, err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to save attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newAttachmentResponse(attachment))
+}
+
+func listAttachments(c *gin.Context, attachments repository.AttachmentRepository, entityType string, entityID uuid.UUID) {
+	list, err := attachments.ListByEntity(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve attachments"})
+		return
+	}
+
+	responses := make([]schemas.AttachmentResponse, 0, len(list))
+	for _, attachment := range list {
+		responses = append(responses, newAttachmentResponse(attachment))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func downloadAttachment(c *gin.Context, attachments repository.AttachmentRepository, entityType string, entityID uuid.UUID) {
+	store, ok := utils.GetStorage(c)
+	if !ok {
+		return
+	}
+
+	attachment, ok := findAttachment(c, attachments, entityType, entityID)
+	if !ok {
+		return
+	}
+
+	content, err := store.Get(c.Request.Context(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Attachment content not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to read attachment %s: %v", attachment.StorageKey, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to read file"})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Checksum-SHA256":   attachment.SHA256,
+		"ETag":                strconv.Quote(attachment.SHA256),
+	})
+}
+
+func deleteAttachment(c *gin.Context, repositories repository.Repositories, entityType string, entityID uuid.UUID, employee *models.Employee) {
+	store, ok := utils.GetStorage(c)
+	if !ok {
+		return
+	}
+
+	attachment, ok := findAttachment(c, repositories.Attachments, entityType, entityID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Attachments.Delete(ctx, attachment.ID); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionDelete,
+			EntityType: audit.EntityAttachment,
+			EntityID:   attachment.ID,
+			Actor:      employee,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to delete attachment"})
+		return
+	}
+
+	// Запись уже удалена, поэтому ошибка хранилища оставляет лишь
+	// недоступный файл и не влияет на ответ.
+	if err := store.Delete(c.Request.Context(), attachment.StorageKey); err != nil {
+		log.Printf("Failed to remove attachment %s: %v", attachment.StorageKey, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+func findAttachment(c *gin.Context, attachments repository.AttachmentRepository, entityType string, entityID uuid.UUID) (models.Attachment, bool) {
+	id, ok := parseID(c, c.Param("attachmentId"), "Attachment not found")
+	if !ok {
+		return models.Attachment{}, false
+	}
+
+	attachment, err := attachments.Find(c.Request.Context(), entityType, entityID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Attachment not found"})
+		return attachment, false
+	}
+
+	return attachment, true
+}
+
+func newAttachmentResponse(attachment models.Attachment) schemas.AttachmentResponse {
+	return schemas.AttachmentResponse{
+		ID:          attachment.ID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		SHA256:      attachment.SHA256,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
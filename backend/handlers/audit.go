@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetAuditEvents возвращает журнал аудита, новые события первыми.
+// Доступен только администраторам. Фильтры: entityType, entityId,
+// actorId, actorUsername, action и диапазон from/to по времени события.
+func GetAuditEvents(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	if !employee.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Only administrators can view the audit log"})
+		return
+	}
+
+	page, ok := pagination.FromQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := repository.AuditFilter{
+		EntityType:    c.Query("entityType"),
+		ActorUsername: c.Query("actorUsername"),
+		Action:        c.Query("action"),
+	}
+
+	for param, target := range map[string]**uuid.UUID{"entityId": &filter.EntityID, "actorId": &filter.ActorID} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid " + param + " value"})
+			return
+		}
+		*target = &id
+	}
+
+	from, _, err := parseDateParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid from value"})
+		return
+	}
+	filter.From = from
+
+	to, toDate, err := parseDateParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid to value"})
+		return
+	}
+	if to != nil {
+		if toDate {
+			before := to.AddDate(0, 0, 1)
+			filter.Before = &before
+		} else {
+			filter.To = to
+		}
+	}
+
+	result, err := repositories.Audit.List(c.Request.Context(), filter, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve audit events")
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.Respond(c, result))
+}
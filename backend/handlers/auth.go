@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Login(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	var request schemas.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	employee, err := repositories.Employees.FindByUsername(c.Request.Context(), request.Username)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid username or password"})
+		return
+	}
+
+	if !auth.CheckPassword(employee.PasswordHash, request.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid username or password"})
+		return
+	}
+
+	issueTokens(c, &employee)
+}
+
+func RefreshToken(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	manager, ok := auth.GetManager(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "authentication is not configured"})
+		return
+	}
+
+	var request schemas.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	employeeID, err := manager.ParseRefresh(request.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": err.Error()})
+		return
+	}
+
+	employee, err := repositories.Employees.FindByID(c.Request.Context(), employeeID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+		return
+	}
+
+	issueTokens(c, &employee)
+}
+
+func issueTokens(c *gin.Context, employee *models.Employee) {
+	if !employee.IsActive() {
+		c.JSON(http.StatusForbidden, gin.H{"reason": auth.ErrDeactivated.Error()})
+		return
+	}
+
+	manager, ok := auth.GetManager(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "authentication is not configured"})
+		return
+	}
+
+	pair, err := manager.Issue(employee.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to issue token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.TokenResponse{
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+	})
+}
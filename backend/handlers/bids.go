@@ -1,147 +1,166 @@
 package handlers
 
 import (
+	"ZADANIE-6105/auth"
 	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
 	"ZADANIE-6105/schemas"
 	"ZADANIE-6105/utils"
+	"errors"
 
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-func CreateBid(c *gin.Context) {
-	database, ok := utils.GetDB(c)
-	if !ok {
-		return
+// callerByID возвращает аутентифицированного сотрудника и проверяет, что
+// authorId из тела запроса (если передан) совпадает с ним. В режиме
+// совместимости запрос без токена может назвать автора по authorId.
+func callerByID(c *gin.Context, employees repository.EmployeeRepository, authorID string) (*models.Employee, bool) {
+	if !auth.LegacyEnabled(c) {
+		employee, ok := auth.CurrentEmployee(c)
+		if !ok {
+			return nil, false
+		}
+		if authorID != "" && authorID != employee.ID.String() {
+			c.JSON(http.StatusForbidden, gin.H{"reason": "authorId does not match the authenticated user"})
+			return nil, false
+		}
+		return employee, true
 	}
 
-	var bidInput schemas.BidCreateRequest
-
-	if err := c.ShouldBindJSON(&bidInput); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	id, err := uuid.Parse(authorID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Unauthorized, user does not exist"})
+		return nil, false
 	}
 
-	tenderID, err := uuid.Parse(bidInput.TenderID)
+	employee, err := employees.FindByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid TenderID format"})
-		return
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Unauthorized, user does not exist"})
+		return nil, false
+	}
+
+	if !employee.IsActive() {
+		c.JSON(http.StatusForbidden, gin.H{"reason": auth.ErrDeactivated.Error()})
+		return nil, false
 	}
+	return &employee, true
+}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", tenderID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusBadRequest, gin.H{"reason": "Tender with the given ID does not exist"})
+// newVisibleBidResponse скрывает название предложения запечатанного тендера
+// от всех, кроме автора.
+func newVisibleBidResponse(bid models.Bid, tender models.Tender, employeeID uuid.UUID) schemas.BidCreateResponse {
+	response := schemas.NewBidResponse(bid)
+	if tender.IsSealed(time.Now()) && bid.AuthorID != employeeID {
+		response.Name = ""
+		response.Price = nil
+		response.Currency = ""
+		response.Sealed = true
+	}
+	return response
+}
+
+// findBid загружает предложение по ID. Некорректный ID, как и
+// отсутствующее предложение, даёт 404.
+func findBid(c *gin.Context, bids repository.BidRepository, id string) (models.Bid, bool) {
+	bidID, ok := parseID(c, id, "Bid not found")
+	if !ok {
+		return models.Bid{}, false
+	}
+
+	bid, err := bids.FindByID(c.Request.Context(), bidID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Database error while checking tender"})
+			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bid"})
 		}
-		return
+		return bid, false
 	}
 
-	var employee models.Employee
-	if err := database.Where("id = ?", bidInput.AuthorID).First(&employee).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Unauthorized, user does not exist"})
+	return bid, true
+}
+
+func CreateBid(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
 		return
 	}
 
-	if bidInput.AuthorType == "Organization" {
-		var orgResponsible models.OrganizationResponsible
-		if err := database.Where("organization_id = ? AND employee_id = ?", tender.OrganizationID, employee.ID).First(&orgResponsible).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to create bid as Organization"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Database error while checking organization responsible"})
-			return
-		}
+	services, ok := utils.GetServices(c)
+	if !ok {
+		return
 	}
 
-	bid := models.Bid{
-		Name:        bidInput.Name,
-		Description: bidInput.Description,
-		TenderID:    tenderID,
-		AuthorType:  bidInput.AuthorType,
-		AuthorID:    employee.ID,
-		Version:     1,
-		Status:      "Created",
-		CreatedAt:   time.Now(),
+	var bidInput schemas.BidCreateRequest
+
+	if err := c.ShouldBindJSON(&bidInput); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := database.Create(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to create bid"})
+	employee, ok := callerByID(c, repositories.Employees, bidInput.AuthorID)
+	if !ok {
 		return
 	}
 
-	response := schemas.BidCreateResponse{
-		ID:         bid.ID,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	bid, err := services.Bids.Create(c.Request.Context(), employee, bidInput)
+	if err != nil {
+		respondError(c, err, "Failed to create bid")
+		return
 	}
 
+	c.Header("ETag", versionETag(bid.Version))
+
+	response := schemas.NewBidResponse(bid)
+
 	c.JSON(http.StatusOK, response)
 }
 
 func GetMyBids(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
 
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := database.Where("username = ?", username).First(&employee).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid username"})
+	page, ok := pagination.FromQuery(c)
+	if !ok {
 		return
 	}
 
-	limit := 5
-	offset := 0
-
-	if l, err := strconv.Atoi(c.DefaultQuery("limit", "5")); err == nil {
-		limit = l
-	}
-	if o, err := strconv.Atoi(c.DefaultQuery("offset", "0")); err == nil {
-		offset = o
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
+		return
 	}
 
-	var bids []models.Bid
-	if err := database.Where("author_id = ?", employee.ID).Limit(limit).Offset(offset).Find(&bids).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bids"})
+	result, err := repositories.Bids.List(c.Request.Context(), repository.BidFilter{
+		Visible:  viewer.Visibility(),
+		AuthorID: &employee.ID,
+	}, repository.BidOrderCreated, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve bids")
 		return
 	}
 
 	var responseBids []schemas.BidCreateResponse
-	for _, bid := range bids {
-		responseBids = append(responseBids, schemas.BidCreateResponse{
-			ID:         bid.ID,
-			Name:       bid.Name,
-			Status:     bid.Status,
-			AuthorID:   bid.AuthorID,
-			AuthorType: bid.AuthorType,
-			Version:    bid.Version,
-			CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-		})
+	for _, bid := range pagination.Respond(c, result) {
+		responseBids = append(responseBids, schemas.NewBidResponse(bid))
 	}
 
 	c.JSON(http.StatusOK, responseBids)
 }
 
 func GetBidsByTender(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
@@ -152,63 +171,69 @@ func GetBidsByTender(c *gin.Context) {
 		return
 	}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", tenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+	tender, ok := findTender(c, repositories.Tenders, tenderID)
+	if !ok {
 		return
 	}
 
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
 		return
 	}
 
-	if tender.CreatorUsername != username {
+	if !viewer.CanViewTender(tender) {
 		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to access bids for this tender"})
 		return
 	}
 
-	limit := 5
-	offset := 0
+	// Пока запечатанный тендер не вскрыт, организация видит только число предложений.
+	if tender.IsSealed(time.Now()) && viewer.IsResponsible(tender.OrganizationID) {
+		count, err := repositories.Bids.Count(c.Request.Context(), repository.BidFilter{TenderID: &tender.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bids"})
+			return
+		}
+		c.JSON(http.StatusOK, schemas.SealedBidsResponse{Sealed: true, Count: count})
+		return
+	}
 
-	if l, err := strconv.Atoi(c.DefaultQuery("limit", "5")); err == nil {
-		limit = l
+	page, ok := pagination.FromQuery(c)
+	if !ok {
+		return
 	}
-	if o, err := strconv.Atoi(c.DefaultQuery("offset", "0")); err == nil {
-		offset = o
+
+	order := repository.BidOrder(c.Query("sort"))
+	switch order {
+	case repository.BidOrderCreated, repository.BidOrderPriceAsc, repository.BidOrderPriceDesc:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid sort value"})
+		return
 	}
 
-	var bids []models.Bid
-	if err := database.Where("tender_id = ?", tenderID).Limit(limit).Offset(offset).Find(&bids).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bids"})
+	result, err := repositories.Bids.List(c.Request.Context(), repository.BidFilter{
+		Visible:  viewer.Visibility(),
+		TenderID: &tender.ID,
+	}, order, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve bids")
 		return
 	}
 
 	var responseBids []schemas.BidCreateResponse
-	for _, bid := range bids {
-		responseBids = append(responseBids, schemas.BidCreateResponse{
-			ID:         bid.ID,
-			Name:       bid.Name,
-			Status:     bid.Status,
-			AuthorType: bid.AuthorType,
-			AuthorID:   bid.AuthorID,
-			Version:    bid.Version,
-			CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-		})
+	for _, bid := range pagination.Respond(c, result) {
+		responseBids = append(responseBids, schemas.NewBidResponse(bid))
 	}
 
 	c.JSON(http.StatusOK, responseBids)
 }
 
 func GetBidStatus(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
@@ -220,462 +245,354 @@ func GetBidStatus(c *gin.Context) {
 		return
 	}
 
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
-		return
-	}
-
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	var bid models.Bid
-	if err := database.First(&bid, "id = ?", bidID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
+	bid, ok := findBid(c, repositories.Bids, bidID)
+	if !ok {
 		return
 	}
 
-	if bid.AuthorID != employee.ID {
-		var orgResponsible models.OrganizationResponsible
-		if err := database.Where("organization_id = ? AND employee_id = ?", bid.TenderID, employee.ID).First(&orgResponsible).Error; err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to access this bid"})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, bid.Status)
-}
-
-func UpdateBidStatus(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	tender, ok := findTender(c, repositories.Tenders, bid.TenderID.String())
 	if !ok {
 		return
 	}
 
-	bidID := c.Param("bidId")
-
-	if bidID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Bid ID is required"})
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
 		return
 	}
 
-	status := c.Query("status")
-	username := c.Query("username")
-
-	if status == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Status is required"})
+	if !viewer.CanViewBid(bid, tender) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to access this bid"})
 		return
 	}
 
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
+	c.Header("ETag", versionETag(bid.Version))
+	if notModified(c, bid.Version) {
 		return
 	}
 
-	validStatuses := map[string]bool{
-		"Created":   true,
-		"Published": true,
-		"Canceled":  true,
-	}
+	c.JSON(http.StatusOK, bid.Status)
+}
 
-	if !validStatuses[status] {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid status value"})
+func UpdateBidStatus(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	bidID, ok := parseID(c, c.Param("bidId"), "Bid not found")
+	if !ok {
 		return
 	}
 
-	var bid models.Bid
-	if err := database.First(&bid, "id = ?", bidID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	if bid.AuthorID != employee.ID {
-		var orgResponsible models.OrganizationResponsible
-		if err := database.Where("organization_id = ? AND employee_id = ?", bid.TenderID, employee.ID).First(&orgResponsible).Error; err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to update this bid"})
-			return
-		}
-	}
-
-	bid.Status = status
-
-	if err := database.Save(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update bid status"})
+	bid, tender, err := services.Bids.ChangeStatus(c.Request.Context(), employee, bidID, c.Query("status"), ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to update bid status")
 		return
 	}
+	c.Header("ETag", versionETag(bid.Version))
 
-	response := schemas.BidCreateResponse{
-		ID:         bid.ID,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-	}
+	response := newVisibleBidResponse(bid, tender, employee.ID)
 
 	c.JSON(http.StatusOK, response)
 }
 
 func EditBid(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	services, ok := utils.GetServices(c)
 	if !ok {
 		return
 	}
 
-	bidID := c.Param("bidId")
 	var bidInput schemas.BidEditRequest
 	if err := c.ShouldBindJSON(&bidInput); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid input"})
 		return
 	}
 
-	var bid models.Bid
-	if err := database.First(&bid, "id = ?", bidID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
-		return
-	}
-
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
-		return
-	}
-
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	bidID, ok := parseID(c, c.Param("bidId"), "Bid not found")
+	if !ok {
 		return
 	}
 
-	if bid.AuthorID != employee.ID {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to edit this bid"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	history := models.BidHistory{
-		ID:          uuid.New(),
-		BidID:       bid.ID,
-		Name:        bid.Name,
-		Description: bid.Description,
-		Status:      bid.Status,
-		TenderID:    bid.TenderID,
-		AuthorType:  bid.AuthorType,
-		AuthorID:    bid.AuthorID,
-		Version:     bid.Version,
-		CreatedAt:   bid.CreatedAt,
-		Decision:    bid.Decision,
-	}
-	database.Create(&history)
-
-	bid.Name = bidInput.Name
-	bid.Description = bidInput.Description
-	bid.Version += 1
-	if err := database.Save(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update bid"})
+	bid, err := services.Bids.Edit(c.Request.Context(), employee, bidID, bidInput, ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to update bid")
 		return
 	}
+	c.Header("ETag", versionETag(bid.Version))
 
-	response := schemas.BidCreateResponse{
-		ID:         bid.ID,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-	}
+	response := schemas.NewBidResponse(bid)
 	c.JSON(http.StatusOK, response)
 }
 
 func RollbackBid(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	services, ok := utils.GetServices(c)
 	if !ok {
 		return
 	}
 
-	bidID := c.Param("bidId")
-	versionStr := c.Param("version")
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
+	version, ok := versionParam(c, c.Param("version"), "version")
+	if !ok {
 		return
 	}
 
-	var bid models.Bid
-	if err := database.First(&bid, "id = ?", bidID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
+	bidID, ok := parseID(c, c.Param("bidId"), "Bid not found")
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	if bid.AuthorID != employee.ID {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to rollback this bid"})
+	bid, err := services.Bids.Rollback(c.Request.Context(), employee, bidID, version, ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to rollback bid")
 		return
 	}
+	c.Header("ETag", versionETag(bid.Version))
 
-	var history models.BidHistory
-	if err := database.First(&history, "bid_id = ? AND version = ?", bidID, versionStr).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Version not found in history"})
+	response := schemas.NewBidResponse(bid)
+	c.JSON(http.StatusOK, response)
+}
+
+func SubmitDecision(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	currentHistory := models.BidHistory{
-		ID:          uuid.New(),
-		BidID:       bid.ID,
-		Name:        bid.Name,
-		Description: bid.Description,
-		Status:      bid.Status,
-		TenderID:    bid.TenderID,
-		AuthorType:  bid.AuthorType,
-		AuthorID:    bid.AuthorID,
-		Version:     bid.Version,
-		CreatedAt:   bid.CreatedAt,
-		Decision:    bid.Decision,
-	}
-	if err := database.Create(&currentHistory).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to save bid history"})
+	bidID, ok := parseID(c, c.Param("bidId"), "Bid not found")
+	if !ok {
 		return
 	}
 
-	bid.Name = history.Name
-	bid.Description = history.Description
-	bid.Status = history.Status
-	bid.TenderID = history.TenderID
-	bid.AuthorType = history.AuthorType
-	bid.AuthorID = history.AuthorID
-	bid.Version += 1
-	bid.CreatedAt = time.Now()
-	bid.Decision = history.Decision
-
-	if err := database.Save(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to rollback bid"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	response := schemas.BidCreateResponse{
-		ID:         bid.ID,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	bid, _, err := services.Bids.SubmitDecision(c.Request.Context(), employee, bidID, c.Query("decision"))
+	if err != nil {
+		respondError(c, err, "Failed to submit decision")
+		return
 	}
+
+	c.Header("ETag", versionETag(bid.Version))
+
+	response := schemas.NewBidResponse(bid)
 	c.JSON(http.StatusOK, response)
 }
 
-func SubmitDecision(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+func GetBidDecisions(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
 
-	bidID := c.Param("bidId")
-	decision := c.Query("decision")
-	username := c.Query("username")
-
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	if decision != "Approved" && decision != "Rejected" {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid decision value"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	var bid models.Bid
-	if err := database.First(&bid, "id = ?", bidID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
+	bid, ok := findBid(c, repositories.Bids, c.GetString("bidId"))
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	tender, ok := findTender(c, repositories.Tenders, bid.TenderID.String())
+	if !ok {
 		return
 	}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", bid.TenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
 		return
 	}
 
-	var organizationResponsible models.OrganizationResponsible
-	if err := database.First(&organizationResponsible, "organization_id = ? AND user_id = ?", tender.OrganizationID, employee.ID).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to submit decision for this bid"})
+	if !viewer.CanViewBid(bid, tender) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to view decisions for this bid"})
 		return
 	}
 
-	bid.Decision = &decision
-	if err := database.Save(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to submit decision"})
+	aggregate, quorum, err := services.Bids.AggregateDecision(c.Request.Context(), bid, tender)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve decisions"})
 		return
 	}
 
-	response := schemas.BidCreateResponse{
-		ID:         bid.ID,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-	}
-	c.JSON(http.StatusOK, response)
-}
-
-func SendFeedback(c *gin.Context) {
-	database, ok := utils.GetDB(c)
-	if !ok {
+	votes, err := decisionVotes(c, repositories, bid.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve decisions"})
 		return
 	}
 
-	bidID := c.Param("bidId")
-	feedback := c.Query("bidFeedback")
-	username := c.Query("username")
+	c.JSON(http.StatusOK, schemas.BidDecisionsResponse{
+		BidID:    bid.ID,
+		Decision: aggregate,
+		Quorum:   quorum,
+		Votes:    votes,
+	})
+}
 
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
-		return
+// decisionVotes возвращает голоса по предложению с именами проголосовавших
+// в порядке их изменения.
+func decisionVotes(c *gin.Context, repositories repository.Repositories, bidID uuid.UUID) ([]schemas.BidDecisionVote, error) {
+	ctx := c.Request.Context()
+	decisions, err := repositories.Bids.Votes(ctx, bidID)
+	if err != nil {
+		return nil, err
 	}
 
-	if feedback == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Feedback is required"})
-		return
+	ids := make([]uuid.UUID, 0, len(decisions))
+	for _, decision := range decisions {
+		ids = append(ids, decision.ResponsibleID)
 	}
-
-	if len(feedback) > 1000 {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Feedback exceeds maximum length of 1000 characters"})
-		return
+	employees, err := repositories.Employees.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[uuid.UUID]string, len(employees))
+	for _, employee := range employees {
+		usernames[employee.ID] = employee.Username
 	}
 
-	var bid models.Bid
-	if err := database.First(&bid, "id = ?", bidID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Bid not found"})
-		return
+	votes := make([]schemas.BidDecisionVote, 0, len(decisions))
+	for _, decision := range decisions {
+		username, ok := usernames[decision.ResponsibleID]
+		if !ok {
+			continue
+		}
+		votes = append(votes, schemas.BidDecisionVote{
+			ResponsibleID: decision.ResponsibleID,
+			Username:      username,
+			Decision:      decision.Decision,
+			UpdatedAt:     decision.UpdatedAt,
+		})
 	}
+	return votes, nil
+}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", bid.TenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+func SendFeedback(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	bidID, ok := parseID(c, c.Param("bidId"), "Bid not found")
+	if !ok {
 		return
 	}
 
-	var orgResp models.OrganizationResponsible
-	if err := database.First(&orgResp, "organization_id = ? AND user_id = ?", tender.OrganizationID, employee.ID).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to submit feedback for this bid"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	feedbackRecord := models.BidFeedback{
-		BidID:     bid.ID,
-		Feedback:  feedback,
-		AuthorID:  employee.ID,
-		CreatedAt: time.Now(),
-	}
-	if err := database.Create(&feedbackRecord).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to submit feedback"})
+	bid, err := services.Bids.SendFeedback(c.Request.Context(), employee, bidID, c.Query("bidFeedback"))
+	if err != nil {
+		respondError(c, err, "Failed to submit feedback")
 		return
 	}
 
-	response := schemas.BidCreateResponse{
-		ID:         bid.ID,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-	}
+	c.Header("ETag", versionETag(bid.Version))
+
+	response := schemas.NewBidResponse(bid)
 	c.JSON(http.StatusOK, response)
 }
 
 func GetBidReviews(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
 
 	tenderID := c.Param("tenderId")
 	authorUsername := c.Query("authorUsername")
-	requesterUsername := c.Query("requesterUsername")
-	limitStr := c.DefaultQuery("limit", "5")
-	offsetStr := c.DefaultQuery("offset", "0")
 
 	if authorUsername == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"reason": "authorUsername is required"})
 		return
 	}
-	if requesterUsername == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "requesterUsername is required"})
-		return
-	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid limit value"})
+	page, ok := pagination.FromQuery(c)
+	if !ok {
 		return
 	}
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid offset value"})
+
+	requesterEmployee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	var requesterEmployee models.Employee
-	if err := database.First(&requesterEmployee, "username = ?", requesterUsername).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent requester user"})
+	tender, ok := findTender(c, repositories.Tenders, tenderID)
+	if !ok {
 		return
 	}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", tenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+	viewer, ok := loadViewer(c, requesterEmployee)
+	if !ok {
 		return
 	}
 
-	var organizationResponsible models.OrganizationResponsible
-	if err := database.First(&organizationResponsible, "organization_id = ? AND user_id = ?", tender.OrganizationID, requesterEmployee.ID).Error; err != nil {
+	if !viewer.IsResponsible(tender.OrganizationID) {
 		c.JSON(http.StatusForbidden, gin.H{"reason": "Requester does not have permission to view reviews for this tender"})
 		return
 	}
 
-	var authorEmployee models.Employee
-	if err := database.First(&authorEmployee, "username = ?", authorUsername).Error; err != nil {
+	ctx := c.Request.Context()
+	authorEmployee, err := repositories.Employees.FindByUsername(ctx, authorUsername)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"reason": "Invalid or non-existent author user"})
 		return
 	}
 
-	var reviews []schemas.BidReviewResponse
-	if err := database.Table("bid_feedback").Select("id, feedback as description, created_at").
-		Where("author_id = ?", authorEmployee.ID).
-		Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+	// Отзывы доступны, только если автор подал предложение на этот тендер.
+	authorBids, err := repositories.Bids.Count(ctx, repository.BidFilter{TenderID: &tender.ID, AuthorID: &authorEmployee.ID})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Error retrieving reviews"})
 		return
 	}
+	if authorBids == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Author has no bids for this tender"})
+		return
+	}
+
+	result, err := repositories.Bids.ListFeedback(ctx, authorEmployee.ID, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Error retrieving reviews")
+		return
+	}
+
+	feedback := pagination.Respond(c, result)
+	reviews := make([]schemas.BidReviewResponse, 0, len(feedback))
+	for _, review := range feedback {
+		reviews = append(reviews, schemas.BidReviewResponse{
+			ID:          review.ID,
+			Description: review.Feedback,
+			CreatedAt:   review.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		})
+	}
 
 	if len(reviews) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"reason": "No reviews found"})
@@ -1,29 +1,317 @@
 package handlers
 
 import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/auth"
 	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
 	"ZADANIE-6105/utils"
+	"errors"
 	"net/http"
+	"net/mail"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+func newEmployeeResponse(employee models.Employee) schemas.EmployeeResponse {
+	return schemas.EmployeeResponse{
+		ID:        employee.ID,
+		Username:  employee.Username,
+		FirstName: employee.FirstName,
+		LastName:  employee.LastName,
+		Email:     employee.Email,
+		Language:  employee.Language,
+		IsActive:  employee.IsActive(),
+		CreatedAt: employee.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		UpdatedAt: employee.UpdatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
 func CreateEmployee(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	var request schemas.EmployeeCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	employee := models.Employee{
+		Username:  request.Username,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Language:  request.Language,
+	}
+	if request.Email != "" {
+		employee.Email = &request.Email
+	}
+	if employee.Language == "" {
+		employee.Language = "ru"
+	}
+
+	if request.Password != "" {
+		hash, err := auth.HashPassword(request.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to hash password"})
+			return
+		}
+		employee.PasswordHash = hash
+	}
+
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Employees.Create(ctx, &employee); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionCreate,
+			EntityType: audit.EntityEmployee,
+			EntityID:   employee.ID,
+			Actor:      auth.OptionalEmployee(c),
+		})
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"reason": "Username is already taken"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to create employee"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Employee created successfully", "employee": newEmployeeResponse(employee)})
+}
+
+func GetEmployees(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	if err := c.ShouldBindJSON(&employee); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if _, ok := auth.CurrentEmployee(c); !ok {
 		return
 	}
 
-	if err := database.Create(&employee).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, ok := pagination.FromQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := repositories.Employees.List(c.Request.Context(), repository.EmployeeFilter{
+		Search:     c.Query("q"),
+		ActiveOnly: c.Query("active") == "true",
+	}, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve employees")
 		return
 	}
+	employees := pagination.Respond(c, result)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Employee created successfully", "employee": employee})
+	responses := make([]schemas.EmployeeResponse, 0, len(employees))
+	for _, employee := range employees {
+		responses = append(responses, newEmployeeResponse(employee))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func GetEmployee(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := auth.CurrentEmployee(c); !ok {
+		return
+	}
+
+	employee, ok := findEmployee(c, repositories.Employees)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newEmployeeResponse(employee))
+}
+
+func GetEmployeeByUsername(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := auth.CurrentEmployee(c); !ok {
+		return
+	}
+
+	employee, err := repositories.Employees.FindByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		respondEmployeeLookupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newEmployeeResponse(employee))
+}
+
+func UpdateEmployee(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	caller, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := findEmployee(c, repositories.Employees)
+	if !ok {
+		return
+	}
+
+	if caller.ID != employee.ID && !caller.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to update this employee"})
+		return
+	}
+
+	var request schemas.EmployeeUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	if request.FirstName != nil {
+		employee.FirstName = *request.FirstName
+	}
+	if request.LastName != nil {
+		employee.LastName = *request.LastName
+	}
+	if request.Email != nil {
+		employee.Email = nil
+		if *request.Email != "" {
+			if _, err := mail.ParseAddress(*request.Email); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid email address"})
+				return
+			}
+			employee.Email = request.Email
+		}
+	}
+	if request.Language != nil {
+		employee.Language = *request.Language
+	}
+	if request.Password != nil {
+		hash, err := auth.HashPassword(*request.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to hash password"})
+			return
+		}
+		employee.PasswordHash = hash
+	}
+	employee.UpdatedAt = time.Now()
+
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Employees.Save(ctx, &employee); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionEdit,
+			EntityType: audit.EntityEmployee,
+			EntityID:   employee.ID,
+			Actor:      caller,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update employee"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newEmployeeResponse(employee))
+}
+
+// DeactivateEmployee запрещает сотруднику любые действия с тендерами и
+// предложениями. Записи сотрудника не удаляются, чтобы сохранить историю.
+func DeactivateEmployee(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	caller, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := findEmployee(c, repositories.Employees)
+	if !ok {
+		return
+	}
+
+	if caller.ID != employee.ID && !caller.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to deactivate this employee"})
+		return
+	}
+
+	if !employee.IsActive() {
+		c.JSON(http.StatusOK, newEmployeeResponse(employee))
+		return
+	}
+
+	now := time.Now()
+	employee.DeactivatedAt = &now
+	employee.UpdatedAt = now
+
+	// Деактивированный сотрудник перестаёт быть владельцем тендеров, чтобы
+	// списки владельцев не ссылались на тех, кто не может действовать.
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Employees.Save(ctx, &employee); err != nil {
+			return err
+		}
+		if err := tx.Tenders.RemoveOwner(ctx, employee.ID, nil); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionDeactivate,
+			EntityType: audit.EntityEmployee,
+			EntityID:   employee.ID,
+			Actor:      caller,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to deactivate employee"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newEmployeeResponse(employee))
+}
+
+func findEmployee(c *gin.Context, employees repository.EmployeeRepository) (models.Employee, bool) {
+	employeeID, err := uuid.Parse(c.Param("employeeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid employeeId format"})
+		return models.Employee{}, false
+	}
+
+	employee, err := employees.FindByID(c.Request.Context(), employeeID)
+	if err != nil {
+		respondEmployeeLookupError(c, err)
+		return employee, false
+	}
+
+	return employee, true
+}
+
+func respondEmployeeLookupError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Employee not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve employee"})
 }
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"ZADANIE-6105/service"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag тендера и предложения — номер версии: любое изменение, сохраняемое
+// в истории, увеличивает версию и тем самым меняет ETag.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch возвращает проверку версии по заголовку If-Match запроса.
+func ifMatch(c *gin.Context) service.VersionCheck {
+	header := c.GetHeader("If-Match")
+	return func(version int) bool {
+		return ifMatchVersion(header, version)
+	}
+}
+
+// ifMatchVersion проверяет заголовок If-Match против текущей версии.
+// Без заголовка изменение разрешено. По RFC 9110 для If-Match
+// используется строгое сравнение, поэтому слабые ETag не совпадают.
+func ifMatchVersion(header string, version int) bool {
+	if header == "" {
+		return true
+	}
+	etag := versionETag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified отвечает 304, если If-None-Match совпал с версией.
+// Для If-None-Match допускается слабое сравнение.
+func notModified(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	etag := versionETag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
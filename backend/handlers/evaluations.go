@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/utils"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func GetTenderCriteria(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
+		return
+	}
+
+	viewer, ok := loadViewer(c, auth.OptionalEmployee(c))
+	if !ok {
+		return
+	}
+
+	if !viewer.CanViewTender(tender) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to view this tender"})
+		return
+	}
+
+	criteria, err := repositories.Evaluations.Criteria(c.Request.Context(), tender.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve criteria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCriteriaResponse(criteria))
+}
+
+// SetTenderCriteria заменяет критерии оценки тендера. После появления
+// первых оценок критерии менять нельзя, чтобы не потерять оценки.
+func SetTenderCriteria(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tenderID, ok := parseID(c, c.Param("tenderId"), "Tender not found")
+	if !ok {
+		return
+	}
+
+	var request schemas.TenderCriteriaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	criteria, err := services.Evaluations.SetCriteria(c.Request.Context(), employee, tenderID, request.Criteria)
+	if err != nil {
+		respondError(c, err, "Failed to update criteria")
+		return
+	}
+
+	c.JSON(http.StatusOK, newCriteriaResponse(criteria))
+}
+
+// ScoreBid сохраняет оценки вызывающего ответственного по критериям тендера.
+// Повторная оценка по тому же критерию заменяет предыдущую.
+func ScoreBid(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	bidID, ok := parseID(c, c.Param("bidId"), "Bid not found")
+	if !ok {
+		return
+	}
+
+	var request schemas.BidScoresRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	if err := services.Evaluations.Score(c.Request.Context(), employee, bidID, request.Scores); err != nil {
+		respondError(c, err, "Failed to save scores")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scores saved successfully"})
+}
+
+// GetTenderRanking возвращает предложения тендера, отсортированные по
+// взвешенной сумме оценок: sum(weight * avg) / sum(weight), где avg —
+// средняя оценка критерия по всем оценщикам. Критерий без оценок даёт 0.
+func GetTenderRanking(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
+		return
+	}
+
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
+		return
+	}
+
+	if !viewer.IsResponsible(tender.OrganizationID) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to view the ranking of this tender"})
+		return
+	}
+
+	if tender.IsSealed(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Bids are sealed until the submission deadline"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	criteria, err := repositories.Evaluations.Criteria(ctx, tender.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve criteria"})
+		return
+	}
+
+	bids, err := repositories.Bids.ListByTender(ctx, tender.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bids"})
+		return
+	}
+
+	byKey, err := evaluatorScores(c, repositories, tender.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve scores"})
+		return
+	}
+
+	totalWeight := 0
+	for _, criterion := range criteria {
+		totalWeight += criterion.Weight
+	}
+
+	ranking := make([]schemas.BidRankingEntry, 0, len(bids))
+	for _, bid := range bids {
+		entry := schemas.BidRankingEntry{
+			Bid:      schemas.NewBidResponse(bid),
+			Criteria: make([]schemas.CriterionScore, 0, len(criteria)),
+		}
+
+		weighted := 0.0
+		for _, criterion := range criteria {
+			score := schemas.CriterionScore{
+				CriterionID: criterion.ID,
+				Name:        criterion.Name,
+				Weight:      criterion.Weight,
+				Evaluations: byKey[scoreKey{bid.ID, criterion.ID}],
+			}
+			if len(score.Evaluations) > 0 {
+				sum := 0
+				for _, evaluation := range score.Evaluations {
+					sum += evaluation.Score
+				}
+				average := float64(sum) / float64(len(score.Evaluations))
+				score.Average = &average
+				weighted += average * float64(criterion.Weight)
+			}
+			entry.Criteria = append(entry.Criteria, score)
+		}
+
+		if totalWeight > 0 {
+			entry.Total = weighted / float64(totalWeight)
+		}
+		ranking = append(ranking, entry)
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].Total > ranking[j].Total
+	})
+	for i := range ranking {
+		ranking[i].Rank = i + 1
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+type scoreKey struct{ bid, criterion uuid.UUID }
+
+// evaluatorScores возвращает оценки предложений тендера по критериям,
+// упорядоченные по имени оценщика.
+func evaluatorScores(c *gin.Context, repositories repository.Repositories, tenderID uuid.UUID) (map[scoreKey][]schemas.EvaluatorScore, error) {
+	ctx := c.Request.Context()
+	scores, err := repositories.Evaluations.Scores(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(scores))
+	for _, score := range scores {
+		ids = append(ids, score.EvaluatorID)
+	}
+	evaluators, err := repositories.Employees.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[uuid.UUID]string, len(evaluators))
+	for _, evaluator := range evaluators {
+		usernames[evaluator.ID] = evaluator.Username
+	}
+
+	byKey := make(map[scoreKey][]schemas.EvaluatorScore)
+	for _, score := range scores {
+		username, ok := usernames[score.EvaluatorID]
+		if !ok {
+			continue
+		}
+		key := scoreKey{score.BidID, score.CriterionID}
+		byKey[key] = append(byKey[key], schemas.EvaluatorScore{
+			EvaluatorID: score.EvaluatorID,
+			Username:    username,
+			Score:       score.Score,
+			Comment:     score.Comment,
+		})
+	}
+	for _, evaluations := range byKey {
+		sort.SliceStable(evaluations, func(i, j int) bool {
+			return evaluations[i].Username < evaluations[j].Username
+		})
+	}
+	return byKey, nil
+}
+
+func newCriteriaResponse(criteria []models.TenderCriterion) []schemas.TenderCriterionResponse {
+	responses := make([]schemas.TenderCriterionResponse, 0, len(criteria))
+	for _, criterion := range criteria {
+		responses = append(responses, schemas.TenderCriterionResponse{
+			ID:     criterion.ID,
+			Name:   criterion.Name,
+			Weight: criterion.Weight,
+		})
+	}
+	return responses
+}
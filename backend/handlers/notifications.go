@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/notifications"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/utils"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newNotificationResponse(notification models.Notification) schemas.NotificationResponse {
+	response := schemas.NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Message:   notification.Message,
+		TenderID:  notification.TenderID,
+		BidID:     notification.BidID,
+		Read:      notification.ReadAt != nil,
+		CreatedAt: notification.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+	if notification.ReadAt != nil {
+		readAt := notification.ReadAt.Format("2006-01-02T15:04:05-07:00")
+		response.ReadAt = &readAt
+	}
+	return response
+}
+
+// GetNotifications возвращает уведомления вызывающего, новые первыми.
+// unread=true оставляет только непрочитанные, type — уведомления одного типа.
+func GetNotifications(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	page, ok := pagination.FromQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := repository.NotificationFilter{Type: c.Query("type")}
+	switch c.Query("unread") {
+	case "":
+	case "true":
+		read := false
+		filter.Read = &read
+	case "false":
+		read := true
+		filter.Read = &read
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid unread value"})
+		return
+	}
+
+	result, err := repositories.Notifications.List(c.Request.Context(), employee.ID, filter, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve notifications")
+		return
+	}
+	records := pagination.Respond(c, result)
+
+	response := make([]schemas.NotificationResponse, 0, len(records))
+	for _, notification := range records {
+		response = append(response, newNotificationResponse(notification))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным. Повторная
+// отметка не меняет время прочтения.
+func MarkNotificationRead(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("notificationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid notificationId format"})
+		return
+	}
+
+	notification, err := repositories.Notifications.MarkRead(c.Request.Context(), employee.ID, notificationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newNotificationResponse(notification))
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления
+// вызывающего.
+func MarkAllNotificationsRead(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	updated, err := repositories.Notifications.MarkAllRead(c.Request.Context(), employee.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// GetNotificationPreferences возвращает для каждого типа уведомлений,
+// включён ли он у вызывающего.
+func GetNotificationPreferences(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	respondNotificationPreferences(c, repositories.Notifications, employee.ID)
+}
+
+// SetNotificationPreferences включает и отключает типы уведомлений.
+// Типы, не указанные в запросе, не меняются.
+func SetNotificationPreferences(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	var request schemas.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	records := make([]models.NotificationPreference, 0, len(request.Preferences))
+	for _, preference := range request.Preferences {
+		if !slices.Contains(notifications.Types, preference.Type) {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Unknown notification type " + preference.Type})
+			return
+		}
+		records = append(records, models.NotificationPreference{
+			EmployeeID: employee.ID,
+			Type:       preference.Type,
+			Enabled:    *preference.Enabled,
+			UpdatedAt:  time.Now(),
+		})
+	}
+
+	if err := repositories.Notifications.SavePreferences(c.Request.Context(), records); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update notification preferences"})
+		return
+	}
+
+	respondNotificationPreferences(c, repositories.Notifications, employee.ID)
+}
+
+func respondNotificationPreferences(c *gin.Context, preferences repository.NotificationRepository, employeeID uuid.UUID) {
+	stored, err := preferences.Preferences(c.Request.Context(), employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve notification preferences"})
+		return
+	}
+
+	enabled := make(map[string]bool, len(stored))
+	for _, preference := range stored {
+		enabled[preference.Type] = preference.Enabled
+	}
+
+	response := make([]schemas.NotificationPreference, 0, len(notifications.Types))
+	for _, notificationType := range notifications.Types {
+		value, exists := enabled[notificationType]
+		if !exists {
+			value = true
+		}
+		response = append(response, schemas.NotificationPreference{Type: notificationType, Enabled: &value})
+	}
+	c.JSON(http.StatusOK, response)
+}
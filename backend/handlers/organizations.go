@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/policy"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newOrganizationResponse(organization models.Organization) schemas.OrganizationResponse {
+	return schemas.OrganizationResponse{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Description: organization.Description,
+		Type:        organization.Type,
+		CreatedAt:   organization.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		UpdatedAt:   organization.UpdatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
+func CreateOrganization(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	var request schemas.OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	organization := models.Organization{
+		Name:        request.Name,
+		Description: request.Description,
+		Type:        request.Type,
+	}
+
+	// Создатель становится первым ответственным, иначе организацией
+	// некому было бы управлять.
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Organizations.Create(ctx, &organization); err != nil {
+			return err
+		}
+		if err := tx.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{
+			OrganizationID: organization.ID,
+			UserID:         employee.ID,
+		}); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionCreate,
+			EntityType: audit.EntityOrganization,
+			EntityID:   organization.ID,
+			Actor:      employee,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationResponse(organization))
+}
+
+func GetOrganizations(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	page, ok := pagination.FromQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := repositories.Organizations.List(c.Request.Context(), page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve organizations")
+		return
+	}
+	organizations := pagination.Respond(c, result)
+
+	responses := make([]schemas.OrganizationResponse, 0, len(organizations))
+	for _, organization := range organizations {
+		responses = append(responses, newOrganizationResponse(organization))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func GetOrganization(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := findOrganization(c, repositories.Organizations)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationResponse(organization))
+}
+
+func UpdateOrganization(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := findOrganization(c, repositories.Organizations)
+	if !ok {
+		return
+	}
+
+	if !policy.CanManageOrganization(c.Request.Context(), repositories.Organizations, organization.ID, employee) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to manage this organization"})
+		return
+	}
+
+	var request schemas.OrganizationUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	if request.Name != nil {
+		organization.Name = *request.Name
+	}
+	if request.Description != nil {
+		organization.Description = *request.Description
+	}
+	if request.Type != nil {
+		organization.Type = *request.Type
+	}
+	organization.UpdatedAt = time.Now()
+
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Organizations.Save(ctx, &organization); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionEdit,
+			EntityType: audit.EntityOrganization,
+			EntityID:   organization.ID,
+			Actor:      employee,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationResponse(organization))
+}
+
+func AddOrganizationResponsible(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := findOrganization(c, repositories.Organizations)
+	if !ok {
+		return
+	}
+
+	if !policy.CanManageOrganization(c.Request.Context(), repositories.Organizations, organization.ID, employee) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to manage this organization"})
+		return
+	}
+
+	var request schemas.ResponsibleAddRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	var candidate models.Employee
+	var err error
+	switch {
+	case request.UserID != "":
+		var userID uuid.UUID
+		userID, err = uuid.Parse(request.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid userId format"})
+			return
+		}
+		candidate, err = repositories.Employees.FindByID(c.Request.Context(), userID)
+	case request.Username != "":
+		candidate, err = repositories.Employees.FindByUsername(c.Request.Context(), request.Username)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "userId or username is required"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Employee not found"})
+		return
+	}
+
+	if !candidate.IsActive() {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Employee is deactivated"})
+		return
+	}
+
+	responsible := models.OrganizationResponsible{
+		OrganizationID: organization.ID,
+		UserID:         candidate.ID,
+	}
+	ctx := c.Request.Context()
+	err = repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Organizations.AddResponsible(ctx, &responsible); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionAddResponsible,
+			EntityType: audit.EntityOrganization,
+			EntityID:   organization.ID,
+			Actor:      employee,
+		})
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Employee is already responsible for this organization"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to add responsible"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.ResponsibleResponse{
+		ID:             responsible.ID,
+		OrganizationID: responsible.OrganizationID,
+		UserID:         candidate.ID,
+		Username:       candidate.Username,
+	})
+}
+
+func RemoveOrganizationResponsible(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := findOrganization(c, repositories.Organizations)
+	if !ok {
+		return
+	}
+
+	if !policy.CanManageOrganization(c.Request.Context(), repositories.Organizations, organization.ID, employee) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to manage this organization"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid userId format"})
+		return
+	}
+
+	// Подсчёт и удаление идут под блокировкой организации, иначе два
+	// параллельных удаления оба увидели бы второго ответственного.
+	// Вместе с ответственностью сотрудник теряет и владение тендерами
+	// организации.
+	errLastResponsible := errors.New("last responsible")
+	ctx := c.Request.Context()
+	err = repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if _, err := tx.Organizations.FindForUpdate(ctx, organization.ID); err != nil {
+			return err
+		}
+		responsibles, err := tx.Organizations.CountResponsibles(ctx, organization.ID)
+		if err != nil {
+			return err
+		}
+		if responsibles <= 1 && !employee.IsAdmin {
+			return errLastResponsible
+		}
+
+		if err := tx.Organizations.RemoveResponsible(ctx, organization.ID, userID); err != nil {
+			return err
+		}
+		if err := tx.Tenders.RemoveOwner(ctx, userID, &organization.ID); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionRemoveResponsible,
+			EntityType: audit.EntityOrganization,
+			EntityID:   organization.ID,
+			Actor:      employee,
+		})
+	})
+	if errors.Is(err, errLastResponsible) {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Cannot remove the last responsible of the organization"})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Employee is not responsible for this organization"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to remove responsible"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Responsible removed successfully"})
+}
+
+func findOrganization(c *gin.Context, organizations repository.OrganizationRepository) (models.Organization, bool) {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid organizationId format"})
+		return models.Organization{}, false
+	}
+
+	organization, err := organizations.FindByID(c.Request.Context(), organizationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"reason": "Organization not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve organization"})
+		}
+		return organization, false
+	}
+
+	return organization, true
+}
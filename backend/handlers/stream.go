@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/policy"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// streamHeartbeat — период комментария-пинга. По нему же поток
+	// перечитывает события на случай потерянного уведомления.
+	streamHeartbeat = 15 * time.Second
+	streamBatchSize = 100
+)
+
+// GetTenderEvents отдаёт ленту активности тендера как Server-Sent Events:
+// создание, изменение и смену статуса предложений и смену статуса
+// тендера. Каждое событие фильтруется по правам вызывающего. Клиент
+// возобновляет поток заголовком Last-Event-ID (или параметром lastEventId);
+// без него поток начинается с новых событий.
+func GetTenderEvents(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	hub, ok := utils.GetHub(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
+		return
+	}
+
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
+		return
+	}
+
+	if !viewer.CanViewTender(tender) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to view this tender"})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	var lastSeq int64
+	if lastEventID != "" {
+		seq, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || seq < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid Last-Event-ID value"})
+			return
+		}
+		lastSeq = seq
+	}
+
+	// Подписка до чтения начальной позиции, чтобы не пропустить событие,
+	// зафиксированное между ними.
+	wakeup, unsubscribe := hub.Subscribe(tender.ID)
+	defer unsubscribe()
+
+	// Номер из Last-Event-ID больше последнего мог остаться от сквозной
+	// нумерации событий: такой поток продолжается с новых событий.
+	seq, err := repositories.Events.LastSeq(c.Request.Context(), tender.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to open event stream"})
+		return
+	}
+	if lastEventID == "" || lastSeq > seq {
+		lastSeq = seq
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		seq, err := writeTenderEvents(c, repositories, viewer, tender.ID, lastSeq)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: {\"reason\":\"Failed to read tender events\"}\n\n")
+				c.Writer.Flush()
+			}
+			return
+		}
+		lastSeq = seq
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wakeup:
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeTenderEvents отправляет события тендера после lastSeq, видимые
+// viewer, и возвращает номер последнего прочитанного события. Номера
+// событий тендера идут подряд, поэтому чтение останавливается на
+// пропуске: недостающее событие ещё не зафиксировано, а следующие за ним
+// будут отправлены после него.
+func writeTenderEvents(c *gin.Context, repositories repository.Repositories, viewer policy.Viewer, tenderID uuid.UUID, lastSeq int64) (int64, error) {
+	ctx := c.Request.Context()
+	for {
+		events, err := repositories.Events.After(ctx, tenderID, lastSeq, streamBatchSize)
+		if err != nil {
+			return lastSeq, err
+		}
+		if len(events) == 0 {
+			return lastSeq, nil
+		}
+
+		// Видимость предложений зависит от текущего состояния тендера:
+		// пока он запечатан, чужие предложения скрыты.
+		tender, err := repositories.Tenders.FindByID(ctx, tenderID)
+		if err != nil {
+			return lastSeq, err
+		}
+
+		complete := true
+		for _, event := range events {
+			if event.Seq != lastSeq+1 {
+				complete = false
+				break
+			}
+			lastSeq = event.Seq
+			if !canViewTenderEvent(viewer, tender, event) {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, event.Payload)
+		}
+		c.Writer.Flush()
+
+		if !complete || len(events) < streamBatchSize {
+			return lastSeq, nil
+		}
+	}
+}
+
+func canViewTenderEvent(viewer policy.Viewer, tender models.Tender, event models.TenderEvent) bool {
+	if event.BidAuthorID == nil {
+		return event.Public || viewer.IsResponsible(tender.OrganizationID)
+	}
+
+	bid := models.Bid{AuthorID: *event.BidAuthorID, TenderID: tender.ID}
+	if !viewer.CanViewBid(bid, tender) {
+		return false
+	}
+	return !tender.IsSealed(time.Now()) || bid.AuthorID == viewer.Employee.ID
+}
@@ -1,78 +1,199 @@
 package handlers
 
 import (
+	"ZADANIE-6105/auth"
 	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/policy"
+	"ZADANIE-6105/repository"
 	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/service"
 	"ZADANIE-6105/utils"
+	"errors"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-func isResponsibleForOrganization(db *gorm.DB, organizationID uuid.UUID, userID uuid.UUID) bool {
-	var responsibility models.OrganizationResponsible
-	result := db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&responsibility)
-	return result.Error == nil && result.RowsAffected > 0
+func loadViewer(c *gin.Context, employee *models.Employee) (policy.Viewer, bool) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return policy.Viewer{}, false
+	}
+
+	viewer, err := policy.LoadViewer(c.Request.Context(), repositories.Organizations, employee)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to load user permissions"})
+		return policy.Viewer{}, false
+	}
+	return viewer, true
 }
 
-func CreateTender(c *gin.Context) {
-	var tender models.Tender
+// findTender загружает тендер по ID. Некорректный ID, как и отсутствующий
+// тендер, даёт 404.
+func findTender(c *gin.Context, tenders repository.TenderRepository, id string) (models.Tender, bool) {
+	tenderID, ok := parseID(c, id, "Tender not found")
+	if !ok {
+		return models.Tender{}, false
+	}
 
-	if err := c.ShouldBindJSON(&tender); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
-		return
+	tender, err := tenders.FindByID(c.Request.Context(), tenderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve tender"})
+		}
+		return tender, false
 	}
 
-	if tender.CreatorUsername == "" {
+	return tender, true
+}
+
+// callerByUsername возвращает аутентифицированного сотрудника. В режиме
+// совместимости запрос без токена может назвать сотрудника по username.
+func callerByUsername(c *gin.Context, employees repository.EmployeeRepository, username string) (*models.Employee, bool) {
+	if !auth.LegacyEnabled(c) {
+		return auth.CurrentEmployee(c)
+	}
+
+	if username == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"reason": "creatorUsername cannot be empty"})
-		return
+		return nil, false
 	}
 
-	db, ok := utils.GetDB(c)
-	if !ok {
+	employee, err := employees.FindByUsername(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+		return nil, false
+	}
+
+	if !employee.IsActive() {
+		c.JSON(http.StatusForbidden, gin.H{"reason": auth.ErrDeactivated.Error()})
+		return nil, false
+	}
+	return &employee, true
+}
+
+func newTenderResponse(tender models.Tender) schemas.TenderResponse {
+	return schemas.TenderResponse{
+		ID:                 tender.ID,
+		Name:               tender.Name,
+		Description:        tender.Description,
+		ServiceType:        tender.ServiceType,
+		Status:             tender.Status,
+		Version:            tender.Version,
+		MaxBudget:          tender.MaxBudget,
+		Currency:           tender.Currency,
+		SubmissionDeadline: formatOptionalTime(tender.SubmissionDeadline),
+		PublishAt:          formatOptionalTime(tender.PublishAt),
+		Sealed:             tender.Sealed,
+		CreatedAt:          tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02T15:04:05-07:00")
+	return &formatted
+}
+
+// respondError отвечает на ошибку сервиса её статусом и сообщением,
+// а на прочие ошибки — 500 с сообщением reason.
+func respondError(c *gin.Context, err error, reason string) {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": reason})
 		return
 	}
 
-	var employee models.Employee
-	result := db.Where("username = ?", tender.CreatorUsername).First(&employee)
-	if result.Error != nil || result.RowsAffected == 0 {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, service.ErrVersionMismatch):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"reason": serviceErr.Reason})
+}
+
+// parseID разбирает ID из пути. На некорректный ID, как и на
+// отсутствующую запись, отвечает 404 с сообщением reason.
+func parseID(c *gin.Context, value string, reason string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"reason": reason})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func CreateTender(c *gin.Context) {
+	var request schemas.TenderCreateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
 		return
 	}
 
-	if !isResponsibleForOrganization(db, tender.OrganizationID, employee.ID) {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not responsible for the organization"})
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
 		return
 	}
 
-	if err := tender.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	if err := db.Create(&tender).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to create tender"})
+	employee, ok := callerByUsername(c, repositories.Employees, request.CreatorUsername)
+	if !ok {
 		return
 	}
 
-	response := schemas.TenderResponse{
-		ID:          tender.ID,
-		Name:        tender.Name,
-		Description: tender.Description,
-		ServiceType: tender.ServiceType,
-		Status:      tender.Status,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	tender, err := services.Tenders.Create(c.Request.Context(), employee, models.Tender{
+		Name:               request.Name,
+		Description:        request.Description,
+		ServiceType:        request.ServiceType,
+		Status:             models.TenderStatusCreated,
+		OrganizationID:     request.OrganizationID,
+		MaxBudget:          request.MaxBudget,
+		Currency:           request.Currency,
+		SubmissionDeadline: request.SubmissionDeadline,
+		PublishAt:          request.PublishAt,
+		Sealed:             request.Sealed,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to create tender")
+		return
 	}
+	c.Header("ETag", versionETag(tender.Version))
+
+	response := newTenderResponse(tender)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// GetTenders возвращает доступные тендеры с фильтрами по статусу,
+// организации, типу услуг и дате создания. Параметр q включает
+// полнотекстовый поиск по названию и описанию: результаты сортируются
+// по релевантности и содержат подсвеченные фрагменты.
 func GetTenders(c *gin.Context) {
-	db, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	viewer, ok := loadViewer(c, auth.OptionalEmployee(c))
 	if !ok {
 		return
 	}
@@ -83,9 +204,15 @@ func GetTenders(c *gin.Context) {
 		"Manufacture":  true,
 	}
 
+	validStatuses := map[string]bool{
+		models.TenderStatusCreated:   true,
+		models.TenderStatusPublished: true,
+		models.TenderStatusClosed:    true,
+	}
+
 	serviceTypes := c.QueryArray("service_type")
-	limitStr := c.DefaultQuery("limit", "5")
-	offsetStr := c.DefaultQuery("offset", "0")
+	statuses := c.QueryArray("status")
+	search := strings.TrimSpace(c.Query("q"))
 
 	for _, st := range serviceTypes {
 		if !validServiceTypes[st] {
@@ -94,158 +221,166 @@ func GetTenders(c *gin.Context) {
 		}
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 5
+	for _, status := range statuses {
+		if !validStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid status value"})
+			return
+		}
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	filter := repository.TenderFilter{
+		Visible:      viewer.Visibility(),
+		ServiceTypes: serviceTypes,
+		Statuses:     statuses,
 	}
 
-	var tenders []models.Tender
-
-	query := db.Model(&models.Tender{})
-
-	if len(serviceTypes) > 0 {
-		query = query.Where("service_type IN ?", serviceTypes)
+	if value := c.Query("organization_id"); value != "" {
+		organizationID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid organization_id value"})
+			return
+		}
+		filter.OrganizationID = &organizationID
 	}
 
-	query = query.Order("name ASC").Limit(limit).Offset(offset)
-
-	if err := query.Find(&tenders).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": err.Error()})
+	createdFrom, _, err := parseDateParam(c.Query("created_from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid created_from value"})
 		return
 	}
 
-	var responses []schemas.TenderResponse
-	for _, tender := range tenders {
-		responses = append(responses, schemas.TenderResponse{
-			ID:          tender.ID,
-			Name:        tender.Name,
-			Description: tender.Description,
-			ServiceType: tender.ServiceType,
-			Status:      tender.Status,
-			Version:     tender.Version,
-			CreatedAt:   tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-		})
+	createdTo, createdToDate, err := parseDateParam(c.Query("created_to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid created_to value"})
+		return
 	}
 
-	c.JSON(http.StatusOK, responses)
-}
-
-func GetUserTenders(c *gin.Context) {
-	db, ok := utils.GetDB(c)
+	page, ok := pagination.FromQuery(c)
 	if !ok {
 		return
 	}
 
-	username := c.Query("username")
+	filter.CreatedFrom = createdFrom
+	if createdTo != nil {
+		// Дата без времени включает весь указанный день.
+		if createdToDate {
+			before := createdTo.AddDate(0, 0, 1)
+			filter.CreatedBefore = &before
+		} else {
+			filter.CreatedTo = createdTo
+		}
+	}
 
-	if username == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "username parameter cannot be empty"})
+	if search == "" {
+		result, err := repositories.Tenders.List(c.Request.Context(), filter, page)
+		if err != nil {
+			pagination.RespondError(c, err, "Failed to retrieve tenders")
+			return
+		}
+
+		var responses []schemas.TenderResponse
+		for _, tender := range pagination.Respond(c, result) {
+			responses = append(responses, newTenderResponse(tender))
+		}
+
+		c.JSON(http.StatusOK, responses)
 		return
 	}
 
-	var employee models.Employee
-	result := db.Where("username = ?", username).First(&employee)
-	if result.Error != nil || result.RowsAffected == 0 {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	result, err := repositories.Tenders.Search(c.Request.Context(), search, filter, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to search tenders")
 		return
 	}
 
-	var tenders []models.Tender
-
-	query := db.Model(&models.Tender{})
-	query = query.Where("creator_username = ?", username)
+	matches := pagination.Respond(c, result)
+	responses := make([]schemas.TenderResponse, 0, len(matches))
+	for _, match := range matches {
+		response := newTenderResponse(match.Tender)
+		rank := match.SearchRank
+		response.Rank = &rank
+		response.Highlight = &schemas.TenderHighlight{
+			Name:        match.NameHighlight,
+			Description: match.DescriptionHighlight,
+		}
+		responses = append(responses, response)
+	}
 
-	limitStr := c.DefaultQuery("limit", "5")
-	offsetStr := c.DefaultQuery("offset", "0")
+	c.JSON(http.StatusOK, responses)
+}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 5
+// parseDateParam разбирает дату в формате RFC 3339 или YYYY-MM-DD.
+// Второе значение сообщает, что время не было указано.
+func parseDateParam(value string) (*time.Time, bool, error) {
+	if value == "" {
+		return nil, false, nil
 	}
-
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return &date, true, nil
 	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, false, err
+	}
+	return &parsed, false, nil
+}
 
-	query = query.Order("name ASC").Limit(limit).Offset(offset)
-
-	if err := query.Find(&tenders).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": err.Error()})
+func GetUserTenders(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
 		return
 	}
 
-	var responses []schemas.TenderResponse
-	for _, tender := range tenders {
-		responses = append(responses, schemas.TenderResponse{
-			ID:          tender.ID,
-			Name:        tender.Name,
-			Description: tender.Description,
-			ServiceType: tender.ServiceType,
-			Status:      tender.Status,
-			Version:     tender.Version,
-			CreatedAt:   tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-		})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
 	}
 
-	if len(responses) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "No tenders found"})
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, responses)
-}
-
-func UpdateTender(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	page, ok := pagination.FromQuery(c)
 	if !ok {
 		return
 	}
-	tenderID := c.Param("tenderId")
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", tenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+	result, err := repositories.Tenders.List(c.Request.Context(), repository.TenderFilter{
+		Visible:         viewer.Visibility(),
+		CreatorUsername: employee.Username,
+	}, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve tenders")
 		return
 	}
 
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "username is required"})
-		return
+	var responses []schemas.TenderResponse
+	for _, tender := range pagination.Respond(c, result) {
+		responses = append(responses, newTenderResponse(tender))
 	}
 
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "User not found"})
+	if len(responses) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "No tenders found"})
 		return
 	}
 
-	if tender.CreatorUsername != username {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to update this tender"})
+	c.JSON(http.StatusOK, responses)
+}
+
+func UpdateTender(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	history := models.TenderHistory{
-		ID:              uuid.New(),
-		TenderID:        tender.ID,
-		Name:            tender.Name,
-		Description:     tender.Description,
-		ServiceType:     tender.ServiceType,
-		Status:          tender.Status,
-		Version:         tender.Version,
-		CreatorUsername: tender.CreatorUsername,
-		OrganizationID:  tender.OrganizationID,
-		CreatedAt:       tender.CreatedAt,
+	tenderID, ok := parseID(c, c.Param("tenderId"), "Tender not found")
+	if !ok {
+		return
 	}
 
-	if err := database.Create(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to save tender history"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
@@ -255,125 +390,80 @@ func UpdateTender(c *gin.Context) {
 		return
 	}
 
-	if updateRequest.Name != nil {
-		tender.Name = *updateRequest.Name
-	}
-	if updateRequest.Description != nil {
-		tender.Description = *updateRequest.Description
-	}
-	if updateRequest.ServiceType != nil {
-		tender.ServiceType = *updateRequest.ServiceType
-	}
-	tender.Version++
-
-	if err := database.Save(&tender).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update tender"})
+	tender, err := services.Tenders.Update(c.Request.Context(), employee, tenderID, updateRequest, ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to update tender")
 		return
 	}
+	c.Header("ETag", versionETag(tender.Version))
 
-	c.JSON(http.StatusOK, schemas.TenderResponse{
-		ID:          tender.ID,
-		Name:        tender.Name,
-		Description: tender.Description,
-		ServiceType: tender.ServiceType,
-		Status:      tender.Status,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-	})
+	c.JSON(http.StatusOK, newTenderResponse(tender))
 }
 
 func GetTenderStatus(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	repositories, ok := utils.GetRepositories(c)
 	if !ok {
 		return
 	}
-	tenderID := c.Param("tenderId")
-	username := c.Query("username")
 
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username must be provided"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", tenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
 		return
 	}
 
-	var employee models.Employee
-	result := database.Where("username = ?", username).First(&employee)
-	if result.Error != nil || result.RowsAffected == 0 {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
 		return
 	}
 
-	if tender.CreatorUsername != username {
+	if !viewer.CanViewTender(tender) {
 		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to view this tender"})
 		return
 	}
 
+	c.Header("ETag", versionETag(tender.Version))
+	if notModified(c, tender.Version) {
+		return
+	}
+
 	c.JSON(http.StatusOK, tender.Status)
 }
 
 func UpdateTenderStatus(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	services, ok := utils.GetServices(c)
 	if !ok {
 		return
 	}
-	tenderID := c.Param("tenderId")
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", tenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
-		return
-	}
-
-	username := c.Query("username")
-	if username == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Username is required"})
-		return
-	}
-
-	var employee models.Employee
-	if err := database.First(&employee, "username = ?", username).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "User not found"})
-		return
-	}
-
-	if tender.CreatorUsername != username {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to update this tender"})
+	tenderID, ok := parseID(c, c.Param("tenderId"), "Tender not found")
+	if !ok {
 		return
 	}
 
-	newStatus := c.Query("status")
-	validStatuses := map[string]bool{"Created": true, "Published": true, "Closed": true}
-	if !validStatuses[newStatus] {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid status"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	tender.Status = newStatus
-	if err := database.Save(&tender).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update tender status"})
+	tender, err := services.Tenders.ChangeStatus(c.Request.Context(), employee, tenderID, c.Query("status"), ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to update tender status")
 		return
 	}
+	c.Header("ETag", versionETag(tender.Version))
 
-	response := schemas.TenderResponse{
-		ID:          tender.ID,
-		Name:        tender.Name,
-		Description: tender.Description,
-		ServiceType: tender.ServiceType,
-		Status:      tender.Status,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
-	}
+	response := newTenderResponse(tender)
 
 	c.JSON(http.StatusOK, response)
 }
 
 func RollbackTender(c *gin.Context) {
-	database, ok := utils.GetDB(c)
+	services, ok := utils.GetServices(c)
 	if !ok {
 		return
 	}
@@ -395,74 +485,149 @@ func RollbackTender(c *gin.Context) {
 		return
 	}
 
-	var queryParams struct {
-		Username string `form:"username" binding:"required"`
+	user, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tenderID, ok := parseID(c, uriParams.TenderID, "Tender not found")
+	if !ok {
+		return
 	}
-	if err := c.ShouldBindQuery(&queryParams); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+
+	tender, err := services.Tenders.Rollback(c.Request.Context(), user, tenderID, uriParams.TenderVersion, ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to update tender")
 		return
 	}
+	c.Header("ETag", versionETag(tender.Version))
 
-	var user models.Employee
-	if err := database.Where("username = ?", queryParams.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"reason": "Invalid or non-existent user"})
+	tenderResponse := newTenderResponse(tender)
+
+	c.JSON(http.StatusOK, tenderResponse)
+}
+
+func GetTenderOwners(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
 		return
 	}
 
-	var tender models.Tender
-	if err := database.First(&tender, "id = ?", uriParams.TenderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender not found"})
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
 		return
 	}
 
-	if tender.CreatorUsername != queryParams.Username {
-		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to rollback this tender"})
+	tender, ok := findTender(c, repositories.Tenders, c.Param("tenderId"))
+	if !ok {
 		return
 	}
 
-	var history models.TenderHistory
-	if err := database.First(&history, "tender_id = ? AND version = ?", uriParams.TenderID, uriParams.TenderVersion).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"reason": "Tender version not found"})
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
 		return
 	}
 
-	currentHistory := models.TenderHistory{
-		TenderID:        tender.ID,
-		Name:            tender.Name,
-		Description:     tender.Description,
-		ServiceType:     tender.ServiceType,
-		Status:          tender.Status,
-		Version:         tender.Version,
-		CreatorUsername: tender.CreatorUsername,
-		OrganizationID:  tender.OrganizationID,
-		CreatedAt:       tender.CreatedAt,
+	if !viewer.IsResponsible(tender.OrganizationID) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to view owners of this tender"})
+		return
 	}
 
-	if err := database.Create(&currentHistory).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to save current tender history"})
+	owners, err := repositories.Tenders.Owners(c.Request.Context(), tender.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve tender owners"})
 		return
 	}
 
-	tender.Name = history.Name
-	tender.Description = history.Description
-	tender.ServiceType = history.ServiceType
-	tender.Status = history.Status
-	tender.Version++
+	c.JSON(http.StatusOK, newTenderOwnersResponse(owners))
+}
 
-	if err := database.Save(&tender).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to update tender"})
+// canManageTender отвечает 403 с сообщением reason, если сотрудник не
+// может управлять тендером.
+func canManageTender(c *gin.Context, repositories repository.Repositories, tender models.Tender, employee *models.Employee, reason string) bool {
+	manageable, err := policy.CanManageTender(c.Request.Context(), repositories, tender, employee.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to load user permissions"})
+		return false
+	}
+	if !manageable {
+		c.JSON(http.StatusForbidden, gin.H{"reason": reason})
+		return false
+	}
+	return true
+}
+
+// SetTenderOwners заменяет список владельцев тендера. Пустой список
+// возвращает управление всем ответственным за организацию.
+func SetTenderOwners(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
 		return
 	}
 
-	tenderResponse := schemas.TenderResponse{
-		ID:          tender.ID,
-		Name:        tender.Name,
-		Description: tender.Description,
-		ServiceType: tender.ServiceType,
-		Status:      tender.Status,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
 	}
 
-	c.JSON(http.StatusOK, tenderResponse)
+	tenderID, ok := parseID(c, c.Param("tenderId"), "Tender not found")
+	if !ok {
+		return
+	}
+
+	var request schemas.TenderOwnersRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	owners, err := services.Tenders.SetOwners(c.Request.Context(), employee, tenderID, request.Usernames)
+	if err != nil {
+		respondError(c, err, "Failed to update tender owners")
+		return
+	}
+
+	c.JSON(http.StatusOK, newTenderOwnersResponse(owners))
+}
+
+// TransferTenderCreator передаёт роль создателя тендера другому
+// ответственному за организацию.
+func TransferTenderCreator(c *gin.Context) {
+	services, ok := utils.GetServices(c)
+	if !ok {
+		return
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return
+	}
+
+	tenderID, ok := parseID(c, c.Param("tenderId"), "Tender not found")
+	if !ok {
+		return
+	}
+
+	var request schemas.TenderCreatorTransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	tender, err := services.Tenders.TransferCreator(c.Request.Context(), employee, tenderID, request.Username, ifMatch(c))
+	if err != nil {
+		respondError(c, err, "Failed to transfer tender")
+		return
+	}
+	c.Header("ETag", versionETag(tender.Version))
+
+	c.JSON(http.StatusOK, newTenderResponse(tender))
+}
+
+func newTenderOwnersResponse(employees []models.Employee) []schemas.TenderOwnerResponse {
+	owners := make([]schemas.TenderOwnerResponse, 0, len(employees))
+	for _, employee := range employees {
+		owners = append(owners, schemas.TenderOwnerResponse{UserID: employee.ID, Username: employee.Username})
+	}
+	return owners
 }
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/utils"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTenderVersions возвращает все версии тендера. История доступна только
+// ответственным организации: в ней могут быть неопубликованные черновики.
+func GetTenderVersions(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := tenderForHistory(c, repositories.Tenders)
+	if !ok {
+		return
+	}
+
+	versions, err := tenderVersions(c, repositories.Tenders, tender)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve tender versions"})
+		return
+	}
+
+	responses := make([]schemas.TenderResponse, 0, len(versions))
+	for _, version := range versions {
+		responses = append(responses, newTenderResponse(version))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func GetTenderVersion(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	number, ok := versionParam(c, c.Param("version"), "version")
+	if !ok {
+		return
+	}
+
+	tender, ok := tenderForHistory(c, repositories.Tenders)
+	if !ok {
+		return
+	}
+
+	versions, err := tenderVersions(c, repositories.Tenders, tender)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve tender versions"})
+		return
+	}
+
+	for _, version := range versions {
+		if version.Version == number {
+			c.JSON(http.StatusOK, newTenderResponse(version))
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"reason": "Tender version not found"})
+}
+
+// DiffTenderVersions возвращает поля, которые отличаются между версиями
+// from и to.
+func DiffTenderVersions(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := diffParams(c)
+	if !ok {
+		return
+	}
+
+	tender, ok := tenderForHistory(c, repositories.Tenders)
+	if !ok {
+		return
+	}
+
+	versions, err := tenderVersions(c, repositories.Tenders, tender)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve tender versions"})
+		return
+	}
+
+	byNumber := make(map[int]schemas.TenderResponse, len(versions))
+	for _, version := range versions {
+		byNumber[version.Version] = newTenderResponse(version)
+	}
+
+	respondWithDiff(c, from, to, byNumber[from], byNumber[to], hasVersion(byNumber, from) && hasVersion(byNumber, to))
+}
+
+func GetBidVersions(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	bid, ok := viewableBid(c)
+	if !ok {
+		return
+	}
+
+	versions, err := bidVersions(c, repositories.Bids, bid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bid versions"})
+		return
+	}
+
+	responses := make([]schemas.BidVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		responses = append(responses, newBidVersionResponse(version))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func GetBidVersion(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	number, ok := versionParam(c, c.Param("version"), "version")
+	if !ok {
+		return
+	}
+
+	bid, ok := viewableBid(c)
+	if !ok {
+		return
+	}
+
+	versions, err := bidVersions(c, repositories.Bids, bid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bid versions"})
+		return
+	}
+
+	for _, version := range versions {
+		if version.Version == number {
+			c.JSON(http.StatusOK, newBidVersionResponse(version))
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"reason": "Version not found in history"})
+}
+
+func DiffBidVersions(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := diffParams(c)
+	if !ok {
+		return
+	}
+
+	bid, ok := viewableBid(c)
+	if !ok {
+		return
+	}
+
+	versions, err := bidVersions(c, repositories.Bids, bid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve bid versions"})
+		return
+	}
+
+	byNumber := make(map[int]schemas.BidVersionResponse, len(versions))
+	for _, version := range versions {
+		byNumber[version.Version] = newBidVersionResponse(version)
+	}
+
+	respondWithDiff(c, from, to, byNumber[from], byNumber[to], hasVersion(byNumber, from) && hasVersion(byNumber, to))
+}
+
+func tenderForHistory(c *gin.Context, tenders repository.TenderRepository) (models.Tender, bool) {
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return models.Tender{}, false
+	}
+
+	tender, ok := findTender(c, tenders, c.Param("tenderId"))
+	if !ok {
+		return tender, false
+	}
+
+	viewer, ok := loadViewer(c, employee)
+	if !ok {
+		return tender, false
+	}
+
+	if !viewer.IsResponsible(tender.OrganizationID) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "Unauthorized to view the history of this tender"})
+		return tender, false
+	}
+
+	return tender, true
+}
+
+// tenderVersions возвращает все версии тендера по возрастанию номера:
+// сохранённые в истории и текущую.
+func tenderVersions(c *gin.Context, tenders repository.TenderRepository, tender models.Tender) ([]models.Tender, error) {
+	history, err := tenders.ListHistory(c.Request.Context(), tender.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]models.Tender, 0, len(history)+1)
+	for _, entry := range history {
+		if entry.Version < tender.Version {
+			versions = append(versions, entry.Snapshot())
+		}
+	}
+	return append(versions, tender), nil
+}
+
+func bidVersions(c *gin.Context, bids repository.BidRepository, bid models.Bid) ([]models.Bid, error) {
+	history, err := bids.ListHistory(c.Request.Context(), bid.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]models.Bid, 0, len(history)+1)
+	for _, entry := range history {
+		if entry.Version < bid.Version {
+			versions = append(versions, entry.Snapshot())
+		}
+	}
+	return append(versions, bid), nil
+}
+
+func newBidVersionResponse(bid models.Bid) schemas.BidVersionResponse {
+	return schemas.BidVersionResponse{
+		BidCreateResponse: schemas.NewBidResponse(bid),
+		Description:       bid.Description,
+		TenderID:          bid.TenderID,
+		Decision:          bid.Decision,
+	}
+}
+
+func versionParam(c *gin.Context, value string, name string) (int, bool) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid " + name + " value"})
+		return 0, false
+	}
+	return version, true
+}
+
+func diffParams(c *gin.Context) (int, int, bool) {
+	from, ok := versionParam(c, c.Query("from"), "from")
+	if !ok {
+		return 0, 0, false
+	}
+
+	to, ok := versionParam(c, c.Query("to"), "to")
+	if !ok {
+		return 0, 0, false
+	}
+
+	return from, to, true
+}
+
+func hasVersion[T any](versions map[int]T, number int) bool {
+	_, ok := versions[number]
+	return ok
+}
+
+// respondWithDiff сравнивает JSON-представления двух версий, поэтому имена
+// полей в ответе совпадают с именами полей в остальных ответах API.
+// Номер версии в сравнении не участвует.
+func respondWithDiff(c *gin.Context, from int, to int, before interface{}, after interface{}, found bool) {
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Version not found in history"})
+		return
+	}
+
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to compare versions"})
+		return
+	}
+
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to compare versions"})
+		return
+	}
+
+	names := make(map[string]bool, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+	delete(names, "version")
+
+	changes := make([]schemas.FieldChange, 0)
+	for name := range names {
+		if !reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			changes = append(changes, schemas.FieldChange{
+				Field: name,
+				From:  beforeFields[name],
+				To:    afterFields[name],
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	c.JSON(http.StatusOK, schemas.VersionDiffResponse{From: from, To: to, Changes: changes})
+}
+
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/policy"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/utils"
+	"ZADANIE-6105/webhooks"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newWebhookResponse(webhook models.Webhook) schemas.WebhookResponse {
+	return schemas.WebhookResponse{
+		ID:             webhook.ID,
+		OrganizationID: webhook.OrganizationID,
+		URL:            webhook.URL,
+		EventTypes:     webhook.EventTypes,
+		CreatedAt:      webhook.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
+// managedOrganization находит организацию из пути и проверяет, что
+// вызывающий может ею управлять.
+func managedOrganization(c *gin.Context) (models.Organization, bool) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return models.Organization{}, false
+	}
+
+	employee, ok := auth.CurrentEmployee(c)
+	if !ok {
+		return models.Organization{}, false
+	}
+
+	organization, ok := findOrganization(c, repositories.Organizations)
+	if !ok {
+		return organization, false
+	}
+
+	if !policy.CanManageOrganization(c.Request.Context(), repositories.Organizations, organization.ID, employee) {
+		c.JSON(http.StatusForbidden, gin.H{"reason": "User is not authorized to manage this organization"})
+		return organization, false
+	}
+
+	return organization, true
+}
+
+// CreateWebhook регистрирует вебхук организации. Секрет для проверки
+// подписи возвращается только в этом ответе.
+func CreateWebhook(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := managedOrganization(c)
+	if !ok {
+		return
+	}
+
+	var request schemas.WebhookCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	if err := webhooks.CheckURL(c.Request.Context(), request.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": err.Error()})
+		return
+	}
+
+	var eventTypes []string
+	for _, eventType := range request.EventTypes {
+		if !slices.Contains(webhooks.EventTypes, eventType) {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Unknown event type " + eventType})
+			return
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to generate webhook secret"})
+		return
+	}
+
+	webhook := models.Webhook{
+		OrganizationID: organization.ID,
+		URL:            request.URL,
+		Secret:         hex.EncodeToString(secret),
+		EventTypes:     eventTypes,
+		CreatedAt:      time.Now(),
+	}
+	ctx := c.Request.Context()
+	err := repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Webhooks.Create(ctx, &webhook); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionCreate,
+			EntityType: audit.EntityWebhook,
+			EntityID:   webhook.ID,
+			Actor:      auth.OptionalEmployee(c),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to create webhook"})
+		return
+	}
+
+	response := newWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	c.JSON(http.StatusOK, response)
+}
+
+func GetWebhooks(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := managedOrganization(c)
+	if !ok {
+		return
+	}
+
+	hooks, err := repositories.Webhooks.ListByOrganization(c.Request.Context(), organization.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retrieve webhooks"})
+		return
+	}
+
+	response := make([]schemas.WebhookResponse, 0, len(hooks))
+	for _, webhook := range hooks {
+		response = append(response, newWebhookResponse(webhook))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWebhook удаляет вебхук вместе с его доставками, в том числе
+// ожидающими повтора.
+func DeleteWebhook(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := managedOrganization(c)
+	if !ok {
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhookId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid webhookId format"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err = repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Webhooks.Delete(ctx, organization.ID, webhookID); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionDelete,
+			EntityType: audit.EntityWebhook,
+			EntityID:   webhookID,
+			Actor:      auth.OptionalEmployee(c),
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeadLetters возвращает доставки вебхуков организации,
+// исчерпавшие попытки, последние первыми.
+func GetWebhookDeadLetters(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := managedOrganization(c)
+	if !ok {
+		return
+	}
+
+	page, ok := pagination.FromQuery(c)
+	if !ok {
+		return
+	}
+
+	var webhookID *uuid.UUID
+	if value := c.Query("webhookId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid webhookId value"})
+			return
+		}
+		webhookID = &id
+	}
+
+	result, err := repositories.Webhooks.DeadLetters(c.Request.Context(), organization.ID, webhookID, page)
+	if err != nil {
+		pagination.RespondError(c, err, "Failed to retrieve dead letters")
+		return
+	}
+	rows := pagination.Respond(c, result)
+
+	response := make([]schemas.WebhookDeliveryResponse, 0, len(rows))
+	for _, row := range rows {
+		deadAt := row.DeadAt.Format("2006-01-02T15:04:05-07:00")
+		response = append(response, schemas.WebhookDeliveryResponse{
+			ID:         row.ID,
+			WebhookID:  row.WebhookID,
+			EventID:    row.EventID,
+			EventType:  row.EventType,
+			Payload:    row.Payload,
+			Attempts:   row.Attempts,
+			LastStatus: row.LastStatus,
+			LastError:  row.LastError,
+			DeadAt:     &deadAt,
+			CreatedAt:  row.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryWebhookDelivery возвращает мёртвую доставку в очередь с обнулённым
+// счётчиком попыток.
+func RetryWebhookDelivery(c *gin.Context) {
+	repositories, ok := utils.GetRepositories(c)
+	if !ok {
+		return
+	}
+
+	organization, ok := managedOrganization(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid deliveryId format"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err = repositories.Transaction(ctx, func(tx repository.Repositories) error {
+		if err := tx.Webhooks.Retry(ctx, organization.ID, deliveryID); err != nil {
+			return err
+		}
+		return audit.Write(ctx, tx, audit.Event{
+			Action:     audit.ActionRetry,
+			EntityType: audit.EntityWebhookDelivery,
+			EntityID:   deliveryID,
+			Actor:      auth.OptionalEmployee(c),
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"reason": "Dead delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"reason": "Failed to retry delivery"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery scheduled for retry"})
+}
@@ -0,0 +1,214 @@
+// Package idempotency защищает создающие запросы от повторов клиента.
+//
+// Клиент передаёт заголовок Idempotency-Key. Первый ответ на запрос с
+// этим ключом сохраняется в хранилище и возвращается на
+// повторы без повторного выполнения обработчика. Ключи принадлежат
+// вызывающему сотруднику: вошедшему по токену или, в режиме
+// совместимости, указанному в запросе. Запросы, вызывающего которых
+// определить не удалось, пропускаются как обычно.
+package idempotency
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	Header         = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	// lease — сколько ключ считается занятым выполняющимся запросом. Если
+	// процесс упал, не сохранив ответ, по истечении аренды ключ удаляется
+	// как просроченный и запрос можно повторить.
+	lease = time.Minute
+)
+
+// TTLFromEnv возвращает срок хранения ответов из IDEMPOTENCY_TTL
+// (по умолчанию 24 часа).
+func TTLFromEnv() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// Middleware сохраняет ответ на запрос с Idempotency-Key на срок ttl.
+// Повтор с тем же ключом и тем же запросом получает сохранённый ответ,
+// с тем же ключом и другим запросом — 409. Ответы 5xx не сохраняются,
+// чтобы клиент мог повторить запрос после сбоя.
+func Middleware(ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"reason": "Idempotency-Key is too long"})
+			return
+		}
+
+		repositories, ok := utils.GetRepositories(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"reason": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		employee := caller(c, repositories.Employees, body)
+		if employee == nil {
+			c.Next()
+			return
+		}
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+		keys := repositories.Idempotency
+		if err := keys.Purge(ctx, employee.ID, time.Now()); err != nil {
+			log.Printf("Failed to purge expired idempotency keys: %v", err)
+		}
+
+		record := models.IdempotencyKey{
+			EmployeeID:  employee.ID,
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(min(lease, ttl)),
+		}
+		reserved, err := keys.Reserve(ctx, &record)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"reason": "Failed to register idempotency key"})
+			return
+		}
+
+		if !reserved {
+			replay(c, keys, employee.ID, key, requestHash)
+			return
+		}
+
+		// Если обработчик упал, ключ освобождается, иначе повторы до
+		// истечения срока получали бы 409.
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				keys.Delete(ctx, employee.ID, key)
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if status := recorder.Status(); status >= http.StatusInternalServerError {
+			err = keys.Delete(ctx, employee.ID, key)
+		} else {
+			err = keys.Complete(ctx, employee.ID, key, status,
+				recorder.Header().Get("Content-Type"), recorder.body.Bytes(), time.Now().Add(ttl))
+		}
+		if err != nil {
+			log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// caller возвращает сотрудника, которому принадлежит ключ запроса. В
+// режиме совместимости это сотрудник из полей creatorUsername или
+// authorId тела, как его определяют обработчики; параметр username уже
+// разобран auth.Middleware.
+func caller(c *gin.Context, employees repository.EmployeeRepository, body []byte) *models.Employee {
+	if employee := auth.OptionalEmployee(c); employee != nil || !auth.LegacyEnabled(c) {
+		return employee
+	}
+
+	var fields struct {
+		CreatorUsername string `json:"creatorUsername"`
+		AuthorID        string `json:"authorId"`
+	}
+	_ = json.Unmarshal(body, &fields)
+
+	var employee models.Employee
+	var err error
+	switch {
+	case fields.CreatorUsername != "":
+		employee, err = employees.FindByUsername(c.Request.Context(), fields.CreatorUsername)
+	case fields.AuthorID != "":
+		id, parseErr := uuid.Parse(fields.AuthorID)
+		if parseErr != nil {
+			return nil
+		}
+		employee, err = employees.FindByID(c.Request.Context(), id)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return &employee
+}
+
+// replay отвечает на повтор запроса с уже использованным ключом.
+func replay(c *gin.Context, keys repository.IdempotencyRepository, employeeID uuid.UUID, key string, requestHash string) {
+	record, err := keys.Find(c.Request.Context(), employeeID, key)
+	if err != nil {
+		// Ключ мог истечь и удалиться параллельным запросом.
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"reason": "Idempotency-Key is being reused, retry the request"})
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"reason": "Idempotency-Key was already used with a different request"})
+		return
+	}
+
+	if record.Status == 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"reason": "A request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	c.Header(ReplayedHeader, "true")
+	audit.Skip(c)
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
+
+// responseRecorder копирует тело ответа, чтобы его можно было сохранить.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
@@ -0,0 +1,116 @@
+package idempotency
+
+import (
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(t *testing.T, repositories repository.Repositories, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	if err := repositories.Employees.Create(context.Background(), &models.Employee{Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("repositories", repositories)
+		c.Next()
+	})
+	r.Use(auth.Middleware(auth.NewManager(auth.Config{AllowUsernameParam: true}), repositories.Employees))
+	r.Use(Middleware(time.Hour))
+	r.POST("/tenders", handler)
+	return r
+}
+
+func send(r *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/tenders?username=alice", strings.NewReader(body))
+	request.Header.Set(Header, key)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	return response
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	r := newRouter(t, repository.NewMemory(), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"call": calls})
+	})
+
+	first := send(r, "key", `{"name":"a"}`)
+	if first.Code != http.StatusOK || first.Header().Get(ReplayedHeader) != "" {
+		t.Fatalf("first request: %d %q", first.Code, first.Header().Get(ReplayedHeader))
+	}
+
+	replayed := send(r, "key", `{"name":"a"}`)
+	if replayed.Code != http.StatusOK || replayed.Header().Get(ReplayedHeader) != "true" || replayed.Body.String() != first.Body.String() {
+		t.Errorf("replay: %d %q %s", replayed.Code, replayed.Header().Get(ReplayedHeader), replayed.Body)
+	}
+
+	if response := send(r, "key", `{"name":"b"}`); response.Code != http.StatusConflict {
+		t.Errorf("different request with the same key: %d, want 409", response.Code)
+	}
+
+	if response := send(r, "other", `{"name":"a"}`); response.Code != http.StatusOK || calls != 2 {
+		t.Errorf("another key: %d after %d calls", response.Code, calls)
+	}
+}
+
+func TestMiddlewareReleasesKeyOnServerError(t *testing.T) {
+	calls := 0
+	r := newRouter(t, repository.NewMemory(), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	if response := send(r, "key", `{}`); response.Code != http.StatusInternalServerError {
+		t.Fatalf("first request: %d", response.Code)
+	}
+	if response := send(r, "key", `{}`); response.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry after 5xx: %d after %d calls", response.Code, calls)
+	}
+}
+
+func TestMiddlewareExpiredLease(t *testing.T) {
+	repositories := repository.NewMemory()
+	r := newRouter(t, repositories, func(c *gin.Context) { c.Status(http.StatusCreated) })
+	ctx := context.Background()
+	employee, err := repositories.Employees.FindByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Запрос, процесс которого упал, не сохранив ответ.
+	record := models.IdempotencyKey{EmployeeID: employee.ID, Key: "key", RequestHash: "hash", ExpiresAt: time.Now().Add(time.Minute)}
+	if _, err := repositories.Idempotency.Reserve(ctx, &record); err != nil {
+		t.Fatal(err)
+	}
+	if response := send(r, "key", `{}`); response.Code != http.StatusConflict {
+		t.Errorf("key under lease: %d, want 409", response.Code)
+	}
+
+	record.ExpiresAt = time.Now().Add(-time.Second)
+	if err := repositories.Idempotency.Delete(ctx, employee.ID, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repositories.Idempotency.Reserve(ctx, &record); err != nil {
+		t.Fatal(err)
+	}
+	if response := send(r, "key", `{}`); response.Code != http.StatusCreated {
+		t.Errorf("key with expired lease: %d, want 201", response.Code)
+	}
+}
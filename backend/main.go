@@ -1,10 +1,24 @@
 package main
 
 import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/auth"
+	"ZADANIE-6105/email"
+	"ZADANIE-6105/idempotency"
 	"ZADANIE-6105/migrations"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
 	"ZADANIE-6105/routes"
+	"ZADANIE-6105/scheduler"
+	"ZADANIE-6105/service"
+	"ZADANIE-6105/storage"
+	"ZADANIE-6105/stream"
+	"ZADANIE-6105/webhooks"
+	"context"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -31,20 +45,60 @@ func main() {
 		log.Fatalf("Failed to connect to the database: %v", err)
 	}
 
+	repositories := repository.NewGorm(db)
+
+	schedulerInterval, err := time.ParseDuration(os.Getenv("SCHEDULER_INTERVAL"))
+	if err != nil || schedulerInterval <= 0 {
+		schedulerInterval = 30 * time.Second
+	}
+	go scheduler.Start(context.Background(), repositories, schedulerInterval)
+	go webhooks.NewWorker(repositories).Start(context.Background(), webhooks.IntervalFromEnv())
+
+	mailer, err := email.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+	go email.NewWorker(repositories, mailer).Start(context.Background(), email.IntervalFromEnv())
+
+	hub := stream.NewHub(postgresConn)
+	go hub.Start(context.Background())
+
+	store, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure file storage: %v", err)
+	}
+
+	services := service.New(repositories)
+
 	r := gin.Default()
 
+	// По умолчанию gin доверяет X-Forwarded-For от любого клиента, и IP в
+	// журнале аудита можно подделать. TRUSTED_PROXIES — список адресов или
+	// подсетей прокси через запятую; без него заголовок не учитывается.
+	if err := r.SetTrustedProxies(trustedProxies(os.Getenv("TRUSTED_PROXIES"))); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE", "PUT"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match", "If-None-Match", "Last-Event-ID", idempotency.Header},
+		ExposeHeaders:    []string{pagination.NextCursorHeader, pagination.TotalCountHeader, "ETag", idempotency.ReplayedHeader},
 		AllowCredentials: true,
 	}))
 
 	r.Use(func(c *gin.Context) {
 		c.Set("db", db)
+		c.Set("repositories", repositories)
+		c.Set("services", services)
+		c.Set("storage", store)
+		c.Set("hub", hub)
 		c.Next()
 	})
 
+	r.Use(auth.Middleware(auth.NewManager(auth.LoadConfig()), repositories.Employees))
+	r.Use(audit.Middleware())
+
 	routes.SetupRoutes(r, db)
 
 	serverAddress := os.Getenv("SERVER_ADDRESS")
@@ -55,3 +109,13 @@ func main() {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
+
+func trustedProxies(value string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -36,14 +36,100 @@ func RunMigrations(postgresConn string) {
 		log.Fatalf("Error creating extension uuid-ossp: %v", err)
 	}
 
-	// Создание типа данных bid_decision, если он не существует
+	// Создание типа данных decision_type, если он не существует.
+	// Раньше тип назывался bid_decision, но это имя теперь занимает таблица голосов.
 	err = db.Exec(`DO $$ BEGIN
-        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'bid_decision') THEN
-            CREATE TYPE bid_decision AS ENUM ('Approved', 'Rejected');
+        IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'bid_decision' AND typtype = 'e') THEN
+            ALTER TYPE bid_decision RENAME TO decision_type;
+        END IF;
+        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'decision_type') THEN
+            CREATE TYPE decision_type AS ENUM ('Approved', 'Rejected');
         END IF;
     END $$`).Error
 	if err != nil {
-		log.Fatalf("Error creating type bid_decision: %v", err)
+		log.Fatalf("Error creating type decision_type: %v", err)
+	}
+
+	// Уникальные индексы по (сущность, версия) нельзя создать, пока в
+	// истории есть дубли версий, оставшиеся с тех пор, когда правки шли без
+	// транзакций. Перед созданием индекса история таких сущностей один раз
+	// перенумеровывается; после создания индекса шаг больше не выполняется.
+	for _, history := range []struct {
+		model                        interface{}
+		index, table, column, entity string
+	}{
+		{&models.TenderHistory{}, "idx_tender_history_version", "tender_history", "tender_id", "tender"},
+		{&models.BidHistory{}, "idx_bid_history_version", "bid_history", "bid_id", "bid"},
+	} {
+		if !db.Migrator().HasTable(history.model) || db.Migrator().HasIndex(history.model, history.index) {
+			continue
+		}
+		renumbered, err := renumberHistory(db, history.table, history.column, history.entity)
+		if err != nil {
+			log.Fatalf("Error renumbering duplicate versions in %s: %v", history.table, err)
+		}
+		if renumbered > 0 {
+			log.Printf("Renumbered %d rows with duplicate versions in %s", renumbered, history.table)
+		}
+	}
+
+	// Уникальный индекс ответственных появился позже таблицы. Перед его
+	// созданием из повторяющихся пар (организация, сотрудник) остаётся одна
+	// строка; после создания индекса шаг больше не выполняется.
+	if db.Migrator().HasTable(&models.OrganizationResponsible{}) &&
+		!db.Migrator().HasIndex(&models.OrganizationResponsible{}, "idx_organization_responsible") {
+		result := db.Exec(`DELETE FROM organization_responsible duplicate USING organization_responsible kept
+        WHERE duplicate.organization_id = kept.organization_id
+            AND duplicate.user_id = kept.user_id
+            AND duplicate.id > kept.id`)
+		if result.Error != nil {
+			log.Fatalf("Error removing duplicate organization responsibles: %v", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Removed %d duplicate organization responsibles", result.RowsAffected)
+		}
+	}
+
+	// Раньше номер события ленты был сквозным (bigserial) и выдавался до
+	// фиксации, поэтому события тендера могли стать видны не по порядку.
+	// Теперь номер идёт подряд внутри тендера: старые события один раз
+	// перенумеровываются с сохранением порядка, ключом становится
+	// (tender_id, seq). Признак старой схемы — значение по умолчанию у seq.
+	var serialSeq bool
+	err = db.Raw(`SELECT EXISTS (SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'tender_event'
+            AND column_name = 'seq' AND column_default IS NOT NULL)`).Scan(&serialSeq).Error
+	if err != nil {
+		log.Fatalf("Error inspecting tender_event: %v", err)
+	}
+	if serialSeq {
+		var renumbered int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range []string{
+				`ALTER TABLE tender_event ALTER COLUMN seq DROP DEFAULT`,
+				`ALTER TABLE tender_event DROP CONSTRAINT tender_event_pkey`,
+				`DROP INDEX IF EXISTS idx_tender_event_tender_seq`,
+			} {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			result := tx.Exec(`UPDATE tender_event event SET seq = numbered.seq
+            FROM (SELECT seq AS old, ROW_NUMBER() OVER (PARTITION BY tender_id ORDER BY seq) AS seq FROM tender_event) numbered
+            WHERE event.seq = numbered.old`)
+			if result.Error != nil {
+				return result.Error
+			}
+			renumbered = result.RowsAffected
+			if err := tx.Exec(`ALTER TABLE tender_event ADD PRIMARY KEY (tender_id, seq)`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`DROP SEQUENCE IF EXISTS tender_event_seq_seq`).Error
+		})
+		if err != nil {
+			log.Fatalf("Error renumbering tender events: %v", err)
+		}
+		log.Printf("Renumbered %d tender events per tender", renumbered)
 	}
 
 	err = db.AutoMigrate(
@@ -51,26 +137,105 @@ func RunMigrations(postgresConn string) {
 		&models.Organization{},
 		&models.OrganizationResponsible{},
 		&models.Tender{},
+		&models.TenderOwner{},
 		&models.TenderHistory{},
 		&models.Bid{},
 		&models.BidHistory{},
 		&models.BidFeedback{},
+		&models.BidDecision{},
+		&models.TenderCriterion{},
+		&models.BidScore{},
+		&models.Attachment{},
+		&models.IdempotencyKey{},
+		&models.AuditEvent{},
+		&models.Webhook{},
+		&models.OutboxEvent{},
+		&models.WebhookDelivery{},
+		&models.TenderEvent{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.EmailMessage{},
 	)
 	if err != nil {
 		log.Fatalf("Error migrating database: %v", err)
 	}
 
+	// Поисковый вектор тендера по названию (вес A) и описанию (вес B)
+	// в русской и английской конфигурациях. Колонка генерируется самим
+	// Postgres, поэтому её нет в модели.
+	err = db.Exec(`ALTER TABLE tender ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+            setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED`).Error
+	if err != nil {
+		log.Fatalf("Error creating tender search vector: %v", err)
+	}
+
+	err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_tender_search_vector ON tender USING GIN (search_vector)`).Error
+	if err != nil {
+		log.Fatalf("Error creating tender search index: %v", err)
+	}
+
 	log.Println("Database migrated successfully")
 }
 
+// renumberHistory нумерует заново версии истории сущностей, у которых
+// версии повторяются: строки получают версии 1, 2, ... в прежнем порядке
+// (дубли — в порядке записи), а версия самой сущности становится больше
+// последней версии в истории. Возвращает число перенумерованных строк.
+func renumberHistory(db *gorm.DB, table, column, entity string) (int64, error) {
+	var renumbered int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`WITH duplicated AS (
+            SELECT DISTINCT ` + column + ` AS id FROM ` + table + `
+            GROUP BY ` + column + `, version HAVING COUNT(*) > 1
+        ), numbered AS (
+            SELECT history.ctid AS row,
+                ROW_NUMBER() OVER (PARTITION BY history.` + column + ` ORDER BY history.version, history.ctid) AS version
+            FROM ` + table + ` history JOIN duplicated ON duplicated.id = history.` + column + `
+        )
+        UPDATE ` + table + ` history SET version = numbered.version
+        FROM numbered WHERE history.ctid = numbered.row`)
+		if result.Error != nil {
+			return result.Error
+		}
+		renumbered = result.RowsAffected
+		if renumbered == 0 {
+			return nil
+		}
+
+		return tx.Exec(`UPDATE ` + entity + ` SET version = latest.version + 1
+        FROM (SELECT ` + column + ` AS id, MAX(version) AS version FROM ` + table + ` GROUP BY ` + column + `) latest
+        WHERE latest.id = ` + entity + `.id AND ` + entity + `.version <= latest.version`).Error
+	})
+	return renumbered, err
+}
+
 func dropTables(db *gorm.DB) error {
 	tables := []string{
 		"organizations",
 		"tenders",
+		"tender_owner",
 		"tender_histories",
 		"bids",
 		"bid_histories",
 		"bid_feedbacks",
+		"bid_decision",
+		"tender_criterion",
+		"bid_score",
+		"attachment",
+		"idempotency_key",
+		"audit_event",
+		"webhook",
+		"outbox_event",
+		"webhook_delivery",
+		"tender_event",
+		"notification",
+		"notification_preference",
+		"email_message",
 	}
 
 	for _, table := range tables {
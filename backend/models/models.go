@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type Employee struct {
@@ -12,14 +13,29 @@ type Employee struct {
 	Username  string    `gorm:"unique;not null"`
 	FirstName string
 	LastName  string
-	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
-	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	// Email — адрес для писем-уведомлений; без него письма не отправляются.
+	Email *string `gorm:"type:varchar(255)"`
+	// Language — язык писем: ru или en.
+	Language string `gorm:"type:varchar(2);not null;default:'ru'"`
+	// PasswordHash хранит bcrypt-хеш пароля и никогда не отдаётся наружу.
+	PasswordHash string `gorm:"column:password_hash" json:"-"`
+	// IsAdmin позволяет управлять любыми организациями.
+	IsAdmin bool `gorm:"not null;default:false"`
+	// DeactivatedAt выставляется при деактивации. Деактивированный сотрудник
+	// не может совершать действия, но его тендеры, предложения и история сохраняются.
+	DeactivatedAt *time.Time `gorm:"default:NULL"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 func (Employee) TableName() string {
 	return "employee"
 }
 
+func (e Employee) IsActive() bool {
+	return e.DeactivatedAt == nil
+}
+
 type OrganizationType string
 
 const (
@@ -43,8 +59,8 @@ func (Organization) TableName() string {
 
 type OrganizationResponsible struct {
 	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	OrganizationID uuid.UUID `gorm:"type:uuid;not null;foreignKey:OrganizationID"`
-	UserID         uuid.UUID `gorm:"type:uuid;not null;foreignKey:EmployeeID"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;foreignKey:OrganizationID;uniqueIndex:idx_organization_responsible"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;foreignKey:EmployeeID;uniqueIndex:idx_organization_responsible"`
 }
 
 func (OrganizationResponsible) TableName() string {
@@ -60,15 +76,36 @@ type Tender struct {
 	ServiceType     string    `json:"serviceType" gorm:"column:service_type" validate:"required,oneof=Construction Delivery Manufacture"`
 	Status          string    `json:"status" binding:"omitempty,oneof=Created Published Closed" gorm:"default:'Created'"`
 	OrganizationID  uuid.UUID `json:"organizationId" binding:"required,max=100"`
-	CreatorUsername string    `json:"creatorUsername" binding:"required"`
+	CreatorUsername string    `json:"creatorUsername"`
 	Version         int       `json:"version" gorm:"default:1"`
-	CreatedAt       time.Time `json:"createdAt"`
+	// SubmissionDeadline — срок подачи предложений, после него тендер
+	// закрывается планировщиком. PublishAt — время автоматической публикации.
+	// MaxBudget — необязательный потолок цены предложения в валюте Currency.
+	MaxBudget          *decimal.Decimal `json:"maxBudget" gorm:"type:numeric(18,2)"`
+	Currency           string           `json:"currency" binding:"omitempty,iso4217" gorm:"type:char(3)"`
+	SubmissionDeadline *time.Time       `json:"submissionDeadline" gorm:"index"`
+	PublishAt          *time.Time       `json:"publishAt" gorm:"index"`
+	// Sealed скрывает содержимое предложений от организации до истечения
+	// срока подачи или закрытия тендера. UnsealedAt фиксирует момент вскрытия.
+	Sealed     bool       `json:"sealed" gorm:"not null;default:false"`
+	UnsealedAt *time.Time `json:"unsealedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
 }
 
 func (Tender) TableName() string {
 	return "tender"
 }
 
+// IsSealed сообщает, скрыто ли к моменту now содержимое предложений тендера.
+func (t Tender) IsSealed(now time.Time) bool {
+	return t.Sealed && t.Status != TenderStatusClosed && !t.SubmissionClosed(now)
+}
+
+// SubmissionClosed сообщает, истёк ли к моменту now срок подачи предложений.
+func (t Tender) SubmissionClosed(now time.Time) bool {
+	return t.SubmissionDeadline != nil && !now.Before(*t.SubmissionDeadline)
+}
+
 func ValidateServiceType(serviceType string) bool {
 	for _, v := range validServiceTypes {
 		if serviceType == v {
@@ -91,23 +128,63 @@ func (t *Tender) Validate() error {
 	return validate.Struct(t)
 }
 
+// TenderOwner сужает круг тех, кто может управлять тендером. Если у тендера
+// нет ни одной записи, управлять им может любой ответственный за организацию.
+type TenderOwner struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	TenderID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tender_owner" json:"tenderId"`
+	EmployeeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tender_owner" json:"employeeId"`
+}
+
+func (TenderOwner) TableName() string {
+	return "tender_owner"
+}
+
 type TenderHistory struct {
-	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-	TenderID        uuid.UUID `gorm:"type:uuid" json:"tender_id"`
-	Name            string    `json:"name"`
-	Description     string    `json:"description"`
-	ServiceType     string    `json:"service_type"`
-	Status          string    `json:"status"`
-	Version         int       `json:"version"`
-	CreatorUsername string    `json:"creator_username"`
-	OrganizationID  uuid.UUID `gorm:"type:uuid" json:"organization_id"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                 uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	TenderID           uuid.UUID        `gorm:"type:uuid;uniqueIndex:idx_tender_history_version" json:"tender_id"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description"`
+	ServiceType        string           `json:"service_type"`
+	Status             string           `json:"status"`
+	Version            int              `gorm:"uniqueIndex:idx_tender_history_version" json:"version"`
+	CreatorUsername    string           `json:"creator_username"`
+	OrganizationID     uuid.UUID        `gorm:"type:uuid" json:"organization_id"`
+	MaxBudget          *decimal.Decimal `gorm:"type:numeric(18,2)" json:"max_budget"`
+	Currency           string           `gorm:"type:char(3)" json:"currency"`
+	SubmissionDeadline *time.Time       `json:"submission_deadline"`
+	PublishAt          *time.Time       `json:"publish_at"`
+	Sealed             bool             `json:"sealed"`
+	UnsealedAt         *time.Time       `json:"unsealed_at"`
+	CreatedAt          time.Time        `json:"created_at"`
 }
 
 func (TenderHistory) TableName() string {
 	return "tender_history"
 }
 
+// NewTenderHistory снимает копию текущего состояния тендера для истории версий.
+func NewTenderHistory(tender Tender) TenderHistory {
+	return TenderHistory{
+		ID:                 uuid.New(),
+		TenderID:           tender.ID,
+		Name:               tender.Name,
+		Description:        tender.Description,
+		ServiceType:        tender.ServiceType,
+		Status:             tender.Status,
+		Version:            tender.Version,
+		CreatorUsername:    tender.CreatorUsername,
+		OrganizationID:     tender.OrganizationID,
+		MaxBudget:          tender.MaxBudget,
+		Currency:           tender.Currency,
+		SubmissionDeadline: tender.SubmissionDeadline,
+		PublishAt:          tender.PublishAt,
+		Sealed:             tender.Sealed,
+		UnsealedAt:         tender.UnsealedAt,
+		CreatedAt:          tender.CreatedAt,
+	}
+}
+
 type Bid struct {
 	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
 	Name        string    `json:"name" binding:"required,max=100"`
@@ -118,31 +195,97 @@ type Bid struct {
 	AuthorID    uuid.UUID `json:"authorId" binding:"required"`
 	Version     int       `gorm:"default:1" json:"version" binding:"required,min=1"`
 	CreatedAt   time.Time `json:"createdAt" binding:"required"`
-	Decision    *string   `gorm:"type:bid_decision;default:NULL"`
+	Decision    *string   `gorm:"type:decision_type;default:NULL"`
+	// Price — сумма предложения с точностью до копеек в валюте Currency (ISO 4217).
+	Price    *decimal.Decimal `gorm:"type:numeric(18,2)" json:"price"`
+	Currency string           `gorm:"type:char(3)" json:"currency"`
+	// Selected выставляется при закрытии тендера: true у согласованного
+	// предложения, false у остальных. До закрытия тендера — NULL.
+	Selected *bool `gorm:"default:NULL" json:"selected"`
 }
 
 func (Bid) TableName() string {
 	return "bid"
 }
 
+// Snapshot восстанавливает тендер в состоянии сохранённой версии.
+func (h TenderHistory) Snapshot() Tender {
+	return Tender{
+		ID:                 h.TenderID,
+		Name:               h.Name,
+		Description:        h.Description,
+		ServiceType:        h.ServiceType,
+		Status:             h.Status,
+		OrganizationID:     h.OrganizationID,
+		CreatorUsername:    h.CreatorUsername,
+		Version:            h.Version,
+		MaxBudget:          h.MaxBudget,
+		Currency:           h.Currency,
+		SubmissionDeadline: h.SubmissionDeadline,
+		PublishAt:          h.PublishAt,
+		Sealed:             h.Sealed,
+		UnsealedAt:         h.UnsealedAt,
+		CreatedAt:          h.CreatedAt,
+	}
+}
+
 type BidHistory struct {
-	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	BidID       uuid.UUID `gorm:"type:uuid;not null" json:"bidId"`
-	Name        string    `json:"name" binding:"required,max=100"`
-	Description string    `json:"description" binding:"required,max=500"`
-	Status      string    `json:"status" binding:"required,oneof=Created Published Canceled"`
-	TenderID    uuid.UUID `gorm:"type:uuid;not null" json:"tender_id"`
-	AuthorType  string    `json:"authorType" binding:"required,oneof=Organisation, User"`
-	AuthorID    uuid.UUID `json:"authorId" binding:"required"`
-	Version     int       `gorm:"default:1" json:"version" binding:"required,min=1"`
-	CreatedAt   time.Time `json:"createdAt"`
-	Decision    *string   `gorm:"type:bid_decision;default:NULL"`
+	ID          uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	BidID       uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_bid_history_version" json:"bidId"`
+	Name        string           `json:"name" binding:"required,max=100"`
+	Description string           `json:"description" binding:"required,max=500"`
+	Status      string           `json:"status" binding:"required,oneof=Created Published Canceled"`
+	TenderID    uuid.UUID        `gorm:"type:uuid;not null" json:"tender_id"`
+	AuthorType  string           `json:"authorType" binding:"required,oneof=Organisation, User"`
+	AuthorID    uuid.UUID        `json:"authorId" binding:"required"`
+	Version     int              `gorm:"default:1;uniqueIndex:idx_bid_history_version" json:"version" binding:"required,min=1"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	Decision    *string          `gorm:"type:decision_type;default:NULL"`
+	Price       *decimal.Decimal `gorm:"type:numeric(18,2)" json:"price"`
+	Currency    string           `gorm:"type:char(3)" json:"currency"`
 }
 
 func (BidHistory) TableName() string {
 	return "bid_history"
 }
 
+// NewBidHistory снимает копию текущего состояния предложения для истории версий.
+func NewBidHistory(bid Bid) BidHistory {
+	return BidHistory{
+		ID:          uuid.New(),
+		BidID:       bid.ID,
+		Name:        bid.Name,
+		Description: bid.Description,
+		Status:      bid.Status,
+		TenderID:    bid.TenderID,
+		AuthorType:  bid.AuthorType,
+		AuthorID:    bid.AuthorID,
+		Version:     bid.Version,
+		CreatedAt:   bid.CreatedAt,
+		Decision:    bid.Decision,
+		Price:       bid.Price,
+		Currency:    bid.Currency,
+	}
+}
+
+// Snapshot восстанавливает предложение в состоянии сохранённой версии.
+func (h BidHistory) Snapshot() Bid {
+	return Bid{
+		ID:          h.BidID,
+		Name:        h.Name,
+		Description: h.Description,
+		Status:      h.Status,
+		TenderID:    h.TenderID,
+		AuthorType:  h.AuthorType,
+		AuthorID:    h.AuthorID,
+		Version:     h.Version,
+		CreatedAt:   h.CreatedAt,
+		Decision:    h.Decision,
+		Price:       h.Price,
+		Currency:    h.Currency,
+	}
+}
+
 type BidFeedback struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
 	BidID     uuid.UUID `gorm:"type:uuid;not null" json:"bid_id"`
@@ -154,3 +297,242 @@ type BidFeedback struct {
 func (BidFeedback) TableName() string {
 	return "bid_feedback"
 }
+
+const (
+	TenderStatusCreated   = "Created"
+	TenderStatusPublished = "Published"
+	TenderStatusClosed    = "Closed"
+)
+
+const (
+	DecisionApproved = "Approved"
+	DecisionRejected = "Rejected"
+)
+
+// BidDecision — голос одного ответственного по предложению.
+// Итоговое решение по предложению вычисляется из всех голосов по кворуму.
+type BidDecision struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	BidID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bid_decision_responsible" json:"bidId"`
+	ResponsibleID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bid_decision_responsible" json:"responsibleId"`
+	Decision      string    `gorm:"type:decision_type;not null" json:"decision"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func (BidDecision) TableName() string {
+	return "bid_decision"
+}
+
+// TenderCriterion — критерий оценки предложений с весом.
+type TenderCriterion struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	TenderID  uuid.UUID `gorm:"type:uuid;not null;index" json:"tenderId"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Weight    int       `gorm:"not null" json:"weight"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (TenderCriterion) TableName() string {
+	return "tender_criterion"
+}
+
+// BidScore — оценка предложения по одному критерию от одного ответственного.
+// Оценки хранятся отдельно по каждому оценщику, чтобы были видны расхождения.
+type BidScore struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	BidID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bid_score_evaluator" json:"bidId"`
+	CriterionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bid_score_evaluator" json:"criterionId"`
+	EvaluatorID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bid_score_evaluator" json:"evaluatorId"`
+	Score       int       `gorm:"not null" json:"score"`
+	Comment     string    `gorm:"type:text" json:"comment"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (BidScore) TableName() string {
+	return "bid_score"
+}
+
+const (
+	AttachmentEntityTender = "tender"
+	AttachmentEntityBid    = "bid"
+)
+
+// Attachment — файл, приложенный к тендеру или предложению. Содержимое
+// лежит в хранилище по ключу StorageKey, в базе только метаданные.
+type Attachment struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	EntityType  string    `gorm:"type:varchar(20);not null;index:idx_attachment_entity" json:"entityType"`
+	EntityID    uuid.UUID `gorm:"type:uuid;not null;index:idx_attachment_entity" json:"entityId"`
+	FileName    string    `gorm:"type:varchar(255);not null" json:"fileName"`
+	ContentType string    `gorm:"type:varchar(255);not null" json:"contentType"`
+	Size        int64     `gorm:"not null" json:"size"`
+	SHA256      string    `gorm:"type:char(64);not null" json:"sha256"`
+	StorageKey  string    `gorm:"type:varchar(255);not null" json:"-"`
+	UploadedBy  uuid.UUID `gorm:"type:uuid;not null" json:"uploadedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (Attachment) TableName() string {
+	return "attachment"
+}
+
+// IdempotencyKey — сохранённый ответ на запрос с заголовком Idempotency-Key.
+// Пока Status равен 0, запрос с этим ключом ещё выполняется.
+type IdempotencyKey struct {
+	EmployeeID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Key         string    `gorm:"type:varchar(255);primaryKey"`
+	RequestHash string    `gorm:"type:char(64);not null"`
+	Status      int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"type:varchar(255)"`
+	Body        []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_key"
+}
+
+// AuditEvent — запись журнала изменяющих действий. ActorID пуст у
+// действий планировщика.
+type AuditEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ActorID       *uuid.UUID `gorm:"type:uuid;index" json:"actorId"`
+	ActorUsername string     `gorm:"type:varchar(50)" json:"actorUsername,omitempty"`
+	Action        string     `gorm:"type:varchar(20);not null" json:"action"`
+	EntityType    string     `gorm:"type:varchar(20);index:idx_audit_event_entity" json:"entityType,omitempty"`
+	EntityID      *uuid.UUID `gorm:"type:uuid;index:idx_audit_event_entity" json:"entityId,omitempty"`
+	VersionBefore *int       `json:"versionBefore,omitempty"`
+	VersionAfter  *int       `json:"versionAfter,omitempty"`
+	Method        string     `gorm:"type:varchar(10)" json:"method,omitempty"`
+	Path          string     `gorm:"type:text" json:"path,omitempty"`
+	Status        int        `json:"status,omitempty"`
+	IP            string     `gorm:"type:varchar(64)" json:"ip,omitempty"`
+	UserAgent     string     `gorm:"type:text" json:"userAgent,omitempty"`
+	CreatedAt     time.Time  `gorm:"index" json:"createdAt"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_event"
+}
+
+// Webhook — адрес, на который организация получает события тендеров и
+// предложений. Secret подписывает доставки и показывается только при создании.
+type Webhook struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	URL            string    `gorm:"type:text;not null"`
+	Secret         string    `gorm:"type:varchar(64);not null"`
+	EventTypes     []string  `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt      time.Time
+}
+
+func (Webhook) TableName() string {
+	return "webhook"
+}
+
+// OutboxEvent — событие, записанное в одной транзакции с изменением.
+// Обработчик доставок раскладывает его по подпискам и ставит DispatchedAt.
+type OutboxEvent struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	EventType      string    `gorm:"type:varchar(50);not null"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null"`
+	Payload        []byte    `gorm:"type:jsonb;not null"`
+	CreatedAt      time.Time
+	DispatchedAt   *time.Time `gorm:"index"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_event"
+}
+
+// WebhookDelivery — доставка одного события одному вебхуку. Доставка
+// считается мёртвой (DeadAt), когда исчерпаны попытки.
+type WebhookDelivery struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	WebhookID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	EventID       uuid.UUID `gorm:"type:uuid;not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	LastStatus    int
+	LastError     string `gorm:"type:text"`
+	DeliveredAt   *time.Time
+	DeadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// TenderEvent — событие ленты активности тендера. Seq — номер события
+// внутри тендера без пропусков, по нему клиент возобновляет поток
+// (Last-Event-ID).
+type TenderEvent struct {
+	TenderID    uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Seq         int64      `gorm:"primaryKey;autoIncrement:false"`
+	Type        string     `gorm:"type:varchar(30);not null"`
+	BidID       *uuid.UUID `gorm:"type:uuid"`
+	BidAuthorID *uuid.UUID `gorm:"type:uuid"`
+	// Public — событие тендера видно всем, а не только ответственным:
+	// тендер был или стал опубликованным.
+	Public    bool   `gorm:"not null;default:false"`
+	Payload   []byte `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time
+}
+
+func (TenderEvent) TableName() string {
+	return "tender_event"
+}
+
+// Notification — уведомление сотрудника во входящих.
+type Notification struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	EmployeeID uuid.UUID  `gorm:"type:uuid;not null;index:idx_notification_employee_created,priority:1"`
+	Type       string     `gorm:"type:varchar(50);not null"`
+	Message    string     `gorm:"type:text;not null"`
+	TenderID   *uuid.UUID `gorm:"type:uuid"`
+	BidID      *uuid.UUID `gorm:"type:uuid"`
+	ReadAt     *time.Time
+	CreatedAt  time.Time `gorm:"index:idx_notification_employee_created,priority:2"`
+}
+
+func (Notification) TableName() string {
+	return "notification"
+}
+
+// NotificationPreference — выбор сотрудника, получать ли уведомления
+// типа Type. Без записи уведомления типа включены.
+type NotificationPreference struct {
+	EmployeeID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Type       string    `gorm:"type:varchar(50);primaryKey"`
+	Enabled    bool      `gorm:"not null"`
+	UpdatedAt  time.Time
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preference"
+}
+
+// EmailMessage — письмо в очереди отправки. Письмо отрисовывается при
+// постановке в очередь, чтобы повторы отправляли тот же текст.
+type EmailMessage struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	EmployeeID    *uuid.UUID `gorm:"type:uuid"`
+	To            string     `gorm:"column:recipient;type:varchar(255);not null"`
+	Template      string     `gorm:"type:varchar(50);not null"`
+	Subject       string     `gorm:"type:text;not null"`
+	Body          string     `gorm:"type:text;not null"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"not null;index"`
+	LastError     string     `gorm:"type:text"`
+	SentAt        *time.Time
+	DeadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (EmailMessage) TableName() string {
+	return "email_message"
+}
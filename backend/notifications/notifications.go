@@ -0,0 +1,195 @@
+// Package notifications создаёт уведомления во входящих сотрудников.
+//
+// Уведомления создаются в транзакции действия, которое их вызвало. Сотрудник
+// может отключить отдельные типы уведомлений (таблица
+// notification_preference); по умолчанию все типы включены. Для типов, у
+// которых есть шаблон письма, вместе с уведомлением в очередь ставится
+// письмо.
+package notifications
+
+import (
+	"ZADANIE-6105/email"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// TypeBidCreated — ответственным: на тендер организации подано
+	// предложение.
+	TypeBidCreated = "bid.created"
+	// TypeBidFeedback — автору предложения: оставлен отзыв.
+	TypeBidFeedback = "bid.feedback"
+	// TypeBidDecision — автору предложения: принято итоговое решение.
+	TypeBidDecision = "bid.decision"
+	// TypeTenderPublished — ответственным: тендер организации опубликован.
+	TypeTenderPublished = "tender.published"
+	// TypeTenderClosed — авторам предложений: тендер закрыт.
+	TypeTenderClosed = "tender.closed"
+)
+
+// Types — все типы уведомлений.
+var Types = []string{TypeBidCreated, TypeBidFeedback, TypeBidDecision, TypeTenderPublished, TypeTenderClosed}
+
+// Notify создаёт уведомление для каждого получателя, у которого этот тип
+// не отключён, и ставит письмо, если для типа есть шаблон. Повторы в
+// recipients и пустые ID пропускаются.
+func Notify(ctx context.Context, repositories repository.Repositories, recipients []uuid.UUID, notification models.Notification, data email.Data) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	disabled, err := repositories.Notifications.Disabled(ctx, notification.Type, recipients)
+	if err != nil {
+		return err
+	}
+
+	skip := make(map[uuid.UUID]bool, len(disabled)+1)
+	skip[uuid.Nil] = true
+	for _, id := range disabled {
+		skip[id] = true
+	}
+
+	now := time.Now()
+	var records []models.Notification
+	var notified []uuid.UUID
+	for _, recipient := range recipients {
+		if skip[recipient] {
+			continue
+		}
+		skip[recipient] = true
+
+		record := notification
+		record.EmployeeID = recipient
+		record.CreatedAt = now
+		records = append(records, record)
+		notified = append(notified, recipient)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := repositories.Notifications.Create(ctx, records); err != nil {
+		return err
+	}
+
+	if !email.HasTemplate(notification.Type) {
+		return nil
+	}
+
+	employees, err := repositories.Employees.FindByIDs(ctx, notified)
+	if err != nil {
+		return err
+	}
+	for _, employee := range employees {
+		if err := email.Enqueue(ctx, repositories, employee, notification.Type, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BidCreated уведомляет ответственных за организацию тендера, кроме
+// автора предложения. Название предложения не раскрывается: тендер может
+// быть запечатан.
+func BidCreated(ctx context.Context, repositories repository.Repositories, bid models.Bid, tender models.Tender) error {
+	responsibles, err := responsibleIDs(ctx, repositories, tender.OrganizationID)
+	if err != nil {
+		return err
+	}
+	responsibles = slices.DeleteFunc(responsibles, func(id uuid.UUID) bool { return id == bid.AuthorID })
+
+	return Notify(ctx, repositories, responsibles, models.Notification{
+		Type:     TypeBidCreated,
+		Message:  "A new bid was submitted for tender \"" + tender.Name + "\"",
+		TenderID: &tender.ID,
+		BidID:    &bid.ID,
+	}, email.Data{Tender: tender, Bid: bid})
+}
+
+// BidFeedback уведомляет автора предложения об отзыве.
+func BidFeedback(ctx context.Context, repositories repository.Repositories, bid models.Bid, tender models.Tender, feedback string) error {
+	return Notify(ctx, repositories, []uuid.UUID{bid.AuthorID}, models.Notification{
+		Type:     TypeBidFeedback,
+		Message:  "Your bid \"" + bid.Name + "\" for tender \"" + tender.Name + "\" received feedback",
+		TenderID: &tender.ID,
+		BidID:    &bid.ID,
+	}, email.Data{Tender: tender, Bid: bid, Feedback: feedback})
+}
+
+// BidDecided уведомляет автора предложения, когда итоговое решение
+// появилось или изменилось.
+func BidDecided(ctx context.Context, repositories repository.Repositories, bid models.Bid, tender models.Tender, previousDecision *string) error {
+	if bid.Decision == nil || (previousDecision != nil && *previousDecision == *bid.Decision) {
+		return nil
+	}
+
+	return Notify(ctx, repositories, []uuid.UUID{bid.AuthorID}, models.Notification{
+		Type:     TypeBidDecision,
+		Message:  "Your bid \"" + bid.Name + "\" for tender \"" + tender.Name + "\" was " + *bid.Decision,
+		TenderID: &tender.ID,
+		BidID:    &bid.ID,
+	}, email.Data{Tender: tender, Bid: bid, Decision: *bid.Decision})
+}
+
+// TenderStatusChanged уведомляет ответственных о публикации тендера, а
+// авторов предложений — о его закрытии.
+func TenderStatusChanged(ctx context.Context, repositories repository.Repositories, tender models.Tender, previousStatus string) error {
+	if tender.Status == previousStatus {
+		return nil
+	}
+
+	switch tender.Status {
+	case models.TenderStatusPublished:
+		return tenderPublished(ctx, repositories, tender)
+	case models.TenderStatusClosed:
+		return tenderClosed(ctx, repositories, tender)
+	}
+	return nil
+}
+
+func tenderPublished(ctx context.Context, repositories repository.Repositories, tender models.Tender) error {
+	responsibles, err := responsibleIDs(ctx, repositories, tender.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	return Notify(ctx, repositories, responsibles, models.Notification{
+		Type:     TypeTenderPublished,
+		Message:  "Tender \"" + tender.Name + "\" was published",
+		TenderID: &tender.ID,
+	}, email.Data{Tender: tender})
+}
+
+func tenderClosed(ctx context.Context, repositories repository.Repositories, tender models.Tender) error {
+	bids, err := repositories.Bids.ListByTender(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+	authors := make([]uuid.UUID, 0, len(bids))
+	for _, bid := range bids {
+		authors = append(authors, bid.AuthorID)
+	}
+
+	return Notify(ctx, repositories, authors, models.Notification{
+		Type:     TypeTenderClosed,
+		Message:  "Tender \"" + tender.Name + "\" was closed",
+		TenderID: &tender.ID,
+	}, email.Data{Tender: tender})
+}
+
+func responsibleIDs(ctx context.Context, repositories repository.Repositories, organizationID uuid.UUID) ([]uuid.UUID, error) {
+	responsibles, err := repositories.Organizations.Responsibles(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(responsibles))
+	for _, responsible := range responsibles {
+		ids = append(ids, responsible.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Query выбирает страницу запроса query: считает общее число строк (если
+// запрошено), добавляет сортировку по keys, условие продолжения после
+// курсора и лимит. Запрашивается на одну строку больше, чтобы понять,
+// есть ли следующая страница. Курсор от другой сортировки даёт
+// ErrCursorMismatch.
+func Query[T any](query *gorm.DB, page Page, keys []Key, values func(T) []any) (Result[T], error) {
+	if err := page.check(keys); err != nil {
+		return Result[T]{}, err
+	}
+
+	var total int64
+	if page.WithTotal {
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return Result[T]{}, err
+		}
+	}
+
+	if page.after != nil {
+		condition, args := keysetCondition(keys, page.after)
+		query = query.Where(condition, args...)
+	}
+
+	for _, key := range keys {
+		if key.Desc {
+			query = query.Order(key.Expr + " DESC")
+		} else {
+			query = query.Order(key.Expr + " ASC")
+		}
+	}
+
+	var rows []T
+	if err := query.Limit(page.Limit + 1).Offset(page.Offset).Find(&rows).Error; err != nil {
+		return Result[T]{}, err
+	}
+	return finish(page, keys, rows, total, values), nil
+}
+
+// keysetCondition строит условие "строка идёт после after" для
+// произвольного набора ключей с разными направлениями сортировки:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(keys []Key, after []string) (string, []interface{}) {
+	var (
+		alternatives []string
+		args         []interface{}
+	)
+
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].Expr+" = ?")
+			args = append(args, after[j])
+		}
+
+		operator := " > ?"
+		if key.Desc {
+			operator = " < ?"
+		}
+		parts = append(parts, key.Expr+operator)
+		args = append(args, after[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
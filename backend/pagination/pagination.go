@@ -0,0 +1,205 @@
+// Package pagination реализует общую для списков постраничную выдачу.
+//
+// Основной режим — keyset-курсоры: страница продолжается строго после
+// последней выданной строки, поэтому вставки во время листания не
+// приводят к пропускам и повторам. Курсор непрозрачен для клиента и
+// возвращается в заголовке X-Next-Cursor; если заголовка нет, страница
+// последняя. Параметр total=true добавляет заголовок X-Total-Count.
+// Смещение offset оставлено для совместимости со старыми клиентами.
+//
+// Хранилища выбирают страницу через Query (gorm) или Slice (срез в
+// памяти) и возвращают Result; обработчик отдаёт его через Respond.
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultLimit = 5
+	MaxLimit     = 100
+
+	NextCursorHeader = "X-Next-Cursor"
+	TotalCountHeader = "X-Total-Count"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// ErrCursorMismatch — курсор выдан для другой сортировки.
+var ErrCursorMismatch = errors.New("cursor does not match the requested sort order")
+
+// Key — выражение сортировки. Набор ключей должен однозначно упорядочивать
+// строки, поэтому последним ключом обычно идёт первичный ключ.
+// Выражение не должно давать NULL: сравнение с NULL ломает keyset.
+// Slice использует из ключа только направление.
+type Key struct {
+	Expr string
+	Desc bool
+}
+
+// Page — параметры запрошенной страницы.
+type Page struct {
+	Limit     int
+	Offset    int
+	WithTotal bool
+
+	after []string
+	sort  string
+}
+
+// FromQuery читает limit, offset, cursor и total из строки запроса.
+// При некорректных значениях отвечает 400 и возвращает false.
+func FromQuery(c *gin.Context) (Page, bool) {
+	page := Page{Limit: DefaultLimit}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > MaxLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid limit value"})
+			return page, false
+		}
+		page.Limit = limit
+	}
+
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid offset value"})
+			return page, false
+		}
+		page.Offset = offset
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		if page.Offset > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "cursor and offset cannot be combined"})
+			return page, false
+		}
+
+		var err error
+		page.sort, page.after, err = decode(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"reason": "Invalid cursor value"})
+			return page, false
+		}
+	}
+
+	page.WithTotal = c.Query("total") == "true"
+
+	return page, true
+}
+
+// Result — страница списка.
+type Result[T any] struct {
+	Items []T
+	// Total — число строк без учёта страницы, если оно запрошено.
+	Total int64
+
+	withTotal bool
+	next      string
+}
+
+// Respond выставляет заголовки X-Next-Cursor и X-Total-Count и
+// возвращает строки страницы.
+func Respond[T any](c *gin.Context, result Result[T]) []T {
+	if result.withTotal {
+		c.Header(TotalCountHeader, strconv.FormatInt(result.Total, 10))
+	}
+	if result.next != "" {
+		c.Header(NextCursorHeader, result.next)
+	}
+	return result.Items
+}
+
+// RespondError отвечает на ошибку выборки страницы: 400 на курсор от
+// другой сортировки, 500 с сообщением reason на прочие ошибки. Текст
+// внутренней ошибки клиенту не отдаётся, а пишется в лог.
+func RespondError(c *gin.Context, err error, reason string) {
+	if errors.Is(err, ErrCursorMismatch) {
+		c.JSON(http.StatusBadRequest, gin.H{"reason": "Cursor does not match the requested sort order"})
+		return
+	}
+	log.Printf("%s: %v", reason, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"reason": reason})
+}
+
+func (p Page) check(keys []Key) error {
+	if p.after != nil && (p.sort != signature(keys) || len(p.after) != len(keys)) {
+		return ErrCursorMismatch
+	}
+	return nil
+}
+
+// finish отбрасывает лишнюю строку и запоминает курсор следующей
+// страницы. values возвращает значения ключей сортировки строки в том же
+// порядке, что и keys.
+func finish[T any](p Page, keys []Key, rows []T, total int64, values func(T) []any) Result[T] {
+	if rows == nil {
+		rows = []T{}
+	}
+	result := Result[T]{Items: rows, Total: total, withTotal: p.WithTotal}
+	if len(rows) <= p.Limit {
+		return result
+	}
+
+	result.Items = rows[:p.Limit]
+	last := values(result.Items[len(result.Items)-1])
+	formatted := make([]string, 0, len(last))
+	for _, value := range last {
+		formatted = append(formatted, format(value))
+	}
+	result.next = encode(signature(keys), formatted)
+	return result
+}
+
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func encode(sort string, values []string) string {
+	data, _ := json.Marshal(cursor{Sort: sort, Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(value string) (string, []string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", nil, errInvalidCursor
+	}
+
+	var decoded cursor
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Values) == 0 {
+		return "", nil, errInvalidCursor
+	}
+
+	return decoded.Sort, decoded.Values, nil
+}
+
+// signature привязывает курсор к набору ключей сортировки.
+func signature(keys []Key) string {
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key.Expr))
+		if key.Desc {
+			hash.Write([]byte(" DESC"))
+		}
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// Time форматирует время для курсора без потери точности.
+func Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,146 @@
+package pagination
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Slice выбирает страницу из уже отфильтрованных строк так же, как Query
+// выбирает её из базы. values возвращает значения ключей сортировки
+// строки; поддерживаются string, uuid.UUID, time.Time, int, int64,
+// float32, float64 и decimal.Decimal.
+func Slice[T any](rows []T, page Page, keys []Key, values func(T) []any) (Result[T], error) {
+	if err := page.check(keys); err != nil {
+		return Result[T]{}, err
+	}
+
+	sorted := slices.Clone(rows)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		left, right := values(a), values(b)
+		for i, key := range keys {
+			if result := compare(left[i], right[i]); result != 0 {
+				if key.Desc {
+					return -result
+				}
+				return result
+			}
+		}
+		return 0
+	})
+
+	if page.after != nil {
+		start := len(sorted)
+		for i, row := range sorted {
+			after, err := isAfter(values(row), keys, page.after)
+			if err != nil {
+				return Result[T]{}, ErrCursorMismatch
+			}
+			if after {
+				start = i
+				break
+			}
+		}
+		sorted = sorted[start:]
+	}
+
+	sorted = sorted[min(page.Offset, len(sorted)):]
+	sorted = sorted[:min(page.Limit+1, len(sorted))]
+	return finish(page, keys, sorted, int64(len(rows)), values), nil
+}
+
+// isAfter сообщает, идёт ли строка со значениями ключей values строго
+// после курсора after.
+func isAfter(values []any, keys []Key, after []string) (bool, error) {
+	for i, key := range keys {
+		cursor, err := parse(values[i], after[i])
+		if err != nil {
+			return false, err
+		}
+		result := compare(values[i], cursor)
+		if key.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result > 0, nil
+		}
+	}
+	return false, nil
+}
+
+// format записывает значение ключа в курсор.
+func format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case uuid.UUID:
+		return v.String()
+	case time.Time:
+		return Time(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case decimal.Decimal:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parse читает значение курсора как значение того же типа, что и like.
+func parse(like any, value string) (any, error) {
+	switch like.(type) {
+	case uuid.UUID:
+		return uuid.Parse(value)
+	case time.Time:
+		return time.Parse(time.RFC3339Nano, value)
+	case int:
+		return strconv.Atoi(value)
+	case int64:
+		return strconv.ParseInt(value, 10, 64)
+	case float32:
+		parsed, err := strconv.ParseFloat(value, 32)
+		return float32(parsed), err
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	case decimal.Decimal:
+		return decimal.NewFromString(value)
+	default:
+		return value, nil
+	}
+}
+
+func compare(a, b any) int {
+	switch left := a.(type) {
+	case string:
+		return strings.Compare(left, b.(string))
+	case uuid.UUID:
+		// Postgres сравнивает uuid побайтно, как и строки в нижнем регистре.
+		return strings.Compare(left.String(), b.(uuid.UUID).String())
+	case time.Time:
+		return left.Compare(b.(time.Time))
+	case int:
+		return cmp.Compare(left, b.(int))
+	case int64:
+		return cmp.Compare(left, b.(int64))
+	case float32:
+		return cmp.Compare(left, b.(float32))
+	case float64:
+		return cmp.Compare(left, b.(float64))
+	case decimal.Decimal:
+		return left.Cmp(b.(decimal.Decimal))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
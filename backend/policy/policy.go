@@ -0,0 +1,127 @@
+// Package policy описывает, кто какие тендеры и предложения может видеть.
+//
+// Правила:
+//   - опубликованный тендер виден всем;
+//   - тендер в статусе Created или Closed виден только ответственным
+//     за организацию-владельца;
+//   - предложение видно только его автору и ответственным за организацию,
+//     которой принадлежит тендер.
+package policy
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Viewer — тот, кто запрашивает данные. Employee равен nil для
+// анонимного запроса.
+type Viewer struct {
+	Employee      *models.Employee
+	Organizations map[uuid.UUID]bool
+}
+
+func Anonymous() Viewer {
+	return Viewer{}
+}
+
+// LoadViewer загружает организации, за которые отвечает сотрудник.
+func LoadViewer(ctx context.Context, organizations repository.OrganizationRepository, employee *models.Employee) (Viewer, error) {
+	if employee == nil {
+		return Anonymous(), nil
+	}
+
+	organizationIDs, err := organizations.ResponsibleOrganizations(ctx, employee.ID)
+	if err != nil {
+		return Viewer{}, err
+	}
+
+	viewer := Viewer{Employee: employee, Organizations: make(map[uuid.UUID]bool, len(organizationIDs))}
+	for _, id := range organizationIDs {
+		viewer.Organizations[id] = true
+	}
+	return viewer, nil
+}
+
+func (v Viewer) IsResponsible(organizationID uuid.UUID) bool {
+	return v.Organizations[organizationID]
+}
+
+func (v Viewer) CanViewTender(tender models.Tender) bool {
+	if tender.Status == models.TenderStatusPublished {
+		return true
+	}
+	return v.IsResponsible(tender.OrganizationID)
+}
+
+// CanViewBid проверяет доступ к предложению; tender — тендер, к которому
+// относится предложение.
+func (v Viewer) CanViewBid(bid models.Bid, tender models.Tender) bool {
+	if v.Employee != nil && bid.AuthorID == v.Employee.ID {
+		return true
+	}
+	return v.IsResponsible(tender.OrganizationID)
+}
+
+// Visibility ограничивает выборки репозиториев тем, что видно v.
+func (v Viewer) Visibility() *repository.Visibility {
+	visibility := &repository.Visibility{}
+	if v.Employee != nil {
+		visibility.EmployeeID = v.Employee.ID
+	}
+	for id := range v.Organizations {
+		visibility.Organizations = append(visibility.Organizations, id)
+	}
+	return visibility
+}
+
+// CanManageOrganization разрешает менять организацию и её состав
+// ответственным за неё и администраторам.
+func CanManageOrganization(ctx context.Context, organizations repository.OrganizationRepository, organizationID uuid.UUID, employee *models.Employee) bool {
+	if employee.IsAdmin {
+		return true
+	}
+	responsible, err := organizations.IsResponsible(ctx, organizationID, employee.ID)
+	return err == nil && responsible
+}
+
+// CanManageTender разрешает редактировать тендер, менять его статус и
+// откатывать версии любому активному ответственному за организацию
+// тендера. Если у тендера задан список владельцев, — только владельцам,
+// которые ещё активны и отвечают за организацию. Если таких не осталось,
+// тендером снова может управлять любой активный ответственный, иначе
+// тендер остался бы без управления.
+func CanManageTender(ctx context.Context, repositories repository.Repositories, tender models.Tender, employeeID uuid.UUID) (bool, error) {
+	responsibles, err := repositories.Organizations.Responsibles(ctx, tender.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	active := make(map[uuid.UUID]bool, len(responsibles))
+	for _, responsible := range responsibles {
+		if responsible.IsActive() {
+			active[responsible.ID] = true
+		}
+	}
+	if !active[employeeID] {
+		return false, nil
+	}
+
+	owners, err := repositories.Tenders.Owners(ctx, tender.ID)
+	if err != nil {
+		return false, err
+	}
+
+	restricted := false
+	for _, owner := range owners {
+		if !active[owner.ID] {
+			continue
+		}
+		if owner.ID == employeeID {
+			return true, nil
+		}
+		restricted = true
+	}
+	return !restricted, nil
+}
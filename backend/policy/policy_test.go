@@ -0,0 +1,217 @@
+package policy
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ctx = context.Background()
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCanViewTender(t *testing.T) {
+	organizationID := uuid.New()
+	employee := &models.Employee{ID: uuid.New()}
+	responsible := Viewer{Employee: employee, Organizations: map[uuid.UUID]bool{organizationID: true}}
+	outsider := Viewer{Employee: employee, Organizations: map[uuid.UUID]bool{uuid.New(): true}}
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		status string
+		want   bool
+	}{
+		{"anonymous sees published", Anonymous(), models.TenderStatusPublished, true},
+		{"anonymous does not see created", Anonymous(), models.TenderStatusCreated, false},
+		{"anonymous does not see closed", Anonymous(), models.TenderStatusClosed, false},
+		{"outsider sees published", outsider, models.TenderStatusPublished, true},
+		{"outsider does not see created", outsider, models.TenderStatusCreated, false},
+		{"outsider does not see closed", outsider, models.TenderStatusClosed, false},
+		{"responsible sees created", responsible, models.TenderStatusCreated, true},
+		{"responsible sees closed", responsible, models.TenderStatusClosed, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tender := models.Tender{OrganizationID: organizationID, Status: test.status}
+			if got := test.viewer.CanViewTender(tender); got != test.want {
+				t.Fatalf("CanViewTender = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCanViewBid(t *testing.T) {
+	organizationID := uuid.New()
+	author := &models.Employee{ID: uuid.New()}
+	other := &models.Employee{ID: uuid.New()}
+	tender := models.Tender{OrganizationID: organizationID, Status: models.TenderStatusPublished}
+	bid := models.Bid{AuthorID: author.ID}
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		want   bool
+	}{
+		{"anonymous", Anonymous(), false},
+		{"author", Viewer{Employee: author}, true},
+		{"responsible", Viewer{Employee: other, Organizations: map[uuid.UUID]bool{organizationID: true}}, true},
+		{"responsible for another organization", Viewer{Employee: other, Organizations: map[uuid.UUID]bool{uuid.New(): true}}, false},
+		{"outsider", Viewer{Employee: other}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.viewer.CanViewBid(bid, tender); got != test.want {
+				t.Fatalf("CanViewBid = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadViewer(t *testing.T) {
+	repositories := repository.NewMemory()
+	organization := models.Organization{Name: "Alpha"}
+	employee := models.Employee{Username: "alice"}
+	check(t, repositories.Organizations.Create(ctx, &organization))
+	check(t, repositories.Employees.Create(ctx, &employee))
+	check(t, repositories.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{
+		OrganizationID: organization.ID,
+		UserID:         employee.ID,
+	}))
+
+	viewer, err := LoadViewer(ctx, repositories.Organizations, &employee)
+	check(t, err)
+	if !viewer.IsResponsible(organization.ID) || viewer.IsResponsible(uuid.New()) {
+		t.Fatalf("viewer organizations %v", viewer.Organizations)
+	}
+	visibility := viewer.Visibility()
+	if visibility.EmployeeID != employee.ID || len(visibility.Organizations) != 1 || visibility.Organizations[0] != organization.ID {
+		t.Fatalf("visibility %+v", visibility)
+	}
+
+	anonymous, err := LoadViewer(ctx, repositories.Organizations, nil)
+	check(t, err)
+	if anonymous.Employee != nil || anonymous.Visibility().EmployeeID != uuid.Nil {
+		t.Fatalf("anonymous viewer %+v", anonymous)
+	}
+}
+
+func TestCanManageOrganization(t *testing.T) {
+	repositories := repository.NewMemory()
+	organization := models.Organization{Name: "Alpha"}
+	check(t, repositories.Organizations.Create(ctx, &organization))
+	responsible := models.Employee{Username: "alice"}
+	outsider := models.Employee{Username: "bob"}
+	admin := models.Employee{Username: "root", IsAdmin: true}
+	for _, employee := range []*models.Employee{&responsible, &outsider, &admin} {
+		check(t, repositories.Employees.Create(ctx, employee))
+	}
+	check(t, repositories.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{
+		OrganizationID: organization.ID,
+		UserID:         responsible.ID,
+	}))
+
+	tests := []struct {
+		name     string
+		employee models.Employee
+		want     bool
+	}{
+		{"responsible", responsible, true},
+		{"outsider", outsider, false},
+		{"admin", admin, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CanManageOrganization(ctx, repositories.Organizations, organization.ID, &test.employee); got != test.want {
+				t.Fatalf("CanManageOrganization = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCanManageTender(t *testing.T) {
+	type setup struct {
+		owners []string
+		// deactivated и removed — сотрудники, которые деактивированы или
+		// больше не отвечают за организацию.
+		deactivated []string
+		removed     []string
+	}
+
+	tests := []struct {
+		name     string
+		setup    setup
+		employee string
+		want     bool
+	}{
+		{"responsible without owners", setup{}, "alice", true},
+		{"outsider", setup{}, "dave", false},
+		{"deactivated responsible", setup{deactivated: []string{"alice"}}, "alice", false},
+		{"owner", setup{owners: []string{"alice"}}, "alice", true},
+		{"responsible who is not an owner", setup{owners: []string{"alice"}}, "bob", false},
+		{"one of several owners", setup{owners: []string{"alice", "bob"}}, "bob", true},
+		{"deactivated owner", setup{owners: []string{"alice", "bob"}, deactivated: []string{"bob"}}, "bob", false},
+		{"other owner still active", setup{owners: []string{"alice", "bob"}, deactivated: []string{"bob"}}, "carol", false},
+		{"fallback when owners are deactivated", setup{owners: []string{"bob"}, deactivated: []string{"bob"}}, "carol", true},
+		{"fallback when owners are removed", setup{owners: []string{"bob"}, removed: []string{"bob"}}, "alice", true},
+		{"removed owner", setup{owners: []string{"bob"}, removed: []string{"bob"}}, "bob", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repositories := repository.NewMemory()
+			organization := models.Organization{Name: "Alpha"}
+			check(t, repositories.Organizations.Create(ctx, &organization))
+
+			employees := make(map[string]*models.Employee)
+			for _, username := range []string{"alice", "bob", "carol", "dave"} {
+				employee := &models.Employee{Username: username}
+				check(t, repositories.Employees.Create(ctx, employee))
+				employees[username] = employee
+				if username == "dave" {
+					continue
+				}
+				check(t, repositories.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{
+					OrganizationID: organization.ID,
+					UserID:         employee.ID,
+				}))
+			}
+
+			tender := models.Tender{Name: "Bridge", OrganizationID: organization.ID}
+			check(t, repositories.Tenders.Create(ctx, &tender))
+
+			owners := make([]uuid.UUID, 0, len(test.setup.owners))
+			for _, username := range test.setup.owners {
+				owners = append(owners, employees[username].ID)
+			}
+			check(t, repositories.Tenders.SetOwners(ctx, tender.ID, owners))
+
+			now := time.Now()
+			for _, username := range test.setup.deactivated {
+				employees[username].DeactivatedAt = &now
+				check(t, repositories.Employees.Save(ctx, employees[username]))
+			}
+			for _, username := range test.setup.removed {
+				check(t, repositories.Organizations.RemoveResponsible(ctx, organization.ID, employees[username].ID))
+			}
+
+			got, err := CanManageTender(ctx, repositories, tender, employees[test.employee].ID)
+			check(t, err)
+			if got != test.want {
+				t.Fatalf("CanManageTender = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
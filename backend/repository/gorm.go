@@ -0,0 +1,577 @@
+package repository
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewGorm создаёт репозитории поверх db. Внутри Transaction репозитории
+// работают в транзакции db.
+func NewGorm(db *gorm.DB) Repositories {
+	return Repositories{
+		Tenders:       gormTenders{db},
+		Bids:          gormBids{db},
+		Evaluations:   gormEvaluations{db},
+		Employees:     gormEmployees{db},
+		Organizations: gormOrganizations{db},
+		Attachments:   gormAttachments{db},
+		Webhooks:      gormWebhooks{db},
+		Notifications: gormNotifications{db},
+		Emails:        gormEmails{db},
+		Audit:         gormAudit{db},
+		Events:        gormEvents{db},
+		Idempotency:   gormIdempotency{db},
+		Locks:         gormLocks{db},
+		transaction: func(ctx context.Context, fn func(Repositories) error) error {
+			return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				return fn(NewGorm(tx))
+			})
+		},
+	}
+}
+
+// translate приводит ошибки gorm и Postgres к ошибкам пакета.
+func translate(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// query выбирает страницу и переводит ошибку базы.
+func query[T any](db *gorm.DB, page pagination.Page, keys []pagination.Key, values func(T) []any) (pagination.Result[T], error) {
+	result, err := pagination.Query(db, page, keys, values)
+	return result, translate(err)
+}
+
+type gormTenders struct {
+	db *gorm.DB
+}
+
+func (r gormTenders) FindByID(ctx context.Context, id uuid.UUID) (models.Tender, error) {
+	var tender models.Tender
+	err := r.db.WithContext(ctx).First(&tender, "id = ?", id).Error
+	return tender, translate(err)
+}
+
+func (r gormTenders) FindForUpdate(ctx context.Context, id uuid.UUID) (models.Tender, error) {
+	var tender models.Tender
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&tender, "id = ?", id).Error
+	return tender, translate(err)
+}
+
+func (r gormTenders) filtered(ctx context.Context, filter TenderFilter) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&models.Tender{})
+
+	if filter.Visible != nil {
+		if len(filter.Visible.Organizations) == 0 {
+			db = db.Where("tender.status = ?", models.TenderStatusPublished)
+		} else {
+			db = db.Where("tender.status = ? OR tender.organization_id IN ?", models.TenderStatusPublished, filter.Visible.Organizations)
+		}
+	}
+	if len(filter.ServiceTypes) > 0 {
+		db = db.Where("tender.service_type IN ?", filter.ServiceTypes)
+	}
+	if len(filter.Statuses) > 0 {
+		db = db.Where("tender.status IN ?", filter.Statuses)
+	}
+	if filter.OrganizationID != nil {
+		db = db.Where("tender.organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.CreatorUsername != "" {
+		db = db.Where("tender.creator_username = ?", filter.CreatorUsername)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("tender.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("tender.created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.CreatedBefore != nil {
+		db = db.Where("tender.created_at < ?", *filter.CreatedBefore)
+	}
+	return db
+}
+
+func (r gormTenders) List(ctx context.Context, filter TenderFilter, page pagination.Page) (pagination.Result[models.Tender], error) {
+	return query(r.filtered(ctx, filter), page, tenderKeys, tenderValues)
+}
+
+// Search ищет по поисковому вектору тендера. websearch_to_tsquery
+// понимает кавычки, OR и минус, как поисковики; запрос строится в обеих
+// конфигурациях, как и поисковый вектор.
+func (r gormTenders) Search(ctx context.Context, search string, filter TenderFilter, page pagination.Page) (pagination.Result[TenderMatch], error) {
+	db := r.filtered(ctx, filter).
+		Joins("CROSS JOIN (SELECT websearch_to_tsquery('russian', ?) || websearch_to_tsquery('english', ?) AS query) AS search", search, search).
+		Where("tender.search_vector @@ search.query").
+		Select(`tender.*,
+			ts_rank_cd(tender.search_vector, search.query) AS search_rank, ` +
+			headline("tender.name", "HighlightAll=true") + ` AS name_highlight, ` +
+			headline("tender.description", "MaxFragments=2, MaxWords=30, MinWords=10") + ` AS description_highlight`)
+	return query(db, page, tenderSearchKeys, tenderMatchValues)
+}
+
+// headline подсвечивает совпадения в колонке column в тех же
+// конфигурациях, что и поисковый вектор: ts_headline принимает одну
+// конфигурацию, поэтому сначала размечаются все совпадения по английской,
+// затем по разметке выбираются фрагменты по русской. Слова, найденные в
+// обеих конфигурациях, размечаются дважды, и двойная разметка убирается.
+func headline(column string, options string) string {
+	english := fmt.Sprintf("ts_headline('english', %s, search.query, 'StartSel=<b>, StopSel=</b>, HighlightAll=true')", column)
+	russian := fmt.Sprintf("ts_headline('russian', %s, search.query, 'StartSel=<b>, StopSel=</b>, %s')", english, options)
+	return fmt.Sprintf("replace(replace(%s, '<b><b>', '<b>'), '</b></b>', '</b>')", russian)
+}
+
+func (r gormTenders) Create(ctx context.Context, tender *models.Tender) error {
+	return translate(r.db.WithContext(ctx).Create(tender).Error)
+}
+
+func (r gormTenders) Save(ctx context.Context, tender *models.Tender) error {
+	return translate(r.db.WithContext(ctx).Save(tender).Error)
+}
+
+func (r gormTenders) ListHistory(ctx context.Context, tenderID uuid.UUID) ([]models.TenderHistory, error) {
+	var history []models.TenderHistory
+	err := r.db.WithContext(ctx).
+		Where("tender_id = ?", tenderID).
+		Order("version").
+		Find(&history).Error
+	return history, translate(err)
+}
+
+func (r gormTenders) FindHistory(ctx context.Context, tenderID uuid.UUID, version int) (models.TenderHistory, error) {
+	var history models.TenderHistory
+	err := r.db.WithContext(ctx).First(&history, "tender_id = ? AND version = ?", tenderID, version).Error
+	return history, translate(err)
+}
+
+func (r gormTenders) CreateHistory(ctx context.Context, history *models.TenderHistory) error {
+	return translate(r.db.WithContext(ctx).Create(history).Error)
+}
+
+func (r gormTenders) Owners(ctx context.Context, tenderID uuid.UUID) ([]models.Employee, error) {
+	var owners []models.Employee
+	err := r.db.WithContext(ctx).
+		Joins("JOIN tender_owner ON tender_owner.employee_id = employee.id").
+		Where("tender_owner.tender_id = ?", tenderID).
+		Order("employee.username").
+		Find(&owners).Error
+	return owners, translate(err)
+}
+
+func (r gormTenders) SetOwners(ctx context.Context, tenderID uuid.UUID, employeeIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tender_id = ?", tenderID).Delete(&models.TenderOwner{}).Error; err != nil {
+			return translate(err)
+		}
+		if len(employeeIDs) == 0 {
+			return nil
+		}
+
+		owners := make([]models.TenderOwner, 0, len(employeeIDs))
+		for _, employeeID := range employeeIDs {
+			owners = append(owners, models.TenderOwner{TenderID: tenderID, EmployeeID: employeeID})
+		}
+		return translate(tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&owners).Error)
+	})
+}
+
+func (r gormTenders) RemoveOwner(ctx context.Context, employeeID uuid.UUID, organizationID *uuid.UUID) error {
+	query := r.db.WithContext(ctx).Where("employee_id = ?", employeeID)
+	if organizationID != nil {
+		query = query.Where("tender_id IN (?)",
+			r.db.Model(&models.Tender{}).Select("id").Where("organization_id = ?", *organizationID))
+	}
+	return translate(query.Delete(&models.TenderOwner{}).Error)
+}
+
+func (r gormTenders) Due(ctx context.Context, due Due, now time.Time) ([]models.Tender, error) {
+	db := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"})
+	switch due {
+	case DuePublish:
+		db = db.Where("status = ? AND publish_at <= ?", models.TenderStatusCreated, now)
+	case DueClose:
+		db = db.Where("status IN ? AND submission_deadline <= ?",
+			[]string{models.TenderStatusCreated, models.TenderStatusPublished}, now)
+	case DueUnseal:
+		db = db.Where("sealed = ? AND unsealed_at IS NULL AND (status = ? OR submission_deadline <= ?)",
+			true, models.TenderStatusClosed, now)
+	default:
+		return nil, fmt.Errorf("unknown due rule %d", due)
+	}
+
+	var tenders []models.Tender
+	err := db.Order("created_at, id").Find(&tenders).Error
+	return tenders, translate(err)
+}
+
+type gormBids struct {
+	db *gorm.DB
+}
+
+func (r gormBids) FindByID(ctx context.Context, id uuid.UUID) (models.Bid, error) {
+	var bid models.Bid
+	err := r.db.WithContext(ctx).First(&bid, "id = ?", id).Error
+	return bid, translate(err)
+}
+
+func (r gormBids) FindForUpdate(ctx context.Context, id uuid.UUID) (models.Bid, error) {
+	var bid models.Bid
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&bid, "id = ?", id).Error
+	return bid, translate(err)
+}
+
+func (r gormBids) filtered(ctx context.Context, filter BidFilter) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&models.Bid{})
+
+	if visible := filter.Visible; visible != nil {
+		switch {
+		case visible.EmployeeID == uuid.Nil:
+			db = db.Where("1 = 0")
+		case len(visible.Organizations) == 0:
+			db = db.Where("bid.author_id = ?", visible.EmployeeID)
+		default:
+			db = db.Where("bid.author_id = ? OR bid.tender_id IN (?)", visible.EmployeeID,
+				r.db.Session(&gorm.Session{NewDB: true}).Model(&models.Tender{}).
+					Select("id").Where("organization_id IN ?", visible.Organizations))
+		}
+	}
+	if filter.TenderID != nil {
+		db = db.Where("bid.tender_id = ?", *filter.TenderID)
+	}
+	if filter.AuthorID != nil {
+		db = db.Where("bid.author_id = ?", *filter.AuthorID)
+	}
+	return db
+}
+
+func (r gormBids) List(ctx context.Context, filter BidFilter, order BidOrder, page pagination.Page) (pagination.Result[models.Bid], error) {
+	keys, values := bidOrderKeys(order)
+	return query(r.filtered(ctx, filter), page, keys, values)
+}
+
+func (r gormBids) Count(ctx context.Context, filter BidFilter) (int64, error) {
+	var count int64
+	err := r.filtered(ctx, filter).Count(&count).Error
+	return count, translate(err)
+}
+
+func (r gormBids) ListByTender(ctx context.Context, tenderID uuid.UUID) ([]models.Bid, error) {
+	var bids []models.Bid
+	err := r.db.WithContext(ctx).
+		Where("tender_id = ?", tenderID).
+		Order("created_at, id").
+		Find(&bids).Error
+	return bids, translate(err)
+}
+
+func (r gormBids) Create(ctx context.Context, bid *models.Bid) error {
+	return translate(r.db.WithContext(ctx).Create(bid).Error)
+}
+
+func (r gormBids) Save(ctx context.Context, bid *models.Bid) error {
+	return translate(r.db.WithContext(ctx).Save(bid).Error)
+}
+
+func (r gormBids) ListHistory(ctx context.Context, bidID uuid.UUID) ([]models.BidHistory, error) {
+	var history []models.BidHistory
+	err := r.db.WithContext(ctx).
+		Where("bid_id = ?", bidID).
+		Order("version").
+		Find(&history).Error
+	return history, translate(err)
+}
+
+func (r gormBids) FindHistory(ctx context.Context, bidID uuid.UUID, version int) (models.BidHistory, error) {
+	var history models.BidHistory
+	err := r.db.WithContext(ctx).First(&history, "bid_id = ? AND version = ?", bidID, version).Error
+	return history, translate(err)
+}
+
+func (r gormBids) CreateHistory(ctx context.Context, history *models.BidHistory) error {
+	return translate(r.db.WithContext(ctx).Create(history).Error)
+}
+
+func (r gormBids) Winner(ctx context.Context, tenderID uuid.UUID) (models.Bid, error) {
+	var bid models.Bid
+	err := r.db.WithContext(ctx).First(&bid, "tender_id = ? AND selected = ?", tenderID, true).Error
+	return bid, translate(err)
+}
+
+func (r gormBids) Votes(ctx context.Context, bidID uuid.UUID) ([]models.BidDecision, error) {
+	var votes []models.BidDecision
+	err := r.db.WithContext(ctx).
+		Where("bid_id = ?", bidID).
+		Order("updated_at, id").
+		Find(&votes).Error
+	return votes, translate(err)
+}
+
+func (r gormBids) SaveVote(ctx context.Context, vote *models.BidDecision) error {
+	return translate(r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bid_id"}, {Name: "responsible_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"decision", "updated_at"}),
+	}).Create(vote).Error)
+}
+
+func (r gormBids) CreateFeedback(ctx context.Context, feedback *models.BidFeedback) error {
+	return translate(r.db.WithContext(ctx).Create(feedback).Error)
+}
+
+func (r gormBids) ListFeedback(ctx context.Context, authorID uuid.UUID, page pagination.Page) (pagination.Result[models.BidFeedback], error) {
+	db := r.db.WithContext(ctx).Model(&models.BidFeedback{}).
+		Joins("JOIN bid ON bid.id = bid_feedback.bid_id").
+		Where("bid.author_id = ?", authorID).
+		Select("bid_feedback.*")
+	return query(db, page, feedbackKeys, feedbackValues)
+}
+
+type gormEvaluations struct {
+	db *gorm.DB
+}
+
+func (r gormEvaluations) Criteria(ctx context.Context, tenderID uuid.UUID) ([]models.TenderCriterion, error) {
+	var criteria []models.TenderCriterion
+	err := r.db.WithContext(ctx).
+		Where("tender_id = ?", tenderID).
+		Order("created_at, name, id").
+		Find(&criteria).Error
+	return criteria, translate(err)
+}
+
+func (r gormEvaluations) ReplaceCriteria(ctx context.Context, tenderID uuid.UUID, criteria []models.TenderCriterion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tender_id = ?", tenderID).Delete(&models.TenderCriterion{}).Error; err != nil {
+			return translate(err)
+		}
+		if len(criteria) == 0 {
+			return nil
+		}
+		return translate(tx.Create(&criteria).Error)
+	})
+}
+
+func (r gormEvaluations) SaveScores(ctx context.Context, scores []models.BidScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	return translate(r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bid_id"}, {Name: "criterion_id"}, {Name: "evaluator_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "comment", "updated_at"}),
+	}).Create(&scores).Error)
+}
+
+func (r gormEvaluations) Scores(ctx context.Context, tenderID uuid.UUID) ([]models.BidScore, error) {
+	var scores []models.BidScore
+	err := r.db.WithContext(ctx).
+		Joins("JOIN bid ON bid.id = bid_score.bid_id").
+		Where("bid.tender_id = ?", tenderID).
+		Order("bid_score.created_at, bid_score.id").
+		Find(&scores).Error
+	return scores, translate(err)
+}
+
+func (r gormEvaluations) CountScores(ctx context.Context, tenderID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.BidScore{}).
+		Joins("JOIN tender_criterion ON tender_criterion.id = bid_score.criterion_id").
+		Where("tender_criterion.tender_id = ?", tenderID).
+		Count(&count).Error
+	return count, translate(err)
+}
+
+type gormEmployees struct {
+	db *gorm.DB
+}
+
+func (r gormEmployees) FindByID(ctx context.Context, id uuid.UUID) (models.Employee, error) {
+	var employee models.Employee
+	err := r.db.WithContext(ctx).First(&employee, "id = ?", id).Error
+	return employee, translate(err)
+}
+
+func (r gormEmployees) FindByUsername(ctx context.Context, username string) (models.Employee, error) {
+	var employee models.Employee
+	err := r.db.WithContext(ctx).First(&employee, "username = ?", username).Error
+	return employee, translate(err)
+}
+
+func (r gormEmployees) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Employee, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var employees []models.Employee
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&employees).Error
+	return employees, translate(err)
+}
+
+func (r gormEmployees) List(ctx context.Context, filter EmployeeFilter, page pagination.Page) (pagination.Result[models.Employee], error) {
+	db := r.db.WithContext(ctx).Model(&models.Employee{})
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		db = db.Where("username ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?", pattern, pattern, pattern)
+	}
+	if filter.ActiveOnly {
+		db = db.Where("deactivated_at IS NULL")
+	}
+	return query(db, page, employeeKeys, employeeValues)
+}
+
+func (r gormEmployees) Create(ctx context.Context, employee *models.Employee) error {
+	return translate(r.db.WithContext(ctx).Create(employee).Error)
+}
+
+func (r gormEmployees) Save(ctx context.Context, employee *models.Employee) error {
+	return translate(r.db.WithContext(ctx).Save(employee).Error)
+}
+
+type gormOrganizations struct {
+	db *gorm.DB
+}
+
+func (r gormOrganizations) FindByID(ctx context.Context, id uuid.UUID) (models.Organization, error) {
+	var organization models.Organization
+	err := r.db.WithContext(ctx).First(&organization, "id = ?", id).Error
+	return organization, translate(err)
+}
+
+func (r gormOrganizations) FindForUpdate(ctx context.Context, id uuid.UUID) (models.Organization, error) {
+	var organization models.Organization
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&organization, "id = ?", id).Error
+	return organization, translate(err)
+}
+
+func (r gormOrganizations) List(ctx context.Context, page pagination.Page) (pagination.Result[models.Organization], error) {
+	return query(r.db.WithContext(ctx).Model(&models.Organization{}), page, organizationKeys, organizationValues)
+}
+
+func (r gormOrganizations) Create(ctx context.Context, organization *models.Organization) error {
+	return translate(r.db.WithContext(ctx).Create(organization).Error)
+}
+
+func (r gormOrganizations) Save(ctx context.Context, organization *models.Organization) error {
+	return translate(r.db.WithContext(ctx).Save(organization).Error)
+}
+
+func (r gormOrganizations) IsResponsible(ctx context.Context, organizationID uuid.UUID, employeeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.OrganizationResponsible{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, employeeID).
+		Count(&count).Error
+	return count > 0, translate(err)
+}
+
+func (r gormOrganizations) CountResponsibles(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.OrganizationResponsible{}).
+		Where("organization_id = ?", organizationID).
+		Count(&count).Error
+	return count, translate(err)
+}
+
+// AddResponsible полагается на уникальный индекс (organization_id,
+// user_id): параллельные добавления одного сотрудника не создают дублей.
+func (r gormOrganizations) AddResponsible(ctx context.Context, responsible *models.OrganizationResponsible) error {
+	return translate(r.db.WithContext(ctx).Create(responsible).Error)
+}
+
+func (r gormOrganizations) RemoveResponsible(ctx context.Context, organizationID uuid.UUID, employeeID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, employeeID).
+		Delete(&models.OrganizationResponsible{})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r gormOrganizations) ResponsibleOrganizations(ctx context.Context, employeeID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&models.OrganizationResponsible{}).
+		Where("user_id = ?", employeeID).
+		Pluck("organization_id", &ids).Error
+	return ids, translate(err)
+}
+
+func (r gormOrganizations) Responsibles(ctx context.Context, organizationID uuid.UUID) ([]models.Employee, error) {
+	var employees []models.Employee
+	err := r.db.WithContext(ctx).
+		Joins("JOIN organization_responsible ON organization_responsible.user_id = employee.id").
+		Where("organization_responsible.organization_id = ?", organizationID).
+		Order("employee.username").
+		Find(&employees).Error
+	return employees, translate(err)
+}
+
+type gormIdempotency struct {
+	db *gorm.DB
+}
+
+func (r gormIdempotency) Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	return result.RowsAffected > 0, translate(result.Error)
+}
+
+func (r gormIdempotency) Find(ctx context.Context, employeeID uuid.UUID, key string) (models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.db.WithContext(ctx).First(&record, "employee_id = ? AND key = ?", employeeID, key).Error
+	return record, translate(err)
+}
+
+func (r gormIdempotency) Complete(ctx context.Context, employeeID uuid.UUID, key string, status int, contentType string, body []byte, expiresAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("employee_id = ? AND key = ?", employeeID, key).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"content_type": contentType,
+			"body":         body,
+			"expires_at":   expiresAt,
+		})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r gormIdempotency) Delete(ctx context.Context, employeeID uuid.UUID, key string) error {
+	return translate(r.db.WithContext(ctx).
+		Where("employee_id = ? AND key = ?", employeeID, key).
+		Delete(&models.IdempotencyKey{}).Error)
+}
+
+func (r gormIdempotency) Purge(ctx context.Context, employeeID uuid.UUID, now time.Time) error {
+	return translate(r.db.WithContext(ctx).
+		Where("employee_id = ? AND expires_at < ?", employeeID, now).
+		Delete(&models.IdempotencyKey{}).Error)
+}
+
+type gormLocks struct {
+	db *gorm.DB
+}
+
+// TryLock берёт advisory-блокировку транзакции.
+func (r gormLocks) TryLock(ctx context.Context, key int64) (bool, error) {
+	var locked bool
+	err := r.db.WithContext(ctx).Raw("SELECT pg_try_advisory_xact_lock(?)", key).Scan(&locked).Error
+	return locked, translate(err)
+}
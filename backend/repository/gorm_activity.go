@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormAttachments struct {
+	db *gorm.DB
+}
+
+func (r gormAttachments) Find(ctx context.Context, entityType string, entityID uuid.UUID, id uuid.UUID) (models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.WithContext(ctx).
+		First(&attachment, "id = ? AND entity_type = ? AND entity_id = ?", id, entityType, entityID).Error
+	return attachment, translate(err)
+}
+
+func (r gormAttachments) ListByEntity(ctx context.Context, entityType string, entityID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at, id").
+		Find(&attachments).Error
+	return attachments, translate(err)
+}
+
+func (r gormAttachments) Create(ctx context.Context, attachment *models.Attachment) error {
+	return translate(r.db.WithContext(ctx).Create(attachment).Error)
+}
+
+func (r gormAttachments) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&models.Attachment{}, "id = ?", id)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type gormWebhooks struct {
+	db *gorm.DB
+}
+
+func (r gormWebhooks) Create(ctx context.Context, webhook *models.Webhook) error {
+	return translate(r.db.WithContext(ctx).Create(webhook).Error)
+}
+
+func (r gormWebhooks) ListByOrganization(ctx context.Context, organizationID uuid.UUID) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at, id").
+		Find(&webhooks).Error
+	return webhooks, translate(err)
+}
+
+func (r gormWebhooks) Delete(ctx context.Context, organizationID uuid.UUID, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND organization_id = ?", id, organizationID).Delete(&models.Webhook{})
+		if result.Error != nil {
+			return translate(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return translate(tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error)
+	})
+}
+
+func (r gormWebhooks) DeadLetters(ctx context.Context, organizationID uuid.UUID, webhookID *uuid.UUID, page pagination.Page) (pagination.Result[DeadLetter], error) {
+	db := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Select("webhook_delivery.*, outbox_event.event_type, outbox_event.payload").
+		Joins("JOIN webhook ON webhook.id = webhook_delivery.webhook_id").
+		Joins("JOIN outbox_event ON outbox_event.id = webhook_delivery.event_id").
+		Where("webhook.organization_id = ? AND webhook_delivery.dead_at IS NOT NULL", organizationID)
+	if webhookID != nil {
+		db = db.Where("webhook_delivery.webhook_id = ?", *webhookID)
+	}
+	return query(db, page, deadLetterKeys, deadLetterValues)
+}
+
+func (r gormWebhooks) Enqueue(ctx context.Context, event *models.OutboxEvent) error {
+	return translate(r.db.WithContext(ctx).Create(event).Error)
+}
+
+func (r gormWebhooks) Dispatch(ctx context.Context, limit int) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").
+			Order("created_at").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, event := range events {
+			var hooks []models.Webhook
+			if err := tx.Where("organization_id = ? AND event_types @> ?::jsonb",
+				event.OrganizationID, `["`+event.EventType+`"]`).
+				Find(&hooks).Error; err != nil {
+				return err
+			}
+
+			for _, hook := range hooks {
+				delivery := models.WebhookDelivery{
+					WebhookID:     hook.ID,
+					EventID:       event.ID,
+					NextAttemptAt: now,
+				}
+				if err := tx.Create(&delivery).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&event).Update("dispatched_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (r gormWebhooks) Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]PendingDelivery, error) {
+	var pending []PendingDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", until).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("webhook_delivery").
+			Select("webhook_delivery.*, webhook.url, webhook.secret, outbox_event.event_type, outbox_event.payload").
+			Joins("JOIN webhook ON webhook.id = webhook_delivery.webhook_id").
+			Joins("JOIN outbox_event ON outbox_event.id = webhook_delivery.event_id").
+			Where("webhook_delivery.id IN ?", ids).
+			Order("webhook_delivery.next_attempt_at").
+			Scan(&pending).Error
+	})
+	return pending, translate(err)
+}
+
+func (r gormWebhooks) Record(ctx context.Context, deliveryID uuid.UUID, attempt Attempt) error {
+	updates := attemptUpdates(attempt, "delivered_at")
+	updates["last_status"] = attempt.Status
+	return translate(r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ?", deliveryID).
+		Updates(updates).Error)
+}
+
+func (r gormWebhooks) Retry(ctx context.Context, organizationID uuid.UUID, deliveryID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND dead_at IS NOT NULL", deliveryID).
+		Where("webhook_id IN (?)", r.db.Session(&gorm.Session{NewDB: true}).Model(&models.Webhook{}).
+			Select("id").
+			Where("organization_id = ?", organizationID)).
+		Updates(map[string]interface{}{
+			"attempts":        0,
+			"dead_at":         nil,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type gormNotifications struct {
+	db *gorm.DB
+}
+
+func (r gormNotifications) List(ctx context.Context, employeeID uuid.UUID, filter NotificationFilter, page pagination.Page) (pagination.Result[models.Notification], error) {
+	db := r.db.WithContext(ctx).Model(&models.Notification{}).Where("employee_id = ?", employeeID)
+	if filter.Read != nil {
+		if *filter.Read {
+			db = db.Where("read_at IS NOT NULL")
+		} else {
+			db = db.Where("read_at IS NULL")
+		}
+	}
+	if filter.Type != "" {
+		db = db.Where("type = ?", filter.Type)
+	}
+	return query(db, page, notificationKeys, notificationValues)
+}
+
+func (r gormNotifications) MarkRead(ctx context.Context, employeeID uuid.UUID, id uuid.UUID) (models.Notification, error) {
+	var notification models.Notification
+	if err := r.db.WithContext(ctx).First(&notification, "id = ? AND employee_id = ?", id, employeeID).Error; err != nil {
+		return notification, translate(err)
+	}
+	if notification.ReadAt != nil {
+		return notification, nil
+	}
+
+	now := time.Now()
+	if err := r.db.WithContext(ctx).Model(&notification).
+		Where("read_at IS NULL").
+		Update("read_at", now).Error; err != nil {
+		return notification, translate(err)
+	}
+	notification.ReadAt = &now
+	return notification, nil
+}
+
+func (r gormNotifications) MarkAllRead(ctx context.Context, employeeID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("employee_id = ? AND read_at IS NULL", employeeID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, translate(result.Error)
+}
+
+func (r gormNotifications) Create(ctx context.Context, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return translate(r.db.WithContext(ctx).Create(&notifications).Error)
+}
+
+func (r gormNotifications) Preferences(ctx context.Context, employeeID uuid.UUID) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.WithContext(ctx).Where("employee_id = ?", employeeID).Order("type").Find(&preferences).Error
+	return preferences, translate(err)
+}
+
+func (r gormNotifications) SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return translate(r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "employee_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error)
+}
+
+func (r gormNotifications) Disabled(ctx context.Context, kind string, employeeIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(employeeIDs) == 0 {
+		return nil, nil
+	}
+	var disabled []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&models.NotificationPreference{}).
+		Where("employee_id IN ? AND type = ? AND enabled = ?", employeeIDs, kind, false).
+		Pluck("employee_id", &disabled).Error
+	return disabled, translate(err)
+}
+
+type gormEmails struct {
+	db *gorm.DB
+}
+
+func (r gormEmails) Enqueue(ctx context.Context, message *models.EmailMessage) error {
+	return translate(r.db.WithContext(ctx).Create(message).Error)
+}
+
+// Lease забирает письма в короткой транзакции: SKIP LOCKED позволяет
+// нескольким репликам разбирать очередь параллельно.
+func (r gormEmails) Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.EmailMessage, error) {
+	var messages []models.EmailMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(messages))
+		for i := range messages {
+			ids = append(ids, messages[i].ID)
+			messages[i].NextAttemptAt = until
+		}
+		return tx.Model(&models.EmailMessage{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", until).Error
+	})
+	return messages, translate(err)
+}
+
+func (r gormEmails) Record(ctx context.Context, messageID uuid.UUID, attempt Attempt) error {
+	return translate(r.db.WithContext(ctx).Model(&models.EmailMessage{}).
+		Where("id = ?", messageID).
+		Updates(attemptUpdates(attempt, "sent_at")).Error)
+}
+
+// attemptUpdates возвращает изменения строки очереди после попытки.
+// doneColumn — колонка времени успешного выполнения.
+func attemptUpdates(attempt Attempt, doneColumn string) map[string]interface{} {
+	updates := map[string]interface{}{
+		"attempts":   attempt.Attempts,
+		"last_error": attempt.Error,
+	}
+	switch {
+	case attempt.Done:
+		updates[doneColumn] = attempt.At
+	case attempt.Dead:
+		updates["dead_at"] = attempt.At
+	default:
+		updates["next_attempt_at"] = attempt.NextAttemptAt
+	}
+	return updates
+}
+
+type gormAudit struct {
+	db *gorm.DB
+}
+
+func (r gormAudit) List(ctx context.Context, filter AuditFilter, page pagination.Page) (pagination.Result[models.AuditEvent], error) {
+	db := r.db.WithContext(ctx).Model(&models.AuditEvent{})
+	if filter.EntityType != "" {
+		db = db.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		db = db.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.ActorID != nil {
+		db = db.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.ActorUsername != "" {
+		db = db.Where("actor_username = ?", filter.ActorUsername)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("created_at <= ?", *filter.To)
+	}
+	if filter.Before != nil {
+		db = db.Where("created_at < ?", *filter.Before)
+	}
+	return query(db, page, auditKeys, auditValues)
+}
+
+func (r gormAudit) Create(ctx context.Context, events []models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return translate(r.db.WithContext(ctx).Create(&events).Error)
+}
+
+type gormEvents struct {
+	db *gorm.DB
+}
+
+// Publish берёт следующий номер события тендера и отправляет pg_notify в
+// канале EventChannel с ID тендера. Postgres доставляет уведомление только
+// после фиксации транзакции.
+func (r gormEvents) Publish(ctx context.Context, event *models.TenderEvent) error {
+	db := r.db.WithContext(ctx)
+	err := db.Model(&models.TenderEvent{}).
+		Where("tender_id = ?", event.TenderID).
+		Select("COALESCE(MAX(seq), 0) + 1").
+		Scan(&event.Seq).Error
+	if err != nil {
+		return translate(err)
+	}
+	if err := db.Create(event).Error; err != nil {
+		return translate(err)
+	}
+	return translate(db.Exec("SELECT pg_notify(?, ?)", EventChannel, event.TenderID.String()).Error)
+}
+
+func (r gormEvents) After(ctx context.Context, tenderID uuid.UUID, seq int64, limit int) ([]models.TenderEvent, error) {
+	var events []models.TenderEvent
+	err := r.db.WithContext(ctx).
+		Where("tender_id = ? AND seq > ?", tenderID, seq).
+		Order("seq").
+		Limit(limit).
+		Find(&events).Error
+	return events, translate(err)
+}
+
+func (r gormEvents) LastSeq(ctx context.Context, tenderID uuid.UUID) (int64, error) {
+	var seq int64
+	err := r.db.WithContext(ctx).Model(&models.TenderEvent{}).
+		Where("tender_id = ?", tenderID).
+		Select("COALESCE(MAX(seq), 0)").
+		Scan(&seq).Error
+	return seq, translate(err)
+}
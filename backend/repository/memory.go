@@ -0,0 +1,1075 @@
+package repository
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewMemory создаёт пустые репозитории в памяти. Они безопасны для
+// параллельного использования. Transaction выполняет транзакции по одной
+// и при ошибке восстанавливает данные на момент начала транзакции, поэтому
+// изменения, сделанные параллельно вне транзакции, при откате теряются.
+// Вложенная транзакция откатывает только свои изменения, как точка
+// сохранения в Postgres.
+func NewMemory() Repositories {
+	store := &memoryStore{data: newMemoryData()}
+	return store.repositories(store.transaction)
+}
+
+// memoryData — таблицы хранилища. Записи хранятся по значению, поэтому
+// снимок данных — копия словарей.
+type memoryData struct {
+	tenders       map[uuid.UUID]models.Tender
+	tenderHistory map[uuid.UUID]models.TenderHistory
+	tenderOwners  map[uuid.UUID]models.TenderOwner
+	bids          map[uuid.UUID]models.Bid
+	bidHistory    map[uuid.UUID]models.BidHistory
+	votes         map[uuid.UUID]models.BidDecision
+	feedback      map[uuid.UUID]models.BidFeedback
+	criteria      map[uuid.UUID]models.TenderCriterion
+	scores        map[uuid.UUID]models.BidScore
+	employees     map[uuid.UUID]models.Employee
+	organizations map[uuid.UUID]models.Organization
+	responsibles  map[uuid.UUID]models.OrganizationResponsible
+	attachments   map[uuid.UUID]models.Attachment
+	webhooks      map[uuid.UUID]models.Webhook
+	outbox        map[uuid.UUID]models.OutboxEvent
+	deliveries    map[uuid.UUID]models.WebhookDelivery
+	notifications map[uuid.UUID]models.Notification
+	preferences   map[preferenceKey]models.NotificationPreference
+	emails        map[uuid.UUID]models.EmailMessage
+	audit         map[uuid.UUID]models.AuditEvent
+	events        map[eventKey]models.TenderEvent
+	idempotency   map[idempotencyKey]models.IdempotencyKey
+}
+
+type idempotencyKey struct {
+	employeeID uuid.UUID
+	key        string
+}
+
+type eventKey struct {
+	tenderID uuid.UUID
+	seq      int64
+}
+
+type preferenceKey struct {
+	employeeID uuid.UUID
+	kind       string
+}
+
+func newMemoryData() memoryData {
+	return memoryData{
+		tenders:       make(map[uuid.UUID]models.Tender),
+		tenderHistory: make(map[uuid.UUID]models.TenderHistory),
+		tenderOwners:  make(map[uuid.UUID]models.TenderOwner),
+		bids:          make(map[uuid.UUID]models.Bid),
+		bidHistory:    make(map[uuid.UUID]models.BidHistory),
+		votes:         make(map[uuid.UUID]models.BidDecision),
+		feedback:      make(map[uuid.UUID]models.BidFeedback),
+		criteria:      make(map[uuid.UUID]models.TenderCriterion),
+		scores:        make(map[uuid.UUID]models.BidScore),
+		employees:     make(map[uuid.UUID]models.Employee),
+		organizations: make(map[uuid.UUID]models.Organization),
+		responsibles:  make(map[uuid.UUID]models.OrganizationResponsible),
+		attachments:   make(map[uuid.UUID]models.Attachment),
+		webhooks:      make(map[uuid.UUID]models.Webhook),
+		outbox:        make(map[uuid.UUID]models.OutboxEvent),
+		deliveries:    make(map[uuid.UUID]models.WebhookDelivery),
+		notifications: make(map[uuid.UUID]models.Notification),
+		preferences:   make(map[preferenceKey]models.NotificationPreference),
+		emails:        make(map[uuid.UUID]models.EmailMessage),
+		audit:         make(map[uuid.UUID]models.AuditEvent),
+		events:        make(map[eventKey]models.TenderEvent),
+		idempotency:   make(map[idempotencyKey]models.IdempotencyKey),
+	}
+}
+
+func (d memoryData) clone() memoryData {
+	return memoryData{
+		tenders:       maps.Clone(d.tenders),
+		tenderHistory: maps.Clone(d.tenderHistory),
+		tenderOwners:  maps.Clone(d.tenderOwners),
+		bids:          maps.Clone(d.bids),
+		bidHistory:    maps.Clone(d.bidHistory),
+		votes:         maps.Clone(d.votes),
+		feedback:      maps.Clone(d.feedback),
+		criteria:      maps.Clone(d.criteria),
+		scores:        maps.Clone(d.scores),
+		employees:     maps.Clone(d.employees),
+		organizations: maps.Clone(d.organizations),
+		responsibles:  maps.Clone(d.responsibles),
+		attachments:   maps.Clone(d.attachments),
+		webhooks:      maps.Clone(d.webhooks),
+		outbox:        maps.Clone(d.outbox),
+		deliveries:    maps.Clone(d.deliveries),
+		notifications: maps.Clone(d.notifications),
+		preferences:   maps.Clone(d.preferences),
+		emails:        maps.Clone(d.emails),
+		audit:         maps.Clone(d.audit),
+		events:        maps.Clone(d.events),
+		idempotency:   maps.Clone(d.idempotency),
+	}
+}
+
+type memoryStore struct {
+	mu   sync.RWMutex
+	data memoryData
+
+	// transactionMu выполняет транзакции по одной.
+	transactionMu sync.Mutex
+}
+
+func (s *memoryStore) repositories(transaction func(context.Context, func(Repositories) error) error) Repositories {
+	return Repositories{
+		Tenders:       memoryTenders{s},
+		Bids:          memoryBids{s},
+		Evaluations:   memoryEvaluations{s},
+		Employees:     memoryEmployees{s},
+		Organizations: memoryOrganizations{s},
+		Attachments:   memoryAttachments{s},
+		Webhooks:      memoryWebhooks{s},
+		Notifications: memoryNotifications{s},
+		Emails:        memoryEmails{s},
+		Audit:         memoryAudit{s},
+		Events:        memoryEvents{s},
+		Idempotency:   memoryIdempotency{s},
+		Locks:         memoryLocks{},
+		transaction:   transaction,
+	}
+}
+
+func (s *memoryStore) transaction(ctx context.Context, fn func(Repositories) error) error {
+	s.transactionMu.Lock()
+	defer s.transactionMu.Unlock()
+
+	return s.savepoint(ctx, fn)
+}
+
+// savepoint выполняет fn и при ошибке восстанавливает данные на момент
+// вызова. Вызывается, когда транзакция уже начата.
+func (s *memoryStore) savepoint(_ context.Context, fn func(Repositories) error) error {
+	s.mu.RLock()
+	snapshot := s.data.clone()
+	s.mu.RUnlock()
+
+	if err := fn(s.repositories(s.savepoint)); err != nil {
+		s.mu.Lock()
+		s.data = snapshot
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// values возвращает записи словаря, отобранные match, в порядке compare.
+func values[K comparable, V any](records map[K]V, match func(V) bool, compare func(a, b V) int) []V {
+	var result []V
+	for _, record := range records {
+		if match == nil || match(record) {
+			result = append(result, record)
+		}
+	}
+	if compare != nil {
+		slices.SortFunc(result, compare)
+	}
+	return result
+}
+
+// byTimeAndID упорядочивает записи по времени, а при равенстве — по ID,
+// как ORDER BY created_at, id.
+func byTimeAndID(leftTime, rightTime time.Time, leftID, rightID uuid.UUID) int {
+	if result := leftTime.Compare(rightTime); result != 0 {
+		return result
+	}
+	return strings.Compare(leftID.String(), rightID.String())
+}
+
+// uniqueIDs возвращает ids без повторов.
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	ids = slices.Clone(ids)
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	return slices.Compact(ids)
+}
+
+type memoryTenders struct {
+	store *memoryStore
+}
+
+func (r memoryTenders) FindByID(_ context.Context, id uuid.UUID) (models.Tender, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tender, ok := r.store.data.tenders[id]
+	if !ok {
+		return models.Tender{}, ErrNotFound
+	}
+	return tender, nil
+}
+
+// FindForUpdate не блокирует запись: транзакции в памяти и так
+// выполняются по одной.
+func (r memoryTenders) FindForUpdate(ctx context.Context, id uuid.UUID) (models.Tender, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (f TenderFilter) matches(tender models.Tender) bool {
+	if f.Visible != nil && tender.Status != models.TenderStatusPublished && !slices.Contains(f.Visible.Organizations, tender.OrganizationID) {
+		return false
+	}
+	if len(f.ServiceTypes) > 0 && !slices.Contains(f.ServiceTypes, tender.ServiceType) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, tender.Status) {
+		return false
+	}
+	if f.OrganizationID != nil && tender.OrganizationID != *f.OrganizationID {
+		return false
+	}
+	if f.CreatorUsername != "" && tender.CreatorUsername != f.CreatorUsername {
+		return false
+	}
+	if f.CreatedFrom != nil && tender.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && tender.CreatedAt.After(*f.CreatedTo) {
+		return false
+	}
+	if f.CreatedBefore != nil && !tender.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func (r memoryTenders) List(_ context.Context, filter TenderFilter, page pagination.Page) (pagination.Result[models.Tender], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return pagination.Slice(values(r.store.data.tenders, filter.matches, nil), page, tenderKeys, tenderValues)
+}
+
+// Search упрощает полнотекстовый поиск Postgres: тендер подходит, если
+// каждое слово запроса встречается в названии или описании без учёта
+// регистра. Ранг — число вхождений, вхождение в название весит больше.
+func (r memoryTenders) Search(_ context.Context, search string, filter TenderFilter, page pagination.Page) (pagination.Result[TenderMatch], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	terms := strings.Fields(strings.ToLower(search))
+
+	var matches []TenderMatch
+	for _, tender := range values(r.store.data.tenders, filter.matches, nil) {
+		name, description := strings.ToLower(tender.Name), strings.ToLower(tender.Description)
+		match := TenderMatch{Tender: tender}
+		for _, term := range terms {
+			inName, inDescription := strings.Count(name, term), strings.Count(description, term)
+			if inName+inDescription == 0 {
+				match.SearchRank = 0
+				break
+			}
+			match.SearchRank += float64(inName) + 0.4*float64(inDescription)
+		}
+		if match.SearchRank == 0 {
+			continue
+		}
+		match.NameHighlight = highlight(tender.Name, terms)
+		match.DescriptionHighlight = highlight(tender.Description, terms)
+		matches = append(matches, match)
+	}
+
+	return pagination.Slice(matches, page, tenderSearchKeys, tenderMatchValues)
+}
+
+// highlight оборачивает вхождения terms в text в <b>…</b>.
+func highlight(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return text
+	}
+
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		for offset := 0; ; {
+			index := strings.Index(lower[offset:], term)
+			if index < 0 || term == "" {
+				break
+			}
+			for i := offset + index; i < offset+index+len(term); i++ {
+				marked[i] = true
+			}
+			offset += index + len(term)
+		}
+	}
+
+	var builder strings.Builder
+	for i := range text {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			builder.WriteString("<b>")
+		}
+		builder.WriteByte(text[i])
+		if marked[i] && (i == len(text)-1 || !marked[i+1]) {
+			builder.WriteString("</b>")
+		}
+	}
+	return builder.String()
+}
+
+// Create заполняет значения, которые в Postgres задаются по умолчанию.
+func (r memoryTenders) Create(_ context.Context, tender *models.Tender) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if tender.ID == uuid.Nil {
+		tender.ID = uuid.New()
+	}
+	if _, exists := r.store.data.tenders[tender.ID]; exists {
+		return ErrDuplicate
+	}
+	if tender.Status == "" {
+		tender.Status = models.TenderStatusCreated
+	}
+	if tender.Version == 0 {
+		tender.Version = 1
+	}
+	if tender.CreatedAt.IsZero() {
+		tender.CreatedAt = time.Now()
+	}
+	r.store.data.tenders[tender.ID] = *tender
+	return nil
+}
+
+func (r memoryTenders) Save(_ context.Context, tender *models.Tender) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if tender.ID == uuid.Nil {
+		tender.ID = uuid.New()
+	}
+	r.store.data.tenders[tender.ID] = *tender
+	return nil
+}
+
+func (r memoryTenders) ListHistory(_ context.Context, tenderID uuid.UUID) ([]models.TenderHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.tenderHistory,
+		func(history models.TenderHistory) bool { return history.TenderID == tenderID },
+		func(a, b models.TenderHistory) int { return cmp.Compare(a.Version, b.Version) }), nil
+}
+
+func (r memoryTenders) FindHistory(_ context.Context, tenderID uuid.UUID, version int) (models.TenderHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, history := range r.store.data.tenderHistory {
+		if history.TenderID == tenderID && history.Version == version {
+			return history, nil
+		}
+	}
+	return models.TenderHistory{}, ErrNotFound
+}
+
+// CreateHistory, как уникальный индекс в Postgres, не допускает двух
+// записей одной версии.
+func (r memoryTenders) CreateHistory(_ context.Context, history *models.TenderHistory) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.data.tenderHistory {
+		if existing.TenderID == history.TenderID && existing.Version == history.Version {
+			return ErrDuplicate
+		}
+	}
+	if history.ID == uuid.Nil {
+		history.ID = uuid.New()
+	}
+	r.store.data.tenderHistory[history.ID] = *history
+	return nil
+}
+
+func (r memoryTenders) Owners(_ context.Context, tenderID uuid.UUID) ([]models.Employee, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var owners []models.Employee
+	for _, owner := range r.store.data.tenderOwners {
+		if employee, ok := r.store.data.employees[owner.EmployeeID]; ok && owner.TenderID == tenderID {
+			owners = append(owners, employee)
+		}
+	}
+	slices.SortFunc(owners, func(a, b models.Employee) int {
+		return strings.Compare(a.Username, b.Username)
+	})
+	return owners, nil
+}
+
+func (r memoryTenders) SetOwners(_ context.Context, tenderID uuid.UUID, employeeIDs []uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	maps.DeleteFunc(r.store.data.tenderOwners, func(_ uuid.UUID, owner models.TenderOwner) bool {
+		return owner.TenderID == tenderID
+	})
+	for _, employeeID := range uniqueIDs(employeeIDs) {
+		owner := models.TenderOwner{ID: uuid.New(), TenderID: tenderID, EmployeeID: employeeID}
+		r.store.data.tenderOwners[owner.ID] = owner
+	}
+	return nil
+}
+
+func (r memoryTenders) RemoveOwner(_ context.Context, employeeID uuid.UUID, organizationID *uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	maps.DeleteFunc(r.store.data.tenderOwners, func(_ uuid.UUID, owner models.TenderOwner) bool {
+		if owner.EmployeeID != employeeID {
+			return false
+		}
+		return organizationID == nil || r.store.data.tenders[owner.TenderID].OrganizationID == *organizationID
+	})
+	return nil
+}
+
+func (r memoryTenders) Due(_ context.Context, due Due, now time.Time) ([]models.Tender, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var match func(models.Tender) bool
+	switch due {
+	case DuePublish:
+		match = func(tender models.Tender) bool {
+			return tender.Status == models.TenderStatusCreated && tender.PublishAt != nil && !tender.PublishAt.After(now)
+		}
+	case DueClose:
+		match = func(tender models.Tender) bool {
+			return (tender.Status == models.TenderStatusCreated || tender.Status == models.TenderStatusPublished) &&
+				tender.SubmissionClosed(now)
+		}
+	case DueUnseal:
+		match = func(tender models.Tender) bool {
+			return tender.Sealed && tender.UnsealedAt == nil &&
+				(tender.Status == models.TenderStatusClosed || tender.SubmissionClosed(now))
+		}
+	default:
+		return nil, fmt.Errorf("unknown due rule %d", due)
+	}
+
+	return values(r.store.data.tenders, match, func(a, b models.Tender) int {
+		return byTimeAndID(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	}), nil
+}
+
+type memoryBids struct {
+	store *memoryStore
+}
+
+func (r memoryBids) FindByID(_ context.Context, id uuid.UUID) (models.Bid, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	bid, ok := r.store.data.bids[id]
+	if !ok {
+		return models.Bid{}, ErrNotFound
+	}
+	return bid, nil
+}
+
+func (r memoryBids) FindForUpdate(ctx context.Context, id uuid.UUID) (models.Bid, error) {
+	return r.FindByID(ctx, id)
+}
+
+// filtered возвращает предложения, подходящие под filter. Вызывается под
+// блокировкой хранилища.
+func (r memoryBids) filtered(filter BidFilter) []models.Bid {
+	return values(r.store.data.bids, func(bid models.Bid) bool {
+		if visible := filter.Visible; visible != nil {
+			if visible.EmployeeID == uuid.Nil {
+				return false
+			}
+			tender := r.store.data.tenders[bid.TenderID]
+			if bid.AuthorID != visible.EmployeeID && !slices.Contains(visible.Organizations, tender.OrganizationID) {
+				return false
+			}
+		}
+		if filter.TenderID != nil && bid.TenderID != *filter.TenderID {
+			return false
+		}
+		return filter.AuthorID == nil || bid.AuthorID == *filter.AuthorID
+	}, nil)
+}
+
+func (r memoryBids) List(_ context.Context, filter BidFilter, order BidOrder, page pagination.Page) (pagination.Result[models.Bid], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys, values := bidOrderKeys(order)
+	return pagination.Slice(r.filtered(filter), page, keys, values)
+}
+
+func (r memoryBids) Count(_ context.Context, filter BidFilter) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.filtered(filter))), nil
+}
+
+func (r memoryBids) ListByTender(_ context.Context, tenderID uuid.UUID) ([]models.Bid, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.bids,
+		func(bid models.Bid) bool { return bid.TenderID == tenderID },
+		func(a, b models.Bid) int { return byTimeAndID(a.CreatedAt, b.CreatedAt, a.ID, b.ID) }), nil
+}
+
+func (r memoryBids) Create(_ context.Context, bid *models.Bid) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if bid.ID == uuid.Nil {
+		bid.ID = uuid.New()
+	}
+	if _, exists := r.store.data.bids[bid.ID]; exists {
+		return ErrDuplicate
+	}
+	if bid.Version == 0 {
+		bid.Version = 1
+	}
+	if bid.CreatedAt.IsZero() {
+		bid.CreatedAt = time.Now()
+	}
+	r.store.data.bids[bid.ID] = *bid
+	return nil
+}
+
+func (r memoryBids) Save(_ context.Context, bid *models.Bid) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if bid.ID == uuid.Nil {
+		bid.ID = uuid.New()
+	}
+	r.store.data.bids[bid.ID] = *bid
+	return nil
+}
+
+func (r memoryBids) ListHistory(_ context.Context, bidID uuid.UUID) ([]models.BidHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.bidHistory,
+		func(history models.BidHistory) bool { return history.BidID == bidID },
+		func(a, b models.BidHistory) int { return cmp.Compare(a.Version, b.Version) }), nil
+}
+
+func (r memoryBids) FindHistory(_ context.Context, bidID uuid.UUID, version int) (models.BidHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, history := range r.store.data.bidHistory {
+		if history.BidID == bidID && history.Version == version {
+			return history, nil
+		}
+	}
+	return models.BidHistory{}, ErrNotFound
+}
+
+func (r memoryBids) CreateHistory(_ context.Context, history *models.BidHistory) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.data.bidHistory {
+		if existing.BidID == history.BidID && existing.Version == history.Version {
+			return ErrDuplicate
+		}
+	}
+	if history.ID == uuid.Nil {
+		history.ID = uuid.New()
+	}
+	r.store.data.bidHistory[history.ID] = *history
+	return nil
+}
+
+func (r memoryBids) Winner(_ context.Context, tenderID uuid.UUID) (models.Bid, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, bid := range r.store.data.bids {
+		if bid.TenderID == tenderID && bid.Selected != nil && *bid.Selected {
+			return bid, nil
+		}
+	}
+	return models.Bid{}, ErrNotFound
+}
+
+func (r memoryBids) Votes(_ context.Context, bidID uuid.UUID) ([]models.BidDecision, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.votes,
+		func(vote models.BidDecision) bool { return vote.BidID == bidID },
+		func(a, b models.BidDecision) int { return byTimeAndID(a.UpdatedAt, b.UpdatedAt, a.ID, b.ID) }), nil
+}
+
+func (r memoryBids) SaveVote(_ context.Context, vote *models.BidDecision) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, existing := range r.store.data.votes {
+		if existing.BidID == vote.BidID && existing.ResponsibleID == vote.ResponsibleID {
+			vote.ID, vote.CreatedAt = existing.ID, existing.CreatedAt
+			break
+		}
+	}
+	if vote.ID == uuid.Nil {
+		vote.ID = uuid.New()
+	}
+	if vote.CreatedAt.IsZero() {
+		vote.CreatedAt = now
+	}
+	vote.UpdatedAt = now
+	r.store.data.votes[vote.ID] = *vote
+	return nil
+}
+
+func (r memoryBids) CreateFeedback(_ context.Context, feedback *models.BidFeedback) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if feedback.ID == uuid.Nil {
+		feedback.ID = uuid.New()
+	}
+	if feedback.CreatedAt.IsZero() {
+		feedback.CreatedAt = time.Now()
+	}
+	r.store.data.feedback[feedback.ID] = *feedback
+	return nil
+}
+
+func (r memoryBids) ListFeedback(_ context.Context, authorID uuid.UUID, page pagination.Page) (pagination.Result[models.BidFeedback], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	feedback := values(r.store.data.feedback, func(feedback models.BidFeedback) bool {
+		bid, ok := r.store.data.bids[feedback.BidID]
+		return ok && bid.AuthorID == authorID
+	}, nil)
+	return pagination.Slice(feedback, page, feedbackKeys, feedbackValues)
+}
+
+type memoryEvaluations struct {
+	store *memoryStore
+}
+
+func (r memoryEvaluations) Criteria(_ context.Context, tenderID uuid.UUID) ([]models.TenderCriterion, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.criteria,
+		func(criterion models.TenderCriterion) bool { return criterion.TenderID == tenderID },
+		func(a, b models.TenderCriterion) int {
+			if result := a.CreatedAt.Compare(b.CreatedAt); result != 0 {
+				return result
+			}
+			if result := strings.Compare(a.Name, b.Name); result != 0 {
+				return result
+			}
+			return strings.Compare(a.ID.String(), b.ID.String())
+		}), nil
+}
+
+func (r memoryEvaluations) ReplaceCriteria(_ context.Context, tenderID uuid.UUID, criteria []models.TenderCriterion) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	maps.DeleteFunc(r.store.data.criteria, func(_ uuid.UUID, criterion models.TenderCriterion) bool {
+		return criterion.TenderID == tenderID
+	})
+	now := time.Now()
+	for i := range criteria {
+		if criteria[i].ID == uuid.Nil {
+			criteria[i].ID = uuid.New()
+		}
+		if criteria[i].CreatedAt.IsZero() {
+			criteria[i].CreatedAt = now
+		}
+		r.store.data.criteria[criteria[i].ID] = criteria[i]
+	}
+	return nil
+}
+
+func (r memoryEvaluations) SaveScores(_ context.Context, scores []models.BidScore) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for i := range scores {
+		score := &scores[i]
+		for _, existing := range r.store.data.scores {
+			if existing.BidID == score.BidID && existing.CriterionID == score.CriterionID && existing.EvaluatorID == score.EvaluatorID {
+				score.ID, score.CreatedAt = existing.ID, existing.CreatedAt
+				break
+			}
+		}
+		if score.ID == uuid.Nil {
+			score.ID = uuid.New()
+		}
+		if score.CreatedAt.IsZero() {
+			score.CreatedAt = now
+		}
+		score.UpdatedAt = now
+		r.store.data.scores[score.ID] = *score
+	}
+	return nil
+}
+
+func (r memoryEvaluations) Scores(_ context.Context, tenderID uuid.UUID) ([]models.BidScore, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.scores,
+		func(score models.BidScore) bool { return r.store.data.bids[score.BidID].TenderID == tenderID },
+		func(a, b models.BidScore) int { return byTimeAndID(a.CreatedAt, b.CreatedAt, a.ID, b.ID) }), nil
+}
+
+func (r memoryEvaluations) CountScores(_ context.Context, tenderID uuid.UUID) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, score := range r.store.data.scores {
+		if criterion, ok := r.store.data.criteria[score.CriterionID]; ok && criterion.TenderID == tenderID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type memoryEmployees struct {
+	store *memoryStore
+}
+
+func (r memoryEmployees) FindByID(_ context.Context, id uuid.UUID) (models.Employee, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	employee, ok := r.store.data.employees[id]
+	if !ok {
+		return models.Employee{}, ErrNotFound
+	}
+	return employee, nil
+}
+
+func (r memoryEmployees) FindByUsername(_ context.Context, username string) (models.Employee, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, employee := range r.store.data.employees {
+		if employee.Username == username {
+			return employee, nil
+		}
+	}
+	return models.Employee{}, ErrNotFound
+}
+
+func (r memoryEmployees) FindByIDs(_ context.Context, ids []uuid.UUID) ([]models.Employee, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var employees []models.Employee
+	for _, id := range uniqueIDs(ids) {
+		if employee, ok := r.store.data.employees[id]; ok {
+			employees = append(employees, employee)
+		}
+	}
+	return employees, nil
+}
+
+func (r memoryEmployees) List(_ context.Context, filter EmployeeFilter, page pagination.Page) (pagination.Result[models.Employee], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	search := strings.ToLower(filter.Search)
+	employees := values(r.store.data.employees, func(employee models.Employee) bool {
+		if filter.ActiveOnly && !employee.IsActive() {
+			return false
+		}
+		return search == "" ||
+			strings.Contains(strings.ToLower(employee.Username), search) ||
+			strings.Contains(strings.ToLower(employee.FirstName), search) ||
+			strings.Contains(strings.ToLower(employee.LastName), search)
+	}, nil)
+	return pagination.Slice(employees, page, employeeKeys, employeeValues)
+}
+
+func (r memoryEmployees) Create(_ context.Context, employee *models.Employee) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if employee.ID == uuid.Nil {
+		employee.ID = uuid.New()
+	}
+	for _, existing := range r.store.data.employees {
+		if existing.ID == employee.ID || existing.Username == employee.Username {
+			return ErrDuplicate
+		}
+	}
+	if employee.Language == "" {
+		employee.Language = "ru"
+	}
+	now := time.Now()
+	if employee.CreatedAt.IsZero() {
+		employee.CreatedAt = now
+	}
+	if employee.UpdatedAt.IsZero() {
+		employee.UpdatedAt = now
+	}
+	r.store.data.employees[employee.ID] = *employee
+	return nil
+}
+
+func (r memoryEmployees) Save(_ context.Context, employee *models.Employee) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if employee.ID == uuid.Nil {
+		employee.ID = uuid.New()
+	}
+	for _, existing := range r.store.data.employees {
+		if existing.ID != employee.ID && existing.Username == employee.Username {
+			return ErrDuplicate
+		}
+	}
+	r.store.data.employees[employee.ID] = *employee
+	return nil
+}
+
+type memoryOrganizations struct {
+	store *memoryStore
+}
+
+func (r memoryOrganizations) FindByID(_ context.Context, id uuid.UUID) (models.Organization, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	organization, ok := r.store.data.organizations[id]
+	if !ok {
+		return models.Organization{}, ErrNotFound
+	}
+	return organization, nil
+}
+
+func (r memoryOrganizations) FindForUpdate(ctx context.Context, id uuid.UUID) (models.Organization, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r memoryOrganizations) List(_ context.Context, page pagination.Page) (pagination.Result[models.Organization], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return pagination.Slice(values(r.store.data.organizations, nil, nil), page, organizationKeys, organizationValues)
+}
+
+func (r memoryOrganizations) Create(_ context.Context, organization *models.Organization) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if organization.ID == uuid.Nil {
+		organization.ID = uuid.New()
+	}
+	if _, exists := r.store.data.organizations[organization.ID]; exists {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	if organization.CreatedAt.IsZero() {
+		organization.CreatedAt = now
+	}
+	if organization.UpdatedAt.IsZero() {
+		organization.UpdatedAt = now
+	}
+	r.store.data.organizations[organization.ID] = *organization
+	return nil
+}
+
+func (r memoryOrganizations) Save(_ context.Context, organization *models.Organization) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if organization.ID == uuid.Nil {
+		organization.ID = uuid.New()
+	}
+	r.store.data.organizations[organization.ID] = *organization
+	return nil
+}
+
+func (r memoryOrganizations) IsResponsible(_ context.Context, organizationID uuid.UUID, employeeID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.findResponsible(organizationID, employeeID) != uuid.Nil, nil
+}
+
+func (r memoryOrganizations) CountResponsibles(_ context.Context, organizationID uuid.UUID) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, responsible := range r.store.data.responsibles {
+		if responsible.OrganizationID == organizationID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r memoryOrganizations) AddResponsible(_ context.Context, responsible *models.OrganizationResponsible) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.findResponsible(responsible.OrganizationID, responsible.UserID) != uuid.Nil {
+		return ErrDuplicate
+	}
+	if responsible.ID == uuid.Nil {
+		responsible.ID = uuid.New()
+	}
+	r.store.data.responsibles[responsible.ID] = *responsible
+	return nil
+}
+
+func (r memoryOrganizations) RemoveResponsible(_ context.Context, organizationID uuid.UUID, employeeID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := r.store.findResponsible(organizationID, employeeID)
+	if id == uuid.Nil {
+		return ErrNotFound
+	}
+	delete(r.store.data.responsibles, id)
+	return nil
+}
+
+func (r memoryOrganizations) ResponsibleOrganizations(_ context.Context, employeeID uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var ids []uuid.UUID
+	for _, responsible := range r.store.data.responsibles {
+		if responsible.UserID == employeeID {
+			ids = append(ids, responsible.OrganizationID)
+		}
+	}
+	return ids, nil
+}
+
+func (r memoryOrganizations) Responsibles(_ context.Context, organizationID uuid.UUID) ([]models.Employee, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var employees []models.Employee
+	for _, responsible := range r.store.data.responsibles {
+		if employee, ok := r.store.data.employees[responsible.UserID]; ok && responsible.OrganizationID == organizationID {
+			employees = append(employees, employee)
+		}
+	}
+	slices.SortFunc(employees, func(a, b models.Employee) int {
+		return strings.Compare(a.Username, b.Username)
+	})
+	return employees, nil
+}
+
+// findResponsible возвращает ID записи об ответственном или uuid.Nil.
+// Вызывается под блокировкой хранилища.
+func (s *memoryStore) findResponsible(organizationID uuid.UUID, employeeID uuid.UUID) uuid.UUID {
+	for id, responsible := range s.data.responsibles {
+		if responsible.OrganizationID == organizationID && responsible.UserID == employeeID {
+			return id
+		}
+	}
+	return uuid.Nil
+}
+
+type memoryIdempotency struct {
+	store *memoryStore
+}
+
+func (r memoryIdempotency) Reserve(_ context.Context, key *models.IdempotencyKey) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := idempotencyKey{key.EmployeeID, key.Key}
+	if _, exists := r.store.data.idempotency[id]; exists {
+		return false, nil
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	r.store.data.idempotency[id] = *key
+	return true, nil
+}
+
+func (r memoryIdempotency) Find(_ context.Context, employeeID uuid.UUID, key string) (models.IdempotencyKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	record, ok := r.store.data.idempotency[idempotencyKey{employeeID, key}]
+	if !ok {
+		return models.IdempotencyKey{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (r memoryIdempotency) Complete(_ context.Context, employeeID uuid.UUID, key string, status int, contentType string, body []byte, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := idempotencyKey{employeeID, key}
+	record, ok := r.store.data.idempotency[id]
+	if !ok {
+		return ErrNotFound
+	}
+	record.Status = status
+	record.ContentType = contentType
+	record.Body = slices.Clone(body)
+	record.ExpiresAt = expiresAt
+	r.store.data.idempotency[id] = record
+	return nil
+}
+
+func (r memoryIdempotency) Delete(_ context.Context, employeeID uuid.UUID, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.idempotency, idempotencyKey{employeeID, key})
+	return nil
+}
+
+func (r memoryIdempotency) Purge(_ context.Context, employeeID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	maps.DeleteFunc(r.store.data.idempotency, func(id idempotencyKey, record models.IdempotencyKey) bool {
+		return id.employeeID == employeeID && record.ExpiresAt.Before(now)
+	})
+	return nil
+}
+
+// memoryLocks всегда выдаёт блокировку: транзакции в памяти и так
+// выполняются по одной.
+type memoryLocks struct{}
+
+func (memoryLocks) TryLock(context.Context, int64) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,513 @@
+package repository
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryAttachments struct {
+	store *memoryStore
+}
+
+func (r memoryAttachments) Find(_ context.Context, entityType string, entityID uuid.UUID, id uuid.UUID) (models.Attachment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	attachment, ok := r.store.data.attachments[id]
+	if !ok || attachment.EntityType != entityType || attachment.EntityID != entityID {
+		return models.Attachment{}, ErrNotFound
+	}
+	return attachment, nil
+}
+
+func (r memoryAttachments) ListByEntity(_ context.Context, entityType string, entityID uuid.UUID) ([]models.Attachment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.attachments,
+		func(attachment models.Attachment) bool {
+			return attachment.EntityType == entityType && attachment.EntityID == entityID
+		},
+		func(a, b models.Attachment) int { return byTimeAndID(a.CreatedAt, b.CreatedAt, a.ID, b.ID) }), nil
+}
+
+func (r memoryAttachments) Create(_ context.Context, attachment *models.Attachment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if attachment.ID == uuid.Nil {
+		attachment.ID = uuid.New()
+	}
+	if _, exists := r.store.data.attachments[attachment.ID]; exists {
+		return ErrDuplicate
+	}
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+	r.store.data.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (r memoryAttachments) Delete(_ context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.attachments[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.store.data.attachments, id)
+	return nil
+}
+
+type memoryWebhooks struct {
+	store *memoryStore
+}
+
+func (r memoryWebhooks) Create(_ context.Context, webhook *models.Webhook) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	if _, exists := r.store.data.webhooks[webhook.ID]; exists {
+		return ErrDuplicate
+	}
+	if webhook.CreatedAt.IsZero() {
+		webhook.CreatedAt = time.Now()
+	}
+	r.store.data.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r memoryWebhooks) ListByOrganization(_ context.Context, organizationID uuid.UUID) ([]models.Webhook, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.webhooks,
+		func(webhook models.Webhook) bool { return webhook.OrganizationID == organizationID },
+		func(a, b models.Webhook) int { return byTimeAndID(a.CreatedAt, b.CreatedAt, a.ID, b.ID) }), nil
+}
+
+func (r memoryWebhooks) Delete(_ context.Context, organizationID uuid.UUID, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	webhook, ok := r.store.data.webhooks[id]
+	if !ok || webhook.OrganizationID != organizationID {
+		return ErrNotFound
+	}
+	delete(r.store.data.webhooks, id)
+	maps.DeleteFunc(r.store.data.deliveries, func(_ uuid.UUID, delivery models.WebhookDelivery) bool {
+		return delivery.WebhookID == id
+	})
+	return nil
+}
+
+// deadDelivery сообщает, является ли delivery мёртвой доставкой вебхука
+// организации. Вызывается под блокировкой хранилища.
+func (r memoryWebhooks) deadDelivery(organizationID uuid.UUID, delivery models.WebhookDelivery) bool {
+	webhook, ok := r.store.data.webhooks[delivery.WebhookID]
+	return ok && webhook.OrganizationID == organizationID && delivery.DeadAt != nil
+}
+
+func (r memoryWebhooks) DeadLetters(_ context.Context, organizationID uuid.UUID, webhookID *uuid.UUID, page pagination.Page) (pagination.Result[DeadLetter], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var letters []DeadLetter
+	for _, delivery := range r.store.data.deliveries {
+		if !r.deadDelivery(organizationID, delivery) || webhookID != nil && delivery.WebhookID != *webhookID {
+			continue
+		}
+		event, ok := r.store.data.outbox[delivery.EventID]
+		if !ok {
+			continue
+		}
+		letters = append(letters, DeadLetter{WebhookDelivery: delivery, EventType: event.EventType, Payload: event.Payload})
+	}
+	return pagination.Slice(letters, page, deadLetterKeys, deadLetterValues)
+}
+
+func (r memoryWebhooks) Enqueue(_ context.Context, event *models.OutboxEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.store.data.outbox[event.ID] = *event
+	return nil
+}
+
+func (r memoryWebhooks) Dispatch(_ context.Context, limit int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	events := values(r.store.data.outbox,
+		func(event models.OutboxEvent) bool { return event.DispatchedAt == nil },
+		func(a, b models.OutboxEvent) int { return byTimeAndID(a.CreatedAt, b.CreatedAt, a.ID, b.ID) })
+	now := time.Now()
+	for _, event := range events[:min(len(events), limit)] {
+		for _, hook := range r.store.data.webhooks {
+			if hook.OrganizationID != event.OrganizationID || !slices.Contains(hook.EventTypes, event.EventType) {
+				continue
+			}
+			delivery := models.WebhookDelivery{
+				ID:            uuid.New(),
+				WebhookID:     hook.ID,
+				EventID:       event.ID,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			}
+			r.store.data.deliveries[delivery.ID] = delivery
+		}
+		event.DispatchedAt = &now
+		r.store.data.outbox[event.ID] = event
+	}
+	return nil
+}
+
+func (r memoryWebhooks) Lease(_ context.Context, now time.Time, until time.Time, limit int) ([]PendingDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deliveries := values(r.store.data.deliveries,
+		func(delivery models.WebhookDelivery) bool {
+			return delivery.DeliveredAt == nil && delivery.DeadAt == nil && !delivery.NextAttemptAt.After(now)
+		},
+		func(a, b models.WebhookDelivery) int { return a.NextAttemptAt.Compare(b.NextAttemptAt) })
+
+	var pending []PendingDelivery
+	for _, delivery := range deliveries[:min(len(deliveries), limit)] {
+		delivery.NextAttemptAt = until
+		r.store.data.deliveries[delivery.ID] = delivery
+
+		hook, hookOK := r.store.data.webhooks[delivery.WebhookID]
+		event, eventOK := r.store.data.outbox[delivery.EventID]
+		if !hookOK || !eventOK {
+			continue
+		}
+		pending = append(pending, PendingDelivery{
+			WebhookDelivery: delivery,
+			URL:             hook.URL,
+			Secret:          hook.Secret,
+			EventType:       event.EventType,
+			Payload:         event.Payload,
+		})
+	}
+	return pending, nil
+}
+
+func (r memoryWebhooks) Record(_ context.Context, deliveryID uuid.UUID, attempt Attempt) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delivery, ok := r.store.data.deliveries[deliveryID]
+	if !ok {
+		return nil
+	}
+	delivery.Attempts = attempt.Attempts
+	delivery.LastStatus = attempt.Status
+	delivery.LastError = attempt.Error
+	switch {
+	case attempt.Done:
+		delivery.DeliveredAt = &attempt.At
+	case attempt.Dead:
+		delivery.DeadAt = &attempt.At
+	default:
+		delivery.NextAttemptAt = attempt.NextAttemptAt
+	}
+	r.store.data.deliveries[deliveryID] = delivery
+	return nil
+}
+
+func (r memoryWebhooks) Retry(_ context.Context, organizationID uuid.UUID, deliveryID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delivery, ok := r.store.data.deliveries[deliveryID]
+	if !ok || !r.deadDelivery(organizationID, delivery) {
+		return ErrNotFound
+	}
+	delivery.Attempts = 0
+	delivery.DeadAt = nil
+	delivery.NextAttemptAt = time.Now()
+	r.store.data.deliveries[deliveryID] = delivery
+	return nil
+}
+
+type memoryNotifications struct {
+	store *memoryStore
+}
+
+func (r memoryNotifications) List(_ context.Context, employeeID uuid.UUID, filter NotificationFilter, page pagination.Page) (pagination.Result[models.Notification], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	notifications := values(r.store.data.notifications, func(notification models.Notification) bool {
+		if notification.EmployeeID != employeeID {
+			return false
+		}
+		if filter.Read != nil && *filter.Read != (notification.ReadAt != nil) {
+			return false
+		}
+		return filter.Type == "" || notification.Type == filter.Type
+	}, nil)
+	return pagination.Slice(notifications, page, notificationKeys, notificationValues)
+}
+
+func (r memoryNotifications) MarkRead(_ context.Context, employeeID uuid.UUID, id uuid.UUID) (models.Notification, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	notification, ok := r.store.data.notifications[id]
+	if !ok || notification.EmployeeID != employeeID {
+		return models.Notification{}, ErrNotFound
+	}
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		r.store.data.notifications[id] = notification
+	}
+	return notification, nil
+}
+
+func (r memoryNotifications) MarkAllRead(_ context.Context, employeeID uuid.UUID) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	var updated int64
+	for id, notification := range r.store.data.notifications {
+		if notification.EmployeeID == employeeID && notification.ReadAt == nil {
+			notification.ReadAt = &now
+			r.store.data.notifications[id] = notification
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r memoryNotifications) Create(_ context.Context, notifications []models.Notification) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for i := range notifications {
+		if notifications[i].ID == uuid.Nil {
+			notifications[i].ID = uuid.New()
+		}
+		if notifications[i].CreatedAt.IsZero() {
+			notifications[i].CreatedAt = now
+		}
+		r.store.data.notifications[notifications[i].ID] = notifications[i]
+	}
+	return nil
+}
+
+func (r memoryNotifications) Preferences(_ context.Context, employeeID uuid.UUID) ([]models.NotificationPreference, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return values(r.store.data.preferences,
+		func(preference models.NotificationPreference) bool { return preference.EmployeeID == employeeID },
+		func(a, b models.NotificationPreference) int { return cmp.Compare(a.Type, b.Type) }), nil
+}
+
+func (r memoryNotifications) SavePreferences(_ context.Context, preferences []models.NotificationPreference) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for i := range preferences {
+		if preferences[i].UpdatedAt.IsZero() {
+			preferences[i].UpdatedAt = now
+		}
+		key := preferenceKey{employeeID: preferences[i].EmployeeID, kind: preferences[i].Type}
+		r.store.data.preferences[key] = preferences[i]
+	}
+	return nil
+}
+
+func (r memoryNotifications) Disabled(_ context.Context, kind string, employeeIDs []uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var disabled []uuid.UUID
+	for _, employeeID := range uniqueIDs(employeeIDs) {
+		preference, ok := r.store.data.preferences[preferenceKey{employeeID: employeeID, kind: kind}]
+		if ok && !preference.Enabled {
+			disabled = append(disabled, employeeID)
+		}
+	}
+	return disabled, nil
+}
+
+type memoryEmails struct {
+	store *memoryStore
+}
+
+func (r memoryEmails) Enqueue(_ context.Context, message *models.EmailMessage) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	r.store.data.emails[message.ID] = *message
+	return nil
+}
+
+func (r memoryEmails) Lease(_ context.Context, now time.Time, until time.Time, limit int) ([]models.EmailMessage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	messages := values(r.store.data.emails,
+		func(message models.EmailMessage) bool {
+			return message.SentAt == nil && message.DeadAt == nil && !message.NextAttemptAt.After(now)
+		},
+		func(a, b models.EmailMessage) int { return a.NextAttemptAt.Compare(b.NextAttemptAt) })
+	messages = messages[:min(len(messages), limit)]
+	for i := range messages {
+		messages[i].NextAttemptAt = until
+		r.store.data.emails[messages[i].ID] = messages[i]
+	}
+	return messages, nil
+}
+
+func (r memoryEmails) Record(_ context.Context, messageID uuid.UUID, attempt Attempt) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	message, ok := r.store.data.emails[messageID]
+	if !ok {
+		return nil
+	}
+	message.Attempts = attempt.Attempts
+	message.LastError = attempt.Error
+	switch {
+	case attempt.Done:
+		message.SentAt = &attempt.At
+	case attempt.Dead:
+		message.DeadAt = &attempt.At
+	default:
+		message.NextAttemptAt = attempt.NextAttemptAt
+	}
+	r.store.data.emails[messageID] = message
+	return nil
+}
+
+type memoryAudit struct {
+	store *memoryStore
+}
+
+func (f AuditFilter) matches(event models.AuditEvent) bool {
+	if f.EntityType != "" && event.EntityType != f.EntityType {
+		return false
+	}
+	if f.EntityID != nil && (event.EntityID == nil || *event.EntityID != *f.EntityID) {
+		return false
+	}
+	if f.ActorID != nil && (event.ActorID == nil || *event.ActorID != *f.ActorID) {
+		return false
+	}
+	if f.ActorUsername != "" && event.ActorUsername != f.ActorUsername {
+		return false
+	}
+	if f.Action != "" && event.Action != f.Action {
+		return false
+	}
+	if f.From != nil && event.CreatedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && event.CreatedAt.After(*f.To) {
+		return false
+	}
+	return f.Before == nil || event.CreatedAt.Before(*f.Before)
+}
+
+func (r memoryAudit) List(_ context.Context, filter AuditFilter, page pagination.Page) (pagination.Result[models.AuditEvent], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return pagination.Slice(values(r.store.data.audit, filter.matches, nil), page, auditKeys, auditValues)
+}
+
+func (r memoryAudit) Create(_ context.Context, events []models.AuditEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range events {
+		if events[i].ID == uuid.Nil {
+			events[i].ID = uuid.New()
+		}
+		if events[i].CreatedAt.IsZero() {
+			events[i].CreatedAt = time.Now()
+		}
+		r.store.data.audit[events[i].ID] = events[i]
+	}
+	return nil
+}
+
+type memoryEvents struct {
+	store *memoryStore
+}
+
+// Publish нумерует события тендера подряд, как gorm-реализация.
+// Подписчиков будить некому: лента в памяти нужна только тестам.
+func (r memoryEvents) Publish(_ context.Context, event *models.TenderEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event.Seq = 1
+	for key := range r.store.data.events {
+		if key.tenderID == event.TenderID {
+			event.Seq = max(event.Seq, key.seq+1)
+		}
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.store.data.events[eventKey{event.TenderID, event.Seq}] = *event
+	return nil
+}
+
+func (r memoryEvents) After(_ context.Context, tenderID uuid.UUID, seq int64, limit int) ([]models.TenderEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := values(r.store.data.events,
+		func(event models.TenderEvent) bool { return event.TenderID == tenderID && event.Seq > seq },
+		func(a, b models.TenderEvent) int { return cmp.Compare(a.Seq, b.Seq) })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r memoryEvents) LastSeq(_ context.Context, tenderID uuid.UUID) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var last int64
+	for _, event := range r.store.data.events {
+		if event.TenderID == tenderID {
+			last = max(last, event.Seq)
+		}
+	}
+	return last, nil
+}
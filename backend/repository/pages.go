@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ключи сортировки списков и значения этих ключей у строки. Обе
+// реализации выдают страницы в одном порядке: gorm сортирует по Expr,
+// память — по значениям.
+var (
+	tenderKeys = []pagination.Key{{Expr: "tender.name"}, {Expr: "tender.id"}}
+	// ts_rank_cd возвращает real, поэтому ранг в курсоре записывается
+	// с точностью float32 и сравнивается без потерь.
+	tenderSearchKeys = []pagination.Key{
+		{Expr: "ts_rank_cd(tender.search_vector, search.query)", Desc: true},
+		{Expr: "tender.name"},
+		{Expr: "tender.id"},
+	}
+
+	// Предложения без цены при сортировке по цене идут в конце; NULL
+	// заменяется значением за пределами numeric(18,2), потому что
+	// keyset-условие не умеет сравнивать с NULL.
+	bidKeys          = []pagination.Key{{Expr: "bid.created_at"}, {Expr: "bid.id"}}
+	bidPriceAscKeys  = []pagination.Key{{Expr: "COALESCE(bid.price, " + bidPriceMissingAsc + ")"}, {Expr: "bid.created_at"}, {Expr: "bid.id"}}
+	bidPriceDescKeys = []pagination.Key{{Expr: "COALESCE(bid.price, " + bidPriceMissingDesc + ")", Desc: true}, {Expr: "bid.created_at"}, {Expr: "bid.id"}}
+
+	feedbackKeys     = []pagination.Key{{Expr: "bid_feedback.created_at"}, {Expr: "bid_feedback.id"}}
+	employeeKeys     = []pagination.Key{{Expr: "username"}, {Expr: "id"}}
+	organizationKeys = []pagination.Key{{Expr: "name"}, {Expr: "id"}}
+	notificationKeys = []pagination.Key{{Expr: "created_at", Desc: true}, {Expr: "id", Desc: true}}
+	auditKeys        = []pagination.Key{{Expr: "created_at", Desc: true}, {Expr: "id", Desc: true}}
+	deadLetterKeys   = []pagination.Key{
+		{Expr: "webhook_delivery.dead_at", Desc: true},
+		{Expr: "webhook_delivery.id", Desc: true},
+	}
+)
+
+const (
+	bidPriceMissingAsc  = "1e18"
+	bidPriceMissingDesc = "-1"
+)
+
+func tenderValues(tender models.Tender) []any {
+	return []any{tender.Name, tender.ID}
+}
+
+func tenderMatchValues(match TenderMatch) []any {
+	return []any{float32(match.SearchRank), match.Name, match.ID}
+}
+
+// bidOrderKeys возвращает ключи порядка order и значения этих ключей.
+func bidOrderKeys(order BidOrder) ([]pagination.Key, func(models.Bid) []any) {
+	switch order {
+	case BidOrderPriceAsc:
+		return bidPriceAscKeys, bidPriceValues(bidPriceMissingAsc)
+	case BidOrderPriceDesc:
+		return bidPriceDescKeys, bidPriceValues(bidPriceMissingDesc)
+	default:
+		return bidKeys, bidValues
+	}
+}
+
+func bidValues(bid models.Bid) []any {
+	return []any{bid.CreatedAt, bid.ID}
+}
+
+func bidPriceValues(missing string) func(models.Bid) []any {
+	missingPrice := decimal.RequireFromString(missing)
+	return func(bid models.Bid) []any {
+		price := missingPrice
+		if bid.Price != nil {
+			price = *bid.Price
+		}
+		return []any{price, bid.CreatedAt, bid.ID}
+	}
+}
+
+func feedbackValues(feedback models.BidFeedback) []any {
+	return []any{feedback.CreatedAt, feedback.ID}
+}
+
+func employeeValues(employee models.Employee) []any {
+	return []any{employee.Username, employee.ID}
+}
+
+func organizationValues(organization models.Organization) []any {
+	return []any{organization.Name, organization.ID}
+}
+
+func notificationValues(notification models.Notification) []any {
+	return []any{notification.CreatedAt, notification.ID}
+}
+
+func auditValues(event models.AuditEvent) []any {
+	return []any{event.CreatedAt, event.ID}
+}
+
+func deadLetterValues(letter DeadLetter) []any {
+	return []any{*letter.DeadAt, letter.ID}
+}
@@ -0,0 +1,400 @@
+// Package repository отделяет хранение данных сервиса от обработчиков.
+//
+// NewGorm хранит данные в Postgres, NewMemory — в памяти процесса; вторая
+// реализация нужна, чтобы проверять правила без базы. Обе возвращают
+// ErrNotFound, если записи нет, и ErrDuplicate при нарушении уникальности.
+// Списки выдаются постранично (pagination.Page) в одинаковом порядке.
+package repository
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound  = errors.New("record not found")
+	ErrDuplicate = errors.New("record already exists")
+)
+
+// Visibility ограничивает выборку тем, что видно сотруднику EmployeeID,
+// ответственному за Organizations. uuid.Nil в EmployeeID — анонимный
+// запрос.
+type Visibility struct {
+	EmployeeID    uuid.UUID
+	Organizations []uuid.UUID
+}
+
+// TenderFilter — условия выборки тендеров. Пустые поля не ограничивают
+// выборку.
+type TenderFilter struct {
+	// Visible оставляет опубликованные тендеры и тендеры организаций
+	// Visible.Organizations.
+	Visible         *Visibility
+	ServiceTypes    []string
+	Statuses        []string
+	OrganizationID  *uuid.UUID
+	CreatorUsername string
+	// CreatedFrom и CreatedTo включают границы, CreatedBefore — нет.
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	CreatedBefore *time.Time
+}
+
+// TenderMatch — тендер, найденный полнотекстовым поиском, с рангом и
+// фрагментами, в которых найденные слова обёрнуты в <b>.
+type TenderMatch struct {
+	models.Tender
+	SearchRank           float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+type TenderRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (models.Tender, error)
+	// FindForUpdate загружает тендер и блокирует его до конца транзакции.
+	FindForUpdate(ctx context.Context, id uuid.UUID) (models.Tender, error)
+	// List возвращает тендеры по имени.
+	List(ctx context.Context, filter TenderFilter, page pagination.Page) (pagination.Result[models.Tender], error)
+	// Search возвращает тендеры, в названии или описании которых есть
+	// query, сначала самые релевантные.
+	Search(ctx context.Context, query string, filter TenderFilter, page pagination.Page) (pagination.Result[TenderMatch], error)
+	Create(ctx context.Context, tender *models.Tender) error
+	Save(ctx context.Context, tender *models.Tender) error
+
+	// ListHistory возвращает сохранённые версии тендера по возрастанию.
+	ListHistory(ctx context.Context, tenderID uuid.UUID) ([]models.TenderHistory, error)
+	FindHistory(ctx context.Context, tenderID uuid.UUID, version int) (models.TenderHistory, error)
+	CreateHistory(ctx context.Context, history *models.TenderHistory) error
+
+	// Owners возвращает владельцев тендера по имени пользователя.
+	Owners(ctx context.Context, tenderID uuid.UUID) ([]models.Employee, error)
+	// SetOwners заменяет список владельцев тендера.
+	SetOwners(ctx context.Context, tenderID uuid.UUID, employeeIDs []uuid.UUID) error
+	// RemoveOwner убирает сотрудника из владельцев тендеров организации
+	// organizationID, а если он nil, — из владельцев всех тендеров.
+	RemoveOwner(ctx context.Context, employeeID uuid.UUID, organizationID *uuid.UUID) error
+
+	// Due возвращает тендеры, которые к now должен обработать планировщик
+	// по правилу due, в порядке создания и блокирует их до конца
+	// транзакции.
+	Due(ctx context.Context, due Due, now time.Time) ([]models.Tender, error)
+}
+
+// Due — правило, по которому планировщик выбирает тендеры.
+type Due int
+
+const (
+	// DuePublish — черновики, у которых наступило publishAt.
+	DuePublish Due = iota
+	// DueClose — черновики и опубликованные тендеры с истёкшим сроком
+	// подачи предложений.
+	DueClose
+	// DueUnseal — ещё не вскрытые запечатанные тендеры, которые закрыты
+	// или у которых истёк срок подачи.
+	DueUnseal
+)
+
+// BidFilter — условия выборки предложений. Пустые поля не ограничивают
+// выборку.
+type BidFilter struct {
+	// Visible оставляет предложения сотрудника Visible.EmployeeID и
+	// предложения на тендеры организаций Visible.Organizations.
+	Visible  *Visibility
+	TenderID *uuid.UUID
+	AuthorID *uuid.UUID
+}
+
+// BidOrder — порядок выдачи предложений.
+type BidOrder string
+
+const (
+	// BidOrderCreated — по времени подачи.
+	BidOrderCreated BidOrder = ""
+	// BidOrderPriceAsc и BidOrderPriceDesc — по цене; предложения без цены
+	// в обоих случаях идут в конце.
+	BidOrderPriceAsc  BidOrder = "price_asc"
+	BidOrderPriceDesc BidOrder = "price_desc"
+)
+
+type BidRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (models.Bid, error)
+	// FindForUpdate загружает предложение и блокирует его до конца
+	// транзакции.
+	FindForUpdate(ctx context.Context, id uuid.UUID) (models.Bid, error)
+	List(ctx context.Context, filter BidFilter, order BidOrder, page pagination.Page) (pagination.Result[models.Bid], error)
+	Count(ctx context.Context, filter BidFilter) (int64, error)
+	// ListByTender возвращает предложения тендера в порядке подачи.
+	ListByTender(ctx context.Context, tenderID uuid.UUID) ([]models.Bid, error)
+	Create(ctx context.Context, bid *models.Bid) error
+	Save(ctx context.Context, bid *models.Bid) error
+
+	// ListHistory возвращает сохранённые версии предложения по возрастанию.
+	ListHistory(ctx context.Context, bidID uuid.UUID) ([]models.BidHistory, error)
+	FindHistory(ctx context.Context, bidID uuid.UUID, version int) (models.BidHistory, error)
+	CreateHistory(ctx context.Context, history *models.BidHistory) error
+
+	// Winner возвращает выбранное предложение тендера или ErrNotFound.
+	Winner(ctx context.Context, tenderID uuid.UUID) (models.Bid, error)
+
+	// Votes возвращает голоса по предложению в порядке их изменения.
+	Votes(ctx context.Context, bidID uuid.UUID) ([]models.BidDecision, error)
+	// SaveVote сохраняет голос; повторный голос того же ответственного
+	// заменяет прежний.
+	SaveVote(ctx context.Context, vote *models.BidDecision) error
+	CreateFeedback(ctx context.Context, feedback *models.BidFeedback) error
+	// ListFeedback возвращает отзывы на предложения автора authorID в
+	// порядке создания.
+	ListFeedback(ctx context.Context, authorID uuid.UUID, page pagination.Page) (pagination.Result[models.BidFeedback], error)
+}
+
+type EvaluationRepository interface {
+	// Criteria возвращает критерии тендера в порядке создания.
+	Criteria(ctx context.Context, tenderID uuid.UUID) ([]models.TenderCriterion, error)
+	// ReplaceCriteria заменяет критерии тендера на criteria.
+	ReplaceCriteria(ctx context.Context, tenderID uuid.UUID, criteria []models.TenderCriterion) error
+	// SaveScores сохраняет оценки; оценка того же оценщика по тому же
+	// критерию заменяет прежнюю.
+	SaveScores(ctx context.Context, scores []models.BidScore) error
+	// Scores возвращает все оценки предложений тендера.
+	Scores(ctx context.Context, tenderID uuid.UUID) ([]models.BidScore, error)
+	CountScores(ctx context.Context, tenderID uuid.UUID) (int64, error)
+}
+
+// EmployeeFilter — условия выборки сотрудников.
+type EmployeeFilter struct {
+	// Search ищет без учёта регистра по имени пользователя, имени и
+	// фамилии.
+	Search     string
+	ActiveOnly bool
+}
+
+type EmployeeRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (models.Employee, error)
+	FindByUsername(ctx context.Context, username string) (models.Employee, error)
+	// FindByIDs возвращает найденных сотрудников из ids в любом порядке.
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Employee, error)
+	// List возвращает сотрудников по имени пользователя.
+	List(ctx context.Context, filter EmployeeFilter, page pagination.Page) (pagination.Result[models.Employee], error)
+	// Create возвращает ErrDuplicate, если имя пользователя занято.
+	Create(ctx context.Context, employee *models.Employee) error
+	Save(ctx context.Context, employee *models.Employee) error
+}
+
+type OrganizationRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (models.Organization, error)
+	// FindForUpdate загружает организацию и блокирует её до конца
+	// транзакции: так сериализуются изменения состава ответственных.
+	FindForUpdate(ctx context.Context, id uuid.UUID) (models.Organization, error)
+	// List возвращает организации по названию.
+	List(ctx context.Context, page pagination.Page) (pagination.Result[models.Organization], error)
+	Create(ctx context.Context, organization *models.Organization) error
+	Save(ctx context.Context, organization *models.Organization) error
+
+	IsResponsible(ctx context.Context, organizationID uuid.UUID, employeeID uuid.UUID) (bool, error)
+	CountResponsibles(ctx context.Context, organizationID uuid.UUID) (int64, error)
+	// AddResponsible возвращает ErrDuplicate, если сотрудник уже отвечает
+	// за организацию.
+	AddResponsible(ctx context.Context, responsible *models.OrganizationResponsible) error
+	// RemoveResponsible возвращает ErrNotFound, если сотрудник не отвечает
+	// за организацию.
+	RemoveResponsible(ctx context.Context, organizationID uuid.UUID, employeeID uuid.UUID) error
+	// ResponsibleOrganizations возвращает организации, за которые отвечает
+	// сотрудник.
+	ResponsibleOrganizations(ctx context.Context, employeeID uuid.UUID) ([]uuid.UUID, error)
+	// Responsibles возвращает ответственных за организацию по имени
+	// пользователя, включая деактивированных.
+	Responsibles(ctx context.Context, organizationID uuid.UUID) ([]models.Employee, error)
+}
+
+type AttachmentRepository interface {
+	// Find возвращает вложение id сущности entityType/entityID.
+	Find(ctx context.Context, entityType string, entityID uuid.UUID, id uuid.UUID) (models.Attachment, error)
+	// ListByEntity возвращает вложения сущности в порядке загрузки.
+	ListByEntity(ctx context.Context, entityType string, entityID uuid.UUID) ([]models.Attachment, error)
+	Create(ctx context.Context, attachment *models.Attachment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// DeadLetter — доставка вебхука, исчерпавшая попытки, вместе с событием.
+type DeadLetter struct {
+	models.WebhookDelivery
+	EventType string
+	Payload   []byte
+}
+
+// PendingDelivery — доставка вебхука, взятая в работу, вместе с адресом,
+// секретом вебхука и событием.
+type PendingDelivery struct {
+	models.WebhookDelivery
+	URL       string
+	Secret    string
+	EventType string
+	Payload   []byte
+}
+
+// Attempt — результат попытки отправить письмо или доставить вебхук.
+type Attempt struct {
+	// Attempts — число попыток вместе с этой.
+	Attempts int
+	At       time.Time
+	// Status — код ответа получателя или 0.
+	Status int
+	// Error — ошибка попытки, пустая при успехе.
+	Error string
+	// Done — попытка удалась; Dead — попытки исчерпаны. Иначе следующая
+	// попытка будет в NextAttemptAt.
+	Done          bool
+	Dead          bool
+	NextAttemptAt time.Time
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	// ListByOrganization возвращает вебхуки организации в порядке создания.
+	ListByOrganization(ctx context.Context, organizationID uuid.UUID) ([]models.Webhook, error)
+	// Delete удаляет вебхук организации вместе с его доставками.
+	// Возвращает ErrNotFound, если у организации нет такого вебхука.
+	Delete(ctx context.Context, organizationID uuid.UUID, id uuid.UUID) error
+	// DeadLetters возвращает мёртвые доставки вебхуков организации,
+	// последние первыми. webhookID сужает выборку до одного вебхука.
+	DeadLetters(ctx context.Context, organizationID uuid.UUID, webhookID *uuid.UUID, page pagination.Page) (pagination.Result[DeadLetter], error)
+	// Enqueue записывает событие в outbox для доставки на вебхуки.
+	Enqueue(ctx context.Context, event *models.OutboxEvent) error
+	// Retry возвращает мёртвую доставку в очередь с обнулённым счётчиком
+	// попыток. Возвращает ErrNotFound, если у вебхуков организации нет
+	// такой мёртвой доставки.
+	Retry(ctx context.Context, organizationID uuid.UUID, deliveryID uuid.UUID) error
+	// Dispatch раскладывает до limit ещё не разобранных событий outbox по
+	// подходящим вебхукам организации, создавая доставки.
+	Dispatch(ctx context.Context, limit int) error
+	// Lease забирает до limit доставок, срок которых наступил к now, и
+	// откладывает их до until, чтобы их не взяла другая реплика. У
+	// возвращённых доставок NextAttemptAt уже равен until.
+	Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]PendingDelivery, error)
+	// Record сохраняет результат попытки доставки.
+	Record(ctx context.Context, deliveryID uuid.UUID, attempt Attempt) error
+}
+
+// NotificationFilter — условия выборки уведомлений.
+type NotificationFilter struct {
+	// Read — true только прочитанные, false только непрочитанные.
+	Read *bool
+	Type string
+}
+
+type NotificationRepository interface {
+	// List возвращает уведомления сотрудника, новые первыми.
+	List(ctx context.Context, employeeID uuid.UUID, filter NotificationFilter, page pagination.Page) (pagination.Result[models.Notification], error)
+	// MarkRead отмечает уведомление сотрудника прочитанным и возвращает
+	// его. Время прочтения не меняется при повторной отметке.
+	MarkRead(ctx context.Context, employeeID uuid.UUID, id uuid.UUID) (models.Notification, error)
+	// MarkAllRead отмечает прочитанными все уведомления сотрудника и
+	// возвращает число отмеченных.
+	MarkAllRead(ctx context.Context, employeeID uuid.UUID) (int64, error)
+	Create(ctx context.Context, notifications []models.Notification) error
+
+	Preferences(ctx context.Context, employeeID uuid.UUID) ([]models.NotificationPreference, error)
+	// SavePreferences создаёт или заменяет настройки.
+	SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error
+	// Disabled возвращает тех из employeeIDs, у кого отключены уведомления
+	// типа kind.
+	Disabled(ctx context.Context, kind string, employeeIDs []uuid.UUID) ([]uuid.UUID, error)
+}
+
+type EmailRepository interface {
+	// Enqueue ставит письмо в очередь отправки.
+	Enqueue(ctx context.Context, message *models.EmailMessage) error
+	// Lease забирает до limit писем, срок отправки которых наступил к
+	// now, и откладывает их до until, чтобы их не взяла другая реплика.
+	// У возвращённых писем NextAttemptAt уже равен until.
+	Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]models.EmailMessage, error)
+	// Record сохраняет результат попытки отправки.
+	Record(ctx context.Context, messageID uuid.UUID, attempt Attempt) error
+}
+
+// AuditFilter — условия выборки журнала аудита. Пустые поля не
+// ограничивают выборку.
+type AuditFilter struct {
+	EntityType    string
+	EntityID      *uuid.UUID
+	ActorID       *uuid.UUID
+	ActorUsername string
+	Action        string
+	// From и To включают границы, Before — нет.
+	From   *time.Time
+	To     *time.Time
+	Before *time.Time
+}
+
+type AuditRepository interface {
+	// List возвращает события журнала, новые первыми.
+	List(ctx context.Context, filter AuditFilter, page pagination.Page) (pagination.Result[models.AuditEvent], error)
+	Create(ctx context.Context, events []models.AuditEvent) error
+}
+
+// EventChannel — канал LISTEN/NOTIFY, в котором Postgres-реализация
+// сообщает о новых событиях тендера; полезная нагрузка — ID тендера.
+const EventChannel = "tender_events"
+
+type EventRepository interface {
+	// Publish сохраняет событие тендера, присваивая ему следующий номер
+	// Seq внутри тендера, и будит подписчиков ленты после фиксации
+	// транзакции. Вызывается под блокировкой тендера (FindForUpdate):
+	// тогда номера идут подряд и фиксируются в порядке возрастания.
+	Publish(ctx context.Context, event *models.TenderEvent) error
+	// After возвращает не больше limit событий тендера с номером больше
+	// seq по возрастанию номера.
+	After(ctx context.Context, tenderID uuid.UUID, seq int64, limit int) ([]models.TenderEvent, error)
+	// LastSeq возвращает номер последнего события тендера или 0.
+	LastSeq(ctx context.Context, tenderID uuid.UUID) (int64, error)
+}
+
+type IdempotencyRepository interface {
+	// Reserve сохраняет ключ, если у сотрудника такого ещё нет, и
+	// сообщает, сохранён ли он.
+	Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	Find(ctx context.Context, employeeID uuid.UUID, key string) (models.IdempotencyKey, error)
+	// Complete сохраняет ответ на запрос с ключом и продлевает срок
+	// хранения до expiresAt.
+	Complete(ctx context.Context, employeeID uuid.UUID, key string, status int, contentType string, body []byte, expiresAt time.Time) error
+	Delete(ctx context.Context, employeeID uuid.UUID, key string) error
+	// Purge удаляет ключи сотрудника, срок которых истёк к now.
+	Purge(ctx context.Context, employeeID uuid.UUID, now time.Time) error
+}
+
+type LockRepository interface {
+	// TryLock берёт блокировку key до конца транзакции, если её не держит
+	// другая транзакция, и сообщает, получена ли она. Вызывается внутри
+	// Transaction.
+	TryLock(ctx context.Context, key int64) (bool, error)
+}
+
+// Repositories — набор репозиториев одного хранилища.
+type Repositories struct {
+	Tenders       TenderRepository
+	Bids          BidRepository
+	Evaluations   EvaluationRepository
+	Employees     EmployeeRepository
+	Organizations OrganizationRepository
+	Attachments   AttachmentRepository
+	Webhooks      WebhookRepository
+	Notifications NotificationRepository
+	Emails        EmailRepository
+	Audit         AuditRepository
+	Events        EventRepository
+	Idempotency   IdempotencyRepository
+	Locks         LockRepository
+
+	transaction func(ctx context.Context, fn func(Repositories) error) error
+}
+
+// Transaction выполняет fn с репозиториями, изменения которых
+// сохраняются, только если fn вернула nil.
+func (r Repositories) Transaction(ctx context.Context, fn func(Repositories) error) error {
+	return r.transaction(ctx, fn)
+}
@@ -0,0 +1,1055 @@
+package repository
+
+import (
+	"ZADANIE-6105/migrations"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Общие тесты обеих реализаций. Postgres-реализация проверяется, если
+// TEST_POSTGRES_CONN указывает на пустую базу: каждый тест выполняется
+// в транзакции, которая затем откатывается.
+
+type seedFunc func(t *testing.T, records ...any)
+
+func forEachImplementation(t *testing.T, test func(t *testing.T, repositories Repositories, seed seedFunc)) {
+	t.Run("memory", func(t *testing.T) {
+		store := &memoryStore{data: newMemoryData()}
+		test(t, store.repositories(store.transaction), store.seed)
+	})
+
+	t.Run("gorm", func(t *testing.T) {
+		tx := testDB(t).Begin()
+		t.Cleanup(func() { tx.Rollback() })
+
+		test(t, NewGorm(tx), func(t *testing.T, records ...any) {
+			t.Helper()
+			for _, record := range records {
+				if err := tx.Create(record).Error; err != nil {
+					t.Fatalf("seed %T: %v", record, err)
+				}
+			}
+		})
+	})
+}
+
+var (
+	testDBOnce sync.Once
+	testDBConn *gorm.DB
+	testDBErr  error
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	conn := os.Getenv("TEST_POSTGRES_CONN")
+	if conn == "" {
+		t.Skip("TEST_POSTGRES_CONN is not set")
+	}
+
+	testDBOnce.Do(func() {
+		migrations.RunMigrations(conn)
+		testDBConn, testDBErr = gorm.Open(postgres.Open(conn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	})
+	if testDBErr != nil {
+		t.Fatalf("connect: %v", testDBErr)
+	}
+	return testDBConn
+}
+
+// seed кладёт записи прямо в таблицы хранилища, как gorm-реализация
+// кладёт их в базу.
+func (s *memoryStore) seed(t *testing.T, records ...any) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		switch record := record.(type) {
+		case *models.Tender:
+			s.data.tenders[record.ID] = *record
+		case *models.TenderHistory:
+			s.data.tenderHistory[record.ID] = *record
+		case *models.Bid:
+			s.data.bids[record.ID] = *record
+		case *models.BidDecision:
+			s.data.votes[record.ID] = *record
+		case *models.BidFeedback:
+			s.data.feedback[record.ID] = *record
+		case *models.Employee:
+			s.data.employees[record.ID] = *record
+		case *models.Organization:
+			s.data.organizations[record.ID] = *record
+		case *models.OrganizationResponsible:
+			s.data.responsibles[record.ID] = *record
+		case *models.Webhook:
+			s.data.webhooks[record.ID] = *record
+		case *models.OutboxEvent:
+			s.data.outbox[record.ID] = *record
+		case *models.WebhookDelivery:
+			s.data.deliveries[record.ID] = *record
+		case *models.Notification:
+			s.data.notifications[record.ID] = *record
+		case *models.AuditEvent:
+			s.data.audit[record.ID] = *record
+		case *models.TenderEvent:
+			s.data.events[eventKey{record.TenderID, record.Seq}] = *record
+		default:
+			t.Fatalf("seed: unsupported record %T", record)
+		}
+	}
+}
+
+var ctx = context.Background()
+
+// at возвращает момент через minutes минут после общей точки отсчёта.
+func at(minutes int) time.Time {
+	return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(minutes) * time.Minute)
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
+
+// page читает параметры страницы из строки запроса, как обработчик.
+func page(t *testing.T, query string) pagination.Page {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	result, ok := pagination.FromQuery(c)
+	if !ok {
+		t.Fatalf("invalid page query %q", query)
+	}
+	return result
+}
+
+// nextCursor возвращает курсор следующей страницы или "".
+func nextCursor[T any](result pagination.Result[T]) string {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	pagination.Respond(c, result)
+	return recorder.Header().Get(pagination.NextCursorHeader)
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func expectError(t *testing.T, err error, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("error = %v, want %v", err, want)
+	}
+}
+
+func expectNames[T any](t *testing.T, items []T, name func(T) string, want ...string) {
+	t.Helper()
+	got := make([]string, 0, len(items))
+	for _, item := range items {
+		got = append(got, name(item))
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// failing выполняет fn в отдельной транзакции: ошибка базы прерывает
+// транзакцию Postgres, а вложенная транзакция откатывается до точки
+// сохранения.
+func failing(repositories Repositories, fn func(Repositories) error) error {
+	return repositories.Transaction(ctx, fn)
+}
+
+type fixture struct {
+	orgA, orgB        models.Organization
+	alice, bob, carol models.Employee
+	responsibleForA   models.OrganizationResponsible
+	bridge, asphalt   models.Tender
+	canteen, depot    models.Tender
+}
+
+func newFixture() fixture {
+	f := fixture{
+		orgA:  models.Organization{ID: uuid.New(), Name: "Alpha", Type: "LLC", CreatedAt: at(0), UpdatedAt: at(0)},
+		orgB:  models.Organization{ID: uuid.New(), Name: "Beta", Type: "JSC", CreatedAt: at(0), UpdatedAt: at(0)},
+		alice: models.Employee{ID: uuid.New(), Username: "alice", FirstName: "Alice", Language: "ru", CreatedAt: at(0), UpdatedAt: at(0)},
+		bob:   models.Employee{ID: uuid.New(), Username: "bob", FirstName: "Bob", Language: "ru", CreatedAt: at(0), UpdatedAt: at(0)},
+		carol: models.Employee{ID: uuid.New(), Username: "carol", FirstName: "Carol", Language: "en", CreatedAt: at(0), UpdatedAt: at(0), DeactivatedAt: pointer(at(0))},
+	}
+	f.responsibleForA = models.OrganizationResponsible{ID: uuid.New(), OrganizationID: f.orgA.ID, UserID: f.alice.ID}
+
+	tender := func(name string, description string, organization models.Organization, status string, serviceType string, creator string, minutes int) models.Tender {
+		return models.Tender{
+			ID:              uuid.New(),
+			Name:            name,
+			Description:     description,
+			ServiceType:     serviceType,
+			Status:          status,
+			OrganizationID:  organization.ID,
+			CreatorUsername: creator,
+			Version:         1,
+			CreatedAt:       at(minutes),
+		}
+	}
+	f.bridge = tender("Bridge", "River crossing", f.orgA, models.TenderStatusCreated, "Construction", "alice", 1)
+	f.asphalt = tender("Asphalt paving", "City roads", f.orgB, models.TenderStatusPublished, "Construction", "bob", 2)
+	f.canteen = tender("Canteen supply", "Food delivery and old asphalt removal", f.orgB, models.TenderStatusPublished, "Delivery", "bob", 3)
+	f.depot = tender("Depot", "Warehouse", f.orgB, models.TenderStatusCreated, "Manufacture", "bob", 4)
+	return f
+}
+
+func (f *fixture) seed(t *testing.T, seed seedFunc) {
+	t.Helper()
+	seed(t, &f.orgA, &f.orgB, &f.alice, &f.bob, &f.carol, &f.responsibleForA,
+		&f.bridge, &f.asphalt, &f.canteen, &f.depot)
+}
+
+func tenderName(tender models.Tender) string { return tender.Name }
+func bidName(bid models.Bid) string          { return bid.Name }
+
+func TestTenderList(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		tests := []struct {
+			name   string
+			filter TenderFilter
+			want   []string
+		}{
+			{"all", TenderFilter{}, []string{"Asphalt paving", "Bridge", "Canteen supply", "Depot"}},
+			{"anonymous sees published", TenderFilter{Visible: &Visibility{}}, []string{"Asphalt paving", "Canteen supply"}},
+			{"responsible sees own drafts", TenderFilter{Visible: &Visibility{EmployeeID: f.alice.ID, Organizations: []uuid.UUID{f.orgA.ID}}}, []string{"Asphalt paving", "Bridge", "Canteen supply"}},
+			{"service types", TenderFilter{ServiceTypes: []string{"Delivery", "Manufacture"}}, []string{"Canteen supply", "Depot"}},
+			{"statuses", TenderFilter{Statuses: []string{models.TenderStatusCreated}}, []string{"Bridge", "Depot"}},
+			{"organization", TenderFilter{OrganizationID: &f.orgA.ID}, []string{"Bridge"}},
+			{"creator", TenderFilter{CreatorUsername: "bob"}, []string{"Asphalt paving", "Canteen supply", "Depot"}},
+			{"created range is inclusive", TenderFilter{CreatedFrom: pointer(at(2)), CreatedTo: pointer(at(3))}, []string{"Asphalt paving", "Canteen supply"}},
+			{"created before is exclusive", TenderFilter{CreatedBefore: pointer(at(2))}, []string{"Bridge"}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				result, err := repositories.Tenders.List(ctx, test.filter, page(t, "limit=10"))
+				check(t, err)
+				expectNames(t, result.Items, tenderName, test.want...)
+			})
+		}
+	})
+}
+
+func TestTenderListPages(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		first, err := repositories.Tenders.List(ctx, TenderFilter{}, page(t, "limit=3&total=true"))
+		check(t, err)
+		expectNames(t, first.Items, tenderName, "Asphalt paving", "Bridge", "Canteen supply")
+		if first.Total != 4 {
+			t.Fatalf("total = %d, want 4", first.Total)
+		}
+
+		cursor := nextCursor(first)
+		if cursor == "" {
+			t.Fatal("first page has no next cursor")
+		}
+		second, err := repositories.Tenders.List(ctx, TenderFilter{}, page(t, "limit=3&cursor="+cursor))
+		check(t, err)
+		expectNames(t, second.Items, tenderName, "Depot")
+		if next := nextCursor(second); next != "" {
+			t.Fatalf("last page has next cursor %q", next)
+		}
+
+		_, err = repositories.Tenders.Search(ctx, "asphalt", TenderFilter{}, page(t, "cursor="+cursor))
+		expectError(t, err, pagination.ErrCursorMismatch)
+	})
+}
+
+func TestTenderSearch(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		result, err := repositories.Tenders.Search(ctx, "asphalt", TenderFilter{Visible: &Visibility{}}, page(t, "limit=10"))
+		check(t, err)
+		names := make([]string, 0, len(result.Items))
+		for _, match := range result.Items {
+			names = append(names, match.Name)
+		}
+		if !slices.Equal(names, []string{"Asphalt paving", "Canteen supply"}) {
+			t.Fatalf("matches = %v, want name match before description match", names)
+		}
+		if result.Items[0].SearchRank <= result.Items[1].SearchRank {
+			t.Fatalf("ranks = %v, %v, want name match ranked higher", result.Items[0].SearchRank, result.Items[1].SearchRank)
+		}
+		if !strings.Contains(result.Items[0].NameHighlight, "<b>Asphalt</b>") {
+			t.Fatalf("name highlight = %q", result.Items[0].NameHighlight)
+		}
+
+		result, err = repositories.Tenders.Search(ctx, "asphalt", TenderFilter{ServiceTypes: []string{"Delivery"}}, page(t, ""))
+		check(t, err)
+		if len(result.Items) != 1 || result.Items[0].ID != f.canteen.ID {
+			t.Fatalf("filtered search returned %d matches", len(result.Items))
+		}
+	})
+}
+
+func TestTenderOwners(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		check(t, repositories.Tenders.SetOwners(ctx, f.bridge.ID, []uuid.UUID{f.bob.ID, f.alice.ID, f.bob.ID}))
+		owners, err := repositories.Tenders.Owners(ctx, f.bridge.ID)
+		check(t, err)
+		expectNames(t, owners, func(employee models.Employee) string { return employee.Username }, "alice", "bob")
+
+		check(t, repositories.Tenders.SetOwners(ctx, f.bridge.ID, []uuid.UUID{f.alice.ID}))
+		owners, err = repositories.Tenders.Owners(ctx, f.bridge.ID)
+		check(t, err)
+		expectNames(t, owners, func(employee models.Employee) string { return employee.Username }, "alice")
+
+		check(t, repositories.Tenders.SetOwners(ctx, f.bridge.ID, nil))
+		owners, err = repositories.Tenders.Owners(ctx, f.bridge.ID)
+		check(t, err)
+		if len(owners) != 0 {
+			t.Fatalf("owners = %d, want none", len(owners))
+		}
+	})
+}
+
+func TestTenderRemoveOwner(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+		username := func(employee models.Employee) string { return employee.Username }
+
+		for _, tender := range []models.Tender{f.bridge, f.asphalt, f.depot} {
+			check(t, repositories.Tenders.SetOwners(ctx, tender.ID, []uuid.UUID{f.alice.ID, f.bob.ID}))
+		}
+
+		// Только тендеры организации Beta.
+		check(t, repositories.Tenders.RemoveOwner(ctx, f.bob.ID, &f.orgB.ID))
+		owners, err := repositories.Tenders.Owners(ctx, f.bridge.ID)
+		check(t, err)
+		expectNames(t, owners, username, "alice", "bob")
+		owners, err = repositories.Tenders.Owners(ctx, f.asphalt.ID)
+		check(t, err)
+		expectNames(t, owners, username, "alice")
+
+		// Все тендеры.
+		check(t, repositories.Tenders.RemoveOwner(ctx, f.alice.ID, nil))
+		for _, tender := range []models.Tender{f.bridge, f.depot} {
+			owners, err = repositories.Tenders.Owners(ctx, tender.ID)
+			check(t, err)
+			if slices.ContainsFunc(owners, func(owner models.Employee) bool { return owner.ID == f.alice.ID }) {
+				t.Fatalf("alice still owns %s", tender.Name)
+			}
+		}
+	})
+}
+
+func TestTenderSaveAndHistory(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		_, err := repositories.Tenders.FindByID(ctx, uuid.New())
+		expectError(t, err, ErrNotFound)
+
+		created := models.Tender{Name: "Fence", Description: "Steel", ServiceType: "Construction", OrganizationID: f.orgA.ID, CreatorUsername: "alice"}
+		check(t, repositories.Tenders.Create(ctx, &created))
+		if created.ID == uuid.Nil || created.Status != models.TenderStatusCreated || created.Version != 1 {
+			t.Fatalf("created tender = %+v, want defaults filled", created)
+		}
+
+		seed(t, pointer(models.NewTenderHistory(created)))
+		created.Name, created.Version = "Fence v2", 2
+		check(t, repositories.Tenders.Save(ctx, &created))
+		seed(t, pointer(models.NewTenderHistory(created)))
+
+		found, err := repositories.Tenders.FindByID(ctx, created.ID)
+		check(t, err)
+		if found.Name != "Fence v2" || found.Version != 2 {
+			t.Fatalf("found = %q v%d", found.Name, found.Version)
+		}
+
+		history, err := repositories.Tenders.ListHistory(ctx, created.ID)
+		check(t, err)
+		if len(history) != 2 || history[0].Version != 1 || history[1].Version != 2 {
+			t.Fatalf("history = %+v", history)
+		}
+	})
+}
+
+type bidFixture struct {
+	fixture
+	road, river, soup, stone models.Bid
+}
+
+func newBidFixture() bidFixture {
+	f := bidFixture{fixture: newFixture()}
+	bid := func(name string, tender models.Tender, author models.Employee, price string, minutes int) models.Bid {
+		bid := models.Bid{
+			ID:         uuid.New(),
+			Name:       name,
+			Status:     "Published",
+			TenderID:   tender.ID,
+			AuthorType: "User",
+			AuthorID:   author.ID,
+			Version:    1,
+			CreatedAt:  at(minutes),
+		}
+		if price != "" {
+			bid.Price = pointer(decimal.RequireFromString(price))
+			bid.Currency = "RUB"
+		}
+		return bid
+	}
+	f.road = bid("road", f.bridge, f.bob, "100", 1)
+	f.river = bid("river", f.bridge, f.carol, "", 2)
+	f.soup = bid("soup", f.canteen, f.bob, "50", 3)
+	f.stone = bid("stone", f.bridge, f.carol, "70", 4)
+	return f
+}
+
+func (f *bidFixture) seed(t *testing.T, seed seedFunc) {
+	t.Helper()
+	f.fixture.seed(t, seed)
+	seed(t, &f.road, &f.river, &f.soup, &f.stone)
+}
+
+func TestBidList(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newBidFixture()
+		f.seed(t, seed)
+
+		tests := []struct {
+			name   string
+			filter BidFilter
+			order  BidOrder
+			want   []string
+		}{
+			{"anonymous sees nothing", BidFilter{Visible: &Visibility{}}, BidOrderCreated, nil},
+			{"author sees own bids", BidFilter{Visible: &Visibility{EmployeeID: f.bob.ID}}, BidOrderCreated, []string{"road", "soup"}},
+			{"responsible sees bids on own tenders", BidFilter{Visible: &Visibility{EmployeeID: f.alice.ID, Organizations: []uuid.UUID{f.orgA.ID}}}, BidOrderCreated, []string{"road", "river", "stone"}},
+			{"author filter", BidFilter{AuthorID: &f.carol.ID}, BidOrderCreated, []string{"river", "stone"}},
+			{"price ascending puts missing price last", BidFilter{TenderID: &f.bridge.ID}, BidOrderPriceAsc, []string{"stone", "road", "river"}},
+			{"price descending puts missing price last", BidFilter{TenderID: &f.bridge.ID}, BidOrderPriceDesc, []string{"road", "stone", "river"}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				result, err := repositories.Bids.List(ctx, test.filter, test.order, page(t, "limit=10"))
+				check(t, err)
+				expectNames(t, result.Items, bidName, test.want...)
+
+				count, err := repositories.Bids.Count(ctx, test.filter)
+				check(t, err)
+				if count != int64(len(test.want)) {
+					t.Fatalf("count = %d, want %d", count, len(test.want))
+				}
+			})
+		}
+	})
+}
+
+func TestBidListPagesByPrice(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newBidFixture()
+		f.seed(t, seed)
+
+		filter := BidFilter{TenderID: &f.bridge.ID}
+		first, err := repositories.Bids.List(ctx, filter, BidOrderPriceAsc, page(t, "limit=2"))
+		check(t, err)
+		expectNames(t, first.Items, bidName, "stone", "road")
+
+		cursor := nextCursor(first)
+		second, err := repositories.Bids.List(ctx, filter, BidOrderPriceAsc, page(t, "limit=2&cursor="+cursor))
+		check(t, err)
+		expectNames(t, second.Items, bidName, "river")
+
+		_, err = repositories.Bids.List(ctx, filter, BidOrderCreated, page(t, "cursor="+cursor))
+		expectError(t, err, pagination.ErrCursorMismatch)
+	})
+}
+
+func TestBidVotesAndFeedback(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newBidFixture()
+		f.seed(t, seed)
+
+		bids, err := repositories.Bids.ListByTender(ctx, f.bridge.ID)
+		check(t, err)
+		expectNames(t, bids, bidName, "road", "river", "stone")
+
+		seed(t,
+			&models.BidDecision{ID: uuid.New(), BidID: f.road.ID, ResponsibleID: f.bob.ID, Decision: models.DecisionRejected, CreatedAt: at(5), UpdatedAt: at(7)},
+			&models.BidDecision{ID: uuid.New(), BidID: f.road.ID, ResponsibleID: f.alice.ID, Decision: models.DecisionApproved, CreatedAt: at(6), UpdatedAt: at(6)},
+			&models.BidDecision{ID: uuid.New(), BidID: f.stone.ID, ResponsibleID: f.alice.ID, Decision: models.DecisionApproved, CreatedAt: at(6), UpdatedAt: at(6)},
+		)
+		votes, err := repositories.Bids.Votes(ctx, f.road.ID)
+		check(t, err)
+		if len(votes) != 2 || votes[0].ResponsibleID != f.alice.ID || votes[1].ResponsibleID != f.bob.ID {
+			t.Fatalf("votes = %+v, want ordered by update time", votes)
+		}
+
+		seed(t,
+			&models.BidFeedback{ID: uuid.New(), BidID: f.soup.ID, Feedback: "late", AuthorID: f.alice.ID, CreatedAt: at(9)},
+			&models.BidFeedback{ID: uuid.New(), BidID: f.road.ID, Feedback: "fine", AuthorID: f.alice.ID, CreatedAt: at(8)},
+			&models.BidFeedback{ID: uuid.New(), BidID: f.stone.ID, Feedback: "other author", AuthorID: f.alice.ID, CreatedAt: at(8)},
+		)
+		feedback, err := repositories.Bids.ListFeedback(ctx, f.bob.ID, page(t, "limit=1&total=true"))
+		check(t, err)
+		expectNames(t, feedback.Items, func(feedback models.BidFeedback) string { return feedback.Feedback }, "fine")
+		if feedback.Total != 2 {
+			t.Fatalf("feedback total = %d, want 2", feedback.Total)
+		}
+		feedback, err = repositories.Bids.ListFeedback(ctx, f.bob.ID, page(t, "limit=1&cursor="+nextCursor(feedback)))
+		check(t, err)
+		expectNames(t, feedback.Items, func(feedback models.BidFeedback) string { return feedback.Feedback }, "late")
+	})
+}
+
+func TestEvaluations(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newBidFixture()
+		f.seed(t, seed)
+
+		check(t, repositories.Evaluations.ReplaceCriteria(ctx, f.bridge.ID, []models.TenderCriterion{
+			{TenderID: f.bridge.ID, Name: "price", Weight: 60},
+			{TenderID: f.bridge.ID, Name: "quality", Weight: 40},
+		}))
+		criteria, err := repositories.Evaluations.Criteria(ctx, f.bridge.ID)
+		check(t, err)
+		expectNames(t, criteria, func(criterion models.TenderCriterion) string { return criterion.Name }, "price", "quality")
+
+		score := func(value int) []models.BidScore {
+			return []models.BidScore{{BidID: f.road.ID, CriterionID: criteria[0].ID, EvaluatorID: f.alice.ID, Score: value}}
+		}
+		check(t, repositories.Evaluations.SaveScores(ctx, score(5)))
+		check(t, repositories.Evaluations.SaveScores(ctx, score(8)))
+
+		scores, err := repositories.Evaluations.Scores(ctx, f.bridge.ID)
+		check(t, err)
+		if len(scores) != 1 || scores[0].Score != 8 {
+			t.Fatalf("scores = %+v, want one replaced score", scores)
+		}
+
+		count, err := repositories.Evaluations.CountScores(ctx, f.bridge.ID)
+		check(t, err)
+		if count != 1 {
+			t.Fatalf("count = %d, want 1", count)
+		}
+		count, err = repositories.Evaluations.CountScores(ctx, f.canteen.ID)
+		check(t, err)
+		if count != 0 {
+			t.Fatalf("count of other tender = %d, want 0", count)
+		}
+	})
+}
+
+func TestEmployees(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		dave := models.Employee{Username: "dave", LastName: "Aliev"}
+		check(t, repositories.Employees.Create(ctx, &dave))
+		if dave.ID == uuid.Nil || dave.Language != "ru" {
+			t.Fatalf("created employee = %+v, want defaults filled", dave)
+		}
+
+		err := failing(repositories, func(r Repositories) error {
+			return r.Employees.Create(ctx, &models.Employee{Username: "alice"})
+		})
+		expectError(t, err, ErrDuplicate)
+
+		err = failing(repositories, func(r Repositories) error {
+			renamed := f.bob
+			renamed.Username = "alice"
+			return r.Employees.Save(ctx, &renamed)
+		})
+		expectError(t, err, ErrDuplicate)
+
+		found, err := repositories.Employees.FindByUsername(ctx, "bob")
+		check(t, err)
+		if found.ID != f.bob.ID {
+			t.Fatalf("found %s, want bob", found.Username)
+		}
+		_, err = repositories.Employees.FindByUsername(ctx, "nobody")
+		expectError(t, err, ErrNotFound)
+
+		employees, err := repositories.Employees.FindByIDs(ctx, []uuid.UUID{f.bob.ID, uuid.New(), f.alice.ID, f.bob.ID})
+		check(t, err)
+		if len(employees) != 2 {
+			t.Fatalf("found %d employees, want 2", len(employees))
+		}
+
+		username := func(employee models.Employee) string { return employee.Username }
+		tests := []struct {
+			name   string
+			filter EmployeeFilter
+			want   []string
+		}{
+			{"all", EmployeeFilter{}, []string{"alice", "bob", "carol", "dave"}},
+			{"search ignores case", EmployeeFilter{Search: "ALI"}, []string{"alice", "dave"}},
+			{"active only", EmployeeFilter{ActiveOnly: true}, []string{"alice", "bob", "dave"}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				result, err := repositories.Employees.List(ctx, test.filter, page(t, "limit=10"))
+				check(t, err)
+				expectNames(t, result.Items, username, test.want...)
+			})
+		}
+	})
+}
+
+func TestOrganizationResponsibles(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		check(t, repositories.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{OrganizationID: f.orgB.ID, UserID: f.alice.ID}))
+		err := failing(repositories, func(r Repositories) error {
+			return r.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{OrganizationID: f.orgB.ID, UserID: f.alice.ID})
+		})
+		expectError(t, err, ErrDuplicate)
+
+		organizations, err := repositories.Organizations.ResponsibleOrganizations(ctx, f.alice.ID)
+		check(t, err)
+		if len(organizations) != 2 {
+			t.Fatalf("alice is responsible for %d organizations, want 2", len(organizations))
+		}
+
+		count, err := repositories.Organizations.CountResponsibles(ctx, f.orgB.ID)
+		check(t, err)
+		if count != 1 {
+			t.Fatalf("responsibles = %d, want 1", count)
+		}
+
+		check(t, repositories.Transaction(ctx, func(r Repositories) error {
+			locked, err := r.Organizations.FindForUpdate(ctx, f.orgB.ID)
+			if err != nil {
+				return err
+			}
+			if locked.ID != f.orgB.ID {
+				t.Fatalf("locked organization %s, want %s", locked.Name, f.orgB.Name)
+			}
+			_, err = r.Organizations.FindForUpdate(ctx, uuid.New())
+			expectError(t, err, ErrNotFound)
+			return nil
+		}))
+
+		expectError(t, repositories.Organizations.RemoveResponsible(ctx, f.orgB.ID, f.bob.ID), ErrNotFound)
+		check(t, repositories.Organizations.RemoveResponsible(ctx, f.orgB.ID, f.alice.ID))
+
+		responsible, err := repositories.Organizations.IsResponsible(ctx, f.orgB.ID, f.alice.ID)
+		check(t, err)
+		if responsible {
+			t.Fatal("alice is still responsible after removal")
+		}
+		responsible, err = repositories.Organizations.IsResponsible(ctx, f.orgA.ID, f.alice.ID)
+		check(t, err)
+		if !responsible {
+			t.Fatal("removal touched another organization")
+		}
+
+		result, err := repositories.Organizations.List(ctx, page(t, "limit=1"))
+		check(t, err)
+		expectNames(t, result.Items, func(organization models.Organization) string { return organization.Name }, "Alpha")
+		result, err = repositories.Organizations.List(ctx, page(t, "limit=1&cursor="+nextCursor(result)))
+		check(t, err)
+		expectNames(t, result.Items, func(organization models.Organization) string { return organization.Name }, "Beta")
+	})
+}
+
+func TestAttachments(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		attachment := func(name string) *models.Attachment {
+			return &models.Attachment{
+				EntityType:  models.AttachmentEntityTender,
+				EntityID:    f.bridge.ID,
+				FileName:    name,
+				ContentType: "application/pdf",
+				Size:        3,
+				SHA256:      strings.Repeat("0", 64),
+				StorageKey:  name,
+				UploadedBy:  f.alice.ID,
+			}
+		}
+		first, second := attachment("a.pdf"), attachment("b.pdf")
+		first.CreatedAt, second.CreatedAt = at(1), at(2)
+		check(t, repositories.Attachments.Create(ctx, first))
+		check(t, repositories.Attachments.Create(ctx, second))
+
+		_, err := repositories.Attachments.Find(ctx, models.AttachmentEntityBid, f.bridge.ID, first.ID)
+		expectError(t, err, ErrNotFound)
+		found, err := repositories.Attachments.Find(ctx, models.AttachmentEntityTender, f.bridge.ID, first.ID)
+		check(t, err)
+		if found.FileName != "a.pdf" {
+			t.Fatalf("found %q", found.FileName)
+		}
+
+		check(t, repositories.Attachments.Delete(ctx, first.ID))
+		expectError(t, repositories.Attachments.Delete(ctx, first.ID), ErrNotFound)
+
+		attachments, err := repositories.Attachments.ListByEntity(ctx, models.AttachmentEntityTender, f.bridge.ID)
+		check(t, err)
+		expectNames(t, attachments, func(attachment models.Attachment) string { return attachment.FileName }, "b.pdf")
+	})
+}
+
+func TestWebhookDeadLetters(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		hookA := models.Webhook{ID: uuid.New(), OrganizationID: f.orgA.ID, URL: "http://a", Secret: "a", EventTypes: []string{"tender.published"}, CreatedAt: at(0)}
+		hookB := models.Webhook{ID: uuid.New(), OrganizationID: f.orgB.ID, URL: "http://b", Secret: "b", EventTypes: []string{"tender.published"}, CreatedAt: at(0)}
+		event := models.OutboxEvent{ID: uuid.New(), EventType: "tender.published", OrganizationID: f.orgA.ID, Payload: []byte(`{}`), CreatedAt: at(0)}
+		delivery := func(name string, webhook models.Webhook, deadAt *time.Time) models.WebhookDelivery {
+			return models.WebhookDelivery{ID: uuid.New(), WebhookID: webhook.ID, EventID: event.ID, Attempts: 8, NextAttemptAt: at(0), LastError: name, DeadAt: deadAt, CreatedAt: at(0)}
+		}
+		older := delivery("older", hookA, pointer(at(5)))
+		pending := delivery("pending", hookA, nil)
+		foreign := delivery("foreign", hookB, pointer(at(7)))
+		newer := delivery("newer", hookA, pointer(at(6)))
+		seed(t, &hookA, &hookB, &event, &older, &pending, &foreign, &newer)
+
+		lastError := func(letter DeadLetter) string { return letter.LastError }
+		letters, err := repositories.Webhooks.DeadLetters(ctx, f.orgA.ID, nil, page(t, "limit=10"))
+		check(t, err)
+		expectNames(t, letters.Items, lastError, "newer", "older")
+		if letters.Items[0].EventType != "tender.published" {
+			t.Fatalf("event type = %q", letters.Items[0].EventType)
+		}
+
+		letters, err = repositories.Webhooks.DeadLetters(ctx, f.orgA.ID, &hookB.ID, page(t, ""))
+		check(t, err)
+		expectNames(t, letters.Items, lastError)
+
+		expectError(t, repositories.Webhooks.Retry(ctx, f.orgB.ID, older.ID), ErrNotFound)
+		expectError(t, repositories.Webhooks.Retry(ctx, f.orgA.ID, pending.ID), ErrNotFound)
+		check(t, repositories.Webhooks.Retry(ctx, f.orgA.ID, older.ID))
+
+		letters, err = repositories.Webhooks.DeadLetters(ctx, f.orgA.ID, nil, page(t, ""))
+		check(t, err)
+		expectNames(t, letters.Items, lastError, "newer")
+
+		expectError(t, repositories.Webhooks.Delete(ctx, f.orgB.ID, hookA.ID), ErrNotFound)
+		check(t, repositories.Webhooks.Delete(ctx, f.orgA.ID, hookA.ID))
+
+		webhooks, err := repositories.Webhooks.ListByOrganization(ctx, f.orgA.ID)
+		check(t, err)
+		if len(webhooks) != 0 {
+			t.Fatalf("webhooks = %d after delete", len(webhooks))
+		}
+		letters, err = repositories.Webhooks.DeadLetters(ctx, f.orgA.ID, nil, page(t, ""))
+		check(t, err)
+		expectNames(t, letters.Items, lastError)
+
+		letters, err = repositories.Webhooks.DeadLetters(ctx, f.orgB.ID, nil, page(t, ""))
+		check(t, err)
+		expectNames(t, letters.Items, lastError, "foreign")
+	})
+}
+
+func TestNotifications(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		notification := func(employee models.Employee, kind string, message string, minutes int) models.Notification {
+			return models.Notification{ID: uuid.New(), EmployeeID: employee.ID, Type: kind, Message: message, CreatedAt: at(minutes)}
+		}
+		created := notification(f.alice, "bid_created", "created", 1)
+		closed := notification(f.alice, "tender_closed", "closed", 2)
+		read := notification(f.alice, "bid_created", "read", 3)
+		read.ReadAt = pointer(at(4))
+		foreign := notification(f.bob, "bid_created", "foreign", 5)
+		seed(t, &created, &closed, &read, &foreign)
+
+		message := func(notification models.Notification) string { return notification.Message }
+		tests := []struct {
+			name   string
+			filter NotificationFilter
+			want   []string
+		}{
+			{"newest first", NotificationFilter{}, []string{"read", "closed", "created"}},
+			{"unread", NotificationFilter{Read: pointer(false)}, []string{"closed", "created"}},
+			{"read", NotificationFilter{Read: pointer(true)}, []string{"read"}},
+			{"type", NotificationFilter{Type: "tender_closed"}, []string{"closed"}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				result, err := repositories.Notifications.List(ctx, f.alice.ID, test.filter, page(t, "limit=10"))
+				check(t, err)
+				expectNames(t, result.Items, message, test.want...)
+			})
+		}
+
+		_, err := repositories.Notifications.MarkRead(ctx, f.bob.ID, created.ID)
+		expectError(t, err, ErrNotFound)
+
+		first, err := repositories.Notifications.MarkRead(ctx, f.alice.ID, created.ID)
+		check(t, err)
+		second, err := repositories.Notifications.MarkRead(ctx, f.alice.ID, created.ID)
+		check(t, err)
+		if first.ReadAt == nil || second.ReadAt == nil || second.ReadAt.Sub(*first.ReadAt).Abs() > time.Millisecond {
+			t.Fatalf("read at changed on repeated mark: %v, %v", first.ReadAt, second.ReadAt)
+		}
+
+		updated, err := repositories.Notifications.MarkAllRead(ctx, f.alice.ID)
+		check(t, err)
+		if updated != 1 {
+			t.Fatalf("marked %d, want 1", updated)
+		}
+
+		check(t, repositories.Notifications.SavePreferences(ctx, []models.NotificationPreference{
+			{EmployeeID: f.alice.ID, Type: "tender_closed", Enabled: false},
+		}))
+		check(t, repositories.Notifications.SavePreferences(ctx, []models.NotificationPreference{
+			{EmployeeID: f.alice.ID, Type: "tender_closed", Enabled: true},
+			{EmployeeID: f.alice.ID, Type: "bid_created", Enabled: false},
+		}))
+		preferences, err := repositories.Notifications.Preferences(ctx, f.alice.ID)
+		check(t, err)
+		if len(preferences) != 2 || preferences[0].Type != "bid_created" || preferences[0].Enabled || !preferences[1].Enabled {
+			t.Fatalf("preferences = %+v", preferences)
+		}
+	})
+}
+
+func TestAuditList(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		bidID := uuid.New()
+		event := func(actor models.Employee, action string, entityType string, entityID uuid.UUID, minutes int) models.AuditEvent {
+			return models.AuditEvent{
+				ID:            uuid.New(),
+				ActorID:       &actor.ID,
+				ActorUsername: actor.Username,
+				Action:        action,
+				EntityType:    entityType,
+				EntityID:      &entityID,
+				CreatedAt:     at(minutes),
+			}
+		}
+		seed(t,
+			pointer(event(f.alice, "create", "tender", f.bridge.ID, 1)),
+			pointer(event(f.bob, "update", "tender", f.bridge.ID, 2)),
+			pointer(event(f.alice, "delete", "bid", bidID, 3)),
+		)
+
+		action := func(event models.AuditEvent) string { return event.Action }
+		tests := []struct {
+			name   string
+			filter AuditFilter
+			want   []string
+		}{
+			{"newest first", AuditFilter{}, []string{"delete", "update", "create"}},
+			{"entity type", AuditFilter{EntityType: "tender"}, []string{"update", "create"}},
+			{"entity", AuditFilter{EntityID: &bidID}, []string{"delete"}},
+			{"actor", AuditFilter{ActorID: &f.alice.ID}, []string{"delete", "create"}},
+			{"actor username", AuditFilter{ActorUsername: "bob"}, []string{"update"}},
+			{"action", AuditFilter{Action: "create"}, []string{"create"}},
+			{"from is inclusive", AuditFilter{From: pointer(at(2))}, []string{"delete", "update"}},
+			{"to is inclusive", AuditFilter{To: pointer(at(2))}, []string{"update", "create"}},
+			{"before is exclusive", AuditFilter{Before: pointer(at(2))}, []string{"create"}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				result, err := repositories.Audit.List(ctx, test.filter, page(t, "limit=10"))
+				check(t, err)
+				expectNames(t, result.Items, action, test.want...)
+			})
+		}
+	})
+}
+
+func TestEvents(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		f := newFixture()
+		f.seed(t, seed)
+
+		event := func(tender models.Tender, kind string) *models.TenderEvent {
+			return &models.TenderEvent{TenderID: tender.ID, Type: kind, Payload: []byte(`{}`), CreatedAt: at(0)}
+		}
+		created, published, foreign, closed := event(f.bridge, "created"), event(f.bridge, "published"), event(f.depot, "created"), event(f.bridge, "closed")
+		for _, event := range []*models.TenderEvent{created, published, foreign, closed} {
+			check(t, repositories.Events.Publish(ctx, event))
+		}
+		seqs := []int64{created.Seq, published.Seq, foreign.Seq, closed.Seq}
+		if !slices.Equal(seqs, []int64{1, 2, 1, 3}) {
+			t.Fatalf("seqs = %v, want numbering per tender", seqs)
+		}
+
+		kind := func(event models.TenderEvent) string { return event.Type }
+		events, err := repositories.Events.After(ctx, f.bridge.ID, created.Seq, 10)
+		check(t, err)
+		expectNames(t, events, kind, "published", "closed")
+
+		events, err = repositories.Events.After(ctx, f.bridge.ID, 0, 1)
+		check(t, err)
+		expectNames(t, events, kind, "created")
+
+		last, err := repositories.Events.LastSeq(ctx, f.bridge.ID)
+		check(t, err)
+		if last != closed.Seq {
+			t.Fatalf("last seq = %d, want %d", last, closed.Seq)
+		}
+		last, err = repositories.Events.LastSeq(ctx, f.asphalt.ID)
+		check(t, err)
+		if last != 0 {
+			t.Fatalf("last seq without events = %d, want 0", last)
+		}
+	})
+}
+
+// TestConcurrentHistoryVersions правит один тендер из нескольких
+// транзакций сразу, как сервисы: под блокировкой тендера версии истории
+// идут подряд без повторов. Postgres-вариант работает с зафиксированными
+// данными, чтобы транзакции шли на разных соединениях.
+func TestConcurrentHistoryVersions(t *testing.T) {
+	const edits = 20
+
+	run := func(t *testing.T, repositories Repositories) models.Tender {
+		organization := models.Organization{Name: "Gamma", Type: "LLC"}
+		check(t, repositories.Organizations.Create(ctx, &organization))
+		tender := models.Tender{Name: "Road", Status: models.TenderStatusCreated, OrganizationID: organization.ID, Version: 1}
+		check(t, repositories.Tenders.Create(ctx, &tender))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, edits)
+		for i := 0; i < edits; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- repositories.Transaction(ctx, func(r Repositories) error {
+					locked, err := r.Tenders.FindForUpdate(ctx, tender.ID)
+					if err != nil {
+						return err
+					}
+					history := models.NewTenderHistory(locked)
+					if err := r.Tenders.CreateHistory(ctx, &history); err != nil {
+						return err
+					}
+					locked.Description = fmt.Sprintf("edit %d", i)
+					locked.Version = history.Version + 1
+					return r.Tenders.Save(ctx, &locked)
+				})
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			check(t, err)
+		}
+
+		history, err := repositories.Tenders.ListHistory(ctx, tender.ID)
+		check(t, err)
+		versions := make([]int, 0, len(history))
+		for _, row := range history {
+			versions = append(versions, row.Version)
+		}
+		want := make([]int, 0, edits)
+		for version := 1; version <= edits; version++ {
+			want = append(want, version)
+		}
+		if !slices.Equal(versions, want) {
+			t.Fatalf("history versions = %v, want %v", versions, want)
+		}
+
+		stored, err := repositories.Tenders.FindByID(ctx, tender.ID)
+		check(t, err)
+		if stored.Version != edits+1 {
+			t.Fatalf("version = %d, want %d", stored.Version, edits+1)
+		}
+		return tender
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		store := &memoryStore{data: newMemoryData()}
+		run(t, store.repositories(store.transaction))
+	})
+
+	t.Run("gorm", func(t *testing.T) {
+		db := testDB(t)
+		tender := run(t, NewGorm(db))
+		t.Cleanup(func() {
+			db.Where("tender_id = ?", tender.ID).Delete(&models.TenderHistory{})
+			db.Delete(&models.Tender{}, "id = ?", tender.ID)
+			db.Delete(&models.Organization{}, "id = ?", tender.OrganizationID)
+		})
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T, repositories Repositories, seed seedFunc) {
+		errRollback := errors.New("rollback")
+		exists := func(username string) bool {
+			t.Helper()
+			_, err := repositories.Employees.FindByUsername(ctx, username)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				t.Fatalf("find %s: %v", username, err)
+			}
+			return err == nil
+		}
+
+		err := repositories.Transaction(ctx, func(r Repositories) error {
+			check(t, r.Employees.Create(ctx, &models.Employee{Username: "rolled"}))
+			return errRollback
+		})
+		expectError(t, err, errRollback)
+		if exists("rolled") {
+			t.Fatal("failed transaction was committed")
+		}
+
+		err = repositories.Transaction(ctx, func(r Repositories) error {
+			check(t, r.Employees.Create(ctx, &models.Employee{Username: "outer"}))
+			inner := r.Transaction(ctx, func(r Repositories) error {
+				check(t, r.Employees.Create(ctx, &models.Employee{Username: "inner"}))
+				return errRollback
+			})
+			expectError(t, inner, errRollback)
+			return nil
+		})
+		check(t, err)
+		if !exists("outer") || exists("inner") {
+			t.Fatal("nested transaction did not roll back to its savepoint")
+		}
+
+		for i := 0; i < 3; i++ {
+			check(t, repositories.Transaction(ctx, func(r Repositories) error {
+				return r.Employees.Create(ctx, &models.Employee{Username: fmt.Sprintf("committed-%d", i)})
+			}))
+		}
+		if !exists("committed-2") {
+			t.Fatal("committed transaction is lost")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Package retry выполняет задачи очереди с арендой и повторами: так
+// отправляются письма и доставляются вебхуки.
+//
+// Worker забирает задачи, срок которых наступил, в короткой транзакции с
+// переносом срока на Lease вперёд, чтобы их не взяла другая реплика, и
+// выполняет их уже вне транзакции. После неудачи следующая попытка
+// откладывается с экспоненциальной задержкой, после MaxAttempts попыток
+// задача больше не выполняется.
+package retry
+
+import (
+	"ZADANIE-6105/repository"
+	"context"
+	"log"
+	"time"
+)
+
+// Queue — хранилище задач Worker.
+type Queue[T any] interface {
+	// Lease забирает до limit задач, срок которых наступил к now, и
+	// откладывает их до until.
+	Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]T, error)
+	// Record сохраняет результат попытки выполнить задачу.
+	Record(ctx context.Context, job T, attempt repository.Attempt) error
+	// Attempts возвращает число уже сделанных попыток задачи.
+	Attempts(job T) int
+}
+
+// Worker выполняет задачи очереди.
+type Worker[T any] struct {
+	// Name — название задач в журнале.
+	Name  string
+	Queue Queue[T]
+	// Run выполняет задачу и возвращает код ответа получателя (или 0) и
+	// ошибку попытки.
+	Run func(ctx context.Context, job T) (int, error)
+	// MaxAttempts — число попыток, после которого задача больше не
+	// выполняется.
+	MaxAttempts int
+	// Задержка перед n-й повторной попыткой — BaseDelay·2^(n-1), но не
+	// больше MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Lease — на сколько задача откладывается на время выполнения.
+	Lease     time.Duration
+	BatchSize int
+}
+
+// RunOnce выполняет задачи, срок которых наступил.
+func (w *Worker[T]) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	jobs, err := w.Queue.Lease(ctx, now, now.Add(w.Lease), w.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		status, runErr := w.Run(ctx, job)
+		if err := w.Queue.Record(ctx, job, w.attempt(w.Queue.Attempts(job)+1, status, runErr)); err != nil {
+			log.Printf("Failed to record %s attempt: %v", w.Name, err)
+		}
+	}
+	return nil
+}
+
+// attempt описывает попытку номер attempts и планирует следующую.
+func (w *Worker[T]) attempt(attempts int, status int, err error) repository.Attempt {
+	attempt := repository.Attempt{Attempts: attempts, At: time.Now(), Status: status}
+	switch {
+	case err == nil:
+		attempt.Done = true
+	case attempts >= w.MaxAttempts:
+		attempt.Error = err.Error()
+		attempt.Dead = true
+	default:
+		attempt.Error = err.Error()
+		attempt.NextAttemptAt = attempt.At.Add(w.Backoff(attempts))
+	}
+	return attempt
+}
+
+// Backoff возвращает задержку после attempts неудачных попыток.
+func (w *Worker[T]) Backoff(attempts int) time.Duration {
+	delay := w.BaseDelay
+	for i := 1; i < attempts && delay < w.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > w.MaxDelay {
+		delay = w.MaxDelay
+	}
+	return delay
+}
+
+// Every вызывает run сразу и затем раз в interval, пока не отменён ctx.
+// Ошибки run пишутся в журнал под именем name.
+func Every(ctx context.Context, interval time.Duration, name string, run func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := run(ctx); err != nil {
+			log.Printf("%s failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package retry
+
+import (
+	"ZADANIE-6105/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// job — задача тестовой очереди.
+type job struct {
+	name     string
+	attempts int
+}
+
+// fakeQueue отдаёт задачи один раз и запоминает результаты попыток.
+type fakeQueue struct {
+	jobs     []job
+	until    time.Time
+	attempts map[string]repository.Attempt
+}
+
+func (q *fakeQueue) Lease(_ context.Context, _ time.Time, until time.Time, limit int) ([]job, error) {
+	q.until = until
+	jobs := q.jobs[:min(len(q.jobs), limit)]
+	q.jobs = q.jobs[len(jobs):]
+	return jobs, nil
+}
+
+func (q *fakeQueue) Record(_ context.Context, job job, attempt repository.Attempt) error {
+	q.attempts[job.name] = attempt
+	return nil
+}
+
+func (*fakeQueue) Attempts(job job) int {
+	return job.attempts
+}
+
+func TestRunOnce(t *testing.T) {
+	queue := &fakeQueue{
+		jobs: []job{
+			{name: "sent"},
+			{name: "failed", attempts: 2},
+			{name: "dead", attempts: 3},
+			{name: "later"},
+		},
+		attempts: make(map[string]repository.Attempt),
+	}
+	w := &Worker[job]{
+		Name:  "test",
+		Queue: queue,
+		Run: func(_ context.Context, job job) (int, error) {
+			if job.name == "sent" {
+				return 200, nil
+			}
+			return 503, errors.New("unavailable")
+		},
+		MaxAttempts: 4,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+		Lease:       time.Minute,
+		BatchSize:   3,
+	}
+
+	start := time.Now()
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if queue.until.Before(start.Add(w.Lease)) {
+		t.Fatalf("lease until %v, want at least %v", queue.until, start.Add(w.Lease))
+	}
+	if len(queue.jobs) != 1 {
+		t.Fatalf("%d jobs left, want one beyond the batch", len(queue.jobs))
+	}
+
+	sent := queue.attempts["sent"]
+	if !sent.Done || sent.Dead || sent.Attempts != 1 || sent.Status != 200 || sent.Error != "" {
+		t.Fatalf("sent attempt %+v", sent)
+	}
+
+	failed := queue.attempts["failed"]
+	if failed.Done || failed.Dead || failed.Attempts != 3 || failed.Status != 503 || failed.Error != "unavailable" {
+		t.Fatalf("failed attempt %+v", failed)
+	}
+	if delay := failed.NextAttemptAt.Sub(failed.At); delay != 4*time.Minute {
+		t.Fatalf("retry after %v, want %v", delay, 4*time.Minute)
+	}
+
+	dead := queue.attempts["dead"]
+	if dead.Done || !dead.Dead || dead.Attempts != 4 || dead.Error != "unavailable" {
+		t.Fatalf("dead attempt %+v", dead)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	w := &Worker[job]{BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{20, 10 * time.Minute},
+	}
+
+	for _, test := range tests {
+		if got := w.Backoff(test.attempts); got != test.want {
+			t.Fatalf("Backoff(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
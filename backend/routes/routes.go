@@ -2,6 +2,7 @@ package routes
 
 import (
 	"ZADANIE-6105/handlers"
+	"ZADANIE-6105/idempotency"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,17 +15,57 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 		c.JSON(http.StatusOK, gin.H{"message": "pong"})
 	})
 
+	idempotent := idempotency.Middleware(idempotency.TTLFromEnv())
+
 	api := router.Group("/api")
 	{
-		api.POST("/tenders/new", handlers.CreateTender)
+		api.POST("/auth/login", handlers.Login)
+		api.POST("/auth/refresh", handlers.RefreshToken)
+		api.POST("/tenders/new", idempotent, handlers.CreateTender)
 		api.GET("/tenders", handlers.GetTenders)
 		api.GET("/tenders/my", handlers.GetUserTenders)
 		api.POST("/employees/new", handlers.CreateEmployee)
+		api.GET("/employees", handlers.GetEmployees)
+		api.GET("/employees/:employeeId", handlers.GetEmployee)
+		api.GET("/employees/username/:username", handlers.GetEmployeeByUsername)
+		api.PATCH("/employees/:employeeId", handlers.UpdateEmployee)
+		api.POST("/employees/:employeeId/deactivate", handlers.DeactivateEmployee)
+		api.GET("/audit", handlers.GetAuditEvents)
+		api.GET("/notifications", handlers.GetNotifications)
+		api.POST("/notifications/read-all", handlers.MarkAllNotificationsRead)
+		api.POST("/notifications/:notificationId/read", handlers.MarkNotificationRead)
+		api.GET("/notifications/preferences", handlers.GetNotificationPreferences)
+		api.PUT("/notifications/preferences", handlers.SetNotificationPreferences)
+		api.POST("/organizations", handlers.CreateOrganization)
+		api.GET("/organizations", handlers.GetOrganizations)
+		api.GET("/organizations/:organizationId", handlers.GetOrganization)
+		api.PATCH("/organizations/:organizationId", handlers.UpdateOrganization)
+		api.POST("/organizations/:organizationId/responsibles", handlers.AddOrganizationResponsible)
+		api.DELETE("/organizations/:organizationId/responsibles/:userId", handlers.RemoveOrganizationResponsible)
+		api.POST("/organizations/:organizationId/webhooks", handlers.CreateWebhook)
+		api.GET("/organizations/:organizationId/webhooks", handlers.GetWebhooks)
+		api.DELETE("/organizations/:organizationId/webhooks/:webhookId", handlers.DeleteWebhook)
+		api.GET("/organizations/:organizationId/webhook-deliveries/dead", handlers.GetWebhookDeadLetters)
+		api.POST("/organizations/:organizationId/webhook-deliveries/:deliveryId/retry", handlers.RetryWebhookDelivery)
 		api.PATCH("/tenders/:tenderId/edit", handlers.UpdateTender)
 		api.GET("/tenders/:tenderId/status", handlers.GetTenderStatus)
+		api.GET("/tenders/:tenderId/events", handlers.GetTenderEvents)
 		api.PUT("/tenders/:tenderId/status", handlers.UpdateTenderStatus)
 		api.PUT("/tenders/:tenderId/rollback/:version", handlers.RollbackTender)
-		api.POST("/bids/new", handlers.CreateBid)
+		api.GET("/tenders/:tenderId/owners", handlers.GetTenderOwners)
+		api.PUT("/tenders/:tenderId/owners", handlers.SetTenderOwners)
+		api.PUT("/tenders/:tenderId/creator", handlers.TransferTenderCreator)
+		api.GET("/tenders/:tenderId/criteria", handlers.GetTenderCriteria)
+		api.PUT("/tenders/:tenderId/criteria", handlers.SetTenderCriteria)
+		api.GET("/tenders/:tenderId/ranking", handlers.GetTenderRanking)
+		api.GET("/tenders/:tenderId/versions", handlers.GetTenderVersions)
+		api.GET("/tenders/:tenderId/versions/:version", handlers.GetTenderVersion)
+		api.GET("/tenders/:tenderId/diff", handlers.DiffTenderVersions)
+		api.POST("/tenders/:tenderId/attachments", handlers.UploadTenderAttachment)
+		api.GET("/tenders/:tenderId/attachments", handlers.GetTenderAttachments)
+		api.GET("/tenders/:tenderId/attachments/:attachmentId", handlers.DownloadTenderAttachment)
+		api.DELETE("/tenders/:tenderId/attachments/:attachmentId", handlers.DeleteTenderAttachment)
+		api.POST("/bids/new", idempotent, handlers.CreateBid)
 		api.GET("/bids/my", handlers.GetMyBids)
 		api.GET("/bids/:tenderId/:action", func(c *gin.Context) {
 			action := c.Param("action")
@@ -35,6 +76,22 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 				bidId := c.Param("tenderId")
 				c.Set("bidId", bidId)
 				handlers.GetBidStatus(c)
+			} else if action == "decisions" {
+				bidId := c.Param("tenderId")
+				c.Set("bidId", bidId)
+				handlers.GetBidDecisions(c)
+			} else if action == "attachments" {
+				bidId := c.Param("tenderId")
+				c.Set("bidId", bidId)
+				handlers.GetBidAttachments(c)
+			} else if action == "versions" {
+				bidId := c.Param("tenderId")
+				c.Set("bidId", bidId)
+				handlers.GetBidVersions(c)
+			} else if action == "diff" {
+				bidId := c.Param("tenderId")
+				c.Set("bidId", bidId)
+				handlers.DiffBidVersions(c)
 			} else {
 				c.JSON(404, gin.H{"reason": "Not found"})
 			}
@@ -44,6 +101,19 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 		api.PUT("/bids/:bidId/rollback/:version", handlers.RollbackBid)
 		api.PUT("/bids/:bidId/submit_decision", handlers.SubmitDecision)
 		api.PUT("/bids/:bidId/feedback", handlers.SendFeedback)
+		api.PUT("/bids/:bidId/scores", handlers.ScoreBid)
 		api.GET("/bids/:tenderId/reviews", handlers.GetBidReviews)
+		api.GET("/bids/:tenderId/attachments/:attachmentId", func(c *gin.Context) {
+			bidId := c.Param("tenderId")
+			c.Set("bidId", bidId)
+			handlers.DownloadBidAttachment(c)
+		})
+		api.GET("/bids/:tenderId/versions/:version", func(c *gin.Context) {
+			bidId := c.Param("tenderId")
+			c.Set("bidId", bidId)
+			handlers.GetBidVersion(c)
+		})
+		api.POST("/bids/:bidId/attachments", handlers.UploadBidAttachment)
+		api.DELETE("/bids/:bidId/attachments/:attachmentId", handlers.DeleteBidAttachment)
 	}
 }
@@ -0,0 +1,148 @@
+// Package scheduler публикует, закрывает и вскрывает тендеры по их срокам.
+//
+// Планировщик запускается в каждой реплике, но за один проход работает
+// только одна из них: проход выполняется в транзакции под
+// pg_try_advisory_xact_lock, и реплики, не получившие блокировку,
+// пропускают свой тик.
+package scheduler
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/notifications"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/stream"
+	"ZADANIE-6105/webhooks"
+	"context"
+	"log"
+	"time"
+)
+
+// Ключ advisory-блокировки планировщика тендеров.
+const lockKey int64 = 6105_0001
+
+// Start запускает цикл планировщика и возвращается после отмены ctx.
+func Start(ctx context.Context, repositories repository.Repositories, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := RunOnce(ctx, repositories); err != nil {
+			log.Printf("Tender scheduler failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce публикует тендеры, у которых наступило publishAt, и закрывает
+// тендеры с истёкшим сроком подачи предложений.
+func RunOnce(ctx context.Context, repositories repository.Repositories) error {
+	return repositories.Transaction(ctx, func(r repository.Repositories) error {
+		locked, err := r.Locks.TryLock(ctx, lockKey)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		now := time.Now()
+		if err := transition(ctx, r, repository.DuePublish, models.TenderStatusPublished, now); err != nil {
+			return err
+		}
+		if err := transition(ctx, r, repository.DueClose, models.TenderStatusClosed, now); err != nil {
+			return err
+		}
+		return unseal(ctx, r, now)
+	})
+}
+
+// unseal фиксирует вскрытие запечатанных тендеров, у которых истёк срок
+// подачи предложений или которые закрыты. Вскрытие записывается в историю
+// как новая версия, чтобы его можно было проверить при аудите.
+func unseal(ctx context.Context, r repository.Repositories, now time.Time) error {
+	tenders, err := r.Tenders.Due(ctx, repository.DueUnseal, now)
+	if err != nil {
+		return err
+	}
+
+	for _, tender := range tenders {
+		history := models.NewTenderHistory(tender)
+		if err := r.Tenders.CreateHistory(ctx, &history); err != nil {
+			return err
+		}
+
+		unsealedAt := time.Now()
+		tender.UnsealedAt = &unsealedAt
+		tender.Version++
+		if err := r.Tenders.Save(ctx, &tender); err != nil {
+			return err
+		}
+
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:        audit.ActionUnseal,
+			EntityType:    audit.EntityTender,
+			EntityID:      tender.ID,
+			VersionBefore: history.Version,
+			VersionAfter:  tender.Version,
+		}); err != nil {
+			return err
+		}
+
+		log.Printf("Tender %s unsealed by scheduler", tender.ID)
+	}
+
+	return nil
+}
+
+// transition переводит тендеры, отобранные правилом due, в статус status
+// так же, как это делают обработчики: с записью версии в историю и
+// увеличением версии.
+func transition(ctx context.Context, r repository.Repositories, due repository.Due, status string, now time.Time) error {
+	tenders, err := r.Tenders.Due(ctx, due, now)
+	if err != nil {
+		return err
+	}
+
+	for _, tender := range tenders {
+		history := models.NewTenderHistory(tender)
+		if err := r.Tenders.CreateHistory(ctx, &history); err != nil {
+			return err
+		}
+
+		tender.Status = status
+		tender.Version++
+		if err := r.Tenders.Save(ctx, &tender); err != nil {
+			return err
+		}
+
+		if err := stream.TenderStatusChanged(ctx, r, tender, history.Status); err != nil {
+			return err
+		}
+		if err := notifications.TenderStatusChanged(ctx, r, tender, history.Status); err != nil {
+			return err
+		}
+		if err := webhooks.TenderStatusChanged(ctx, r, tender, history.Status); err != nil {
+			return err
+		}
+
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:        audit.ActionStatus,
+			EntityType:    audit.EntityTender,
+			EntityID:      tender.ID,
+			VersionBefore: history.Version,
+			VersionAfter:  tender.Version,
+		}); err != nil {
+			return err
+		}
+
+		log.Printf("Tender %s moved to %s by scheduler", tender.ID, status)
+	}
+
+	return nil
+}
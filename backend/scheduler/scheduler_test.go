@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRunOnce(t *testing.T) {
+	ctx := context.Background()
+	repositories := repository.NewMemory()
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	create := func(tender models.Tender) uuid.UUID {
+		t.Helper()
+		tender.Name = "Tender"
+		tender.OrganizationID = uuid.New()
+		if err := repositories.Tenders.Create(ctx, &tender); err != nil {
+			t.Fatal(err)
+		}
+		return tender.ID
+	}
+
+	publish := create(models.Tender{PublishAt: &past, SubmissionDeadline: &future})
+	pending := create(models.Tender{PublishAt: &future})
+	closeSealed := create(models.Tender{Status: models.TenderStatusPublished, SubmissionDeadline: &past, Sealed: true})
+
+	if err := RunOnce(ctx, repositories); err != nil {
+		t.Fatal(err)
+	}
+	// Повторный проход ничего не меняет.
+	if err := RunOnce(ctx, repositories); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id       uuid.UUID
+		status   string
+		version  int
+		unsealed bool
+	}{
+		{publish, models.TenderStatusPublished, 2, false},
+		{pending, models.TenderStatusCreated, 1, false},
+		{closeSealed, models.TenderStatusClosed, 3, true},
+	}
+	for _, tt := range tests {
+		tender, err := repositories.Tenders.FindByID(ctx, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tender.Status != tt.status || tender.Version != tt.version || (tender.UnsealedAt != nil) != tt.unsealed {
+			t.Errorf("tender %s: status %s, version %d, unsealed %v; want %s, %d, %v",
+				tt.id, tender.Status, tender.Version, tender.UnsealedAt != nil, tt.status, tt.version, tt.unsealed)
+		}
+
+		history, err := repositories.Tenders.ListHistory(ctx, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != tt.version-1 {
+			t.Errorf("tender %s: %d history records, want %d", tt.id, len(history), tt.version-1)
+		}
+	}
+
+	events, err := repositories.Audit.List(ctx, repository.AuditFilter{}, pagination.Page{Limit: pagination.MaxLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 3 {
+		t.Errorf("%d audit events, want 3", len(events.Items))
+	}
+}
@@ -1,29 +1,81 @@
 package schemas
 
 import (
+	"ZADANIE-6105/models"
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type TenderResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	ServiceType string    `json:"serviceType"`
-	Status      string    `json:"status"`
-	Version     int       `json:"version"`
-	CreatedAt   string    `json:"createdAt"`
+	ID                 uuid.UUID        `json:"id"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description"`
+	ServiceType        string           `json:"serviceType"`
+	Status             string           `json:"status"`
+	Version            int              `json:"version"`
+	MaxBudget          *decimal.Decimal `json:"maxBudget,omitempty"`
+	Currency           string           `json:"currency,omitempty"`
+	SubmissionDeadline *string          `json:"submissionDeadline,omitempty"`
+	PublishAt          *string          `json:"publishAt,omitempty"`
+	Sealed             bool             `json:"sealed"`
+	CreatedAt          string           `json:"createdAt"`
+	// Rank и Highlight заполняются только при полнотекстовом поиске (?q=).
+	Rank      *float64         `json:"rank,omitempty"`
+	Highlight *TenderHighlight `json:"highlight,omitempty"`
+}
+
+// TenderHighlight — фрагменты названия и описания с найденными словами,
+// обрамлёнными тегами <b></b>.
+type TenderHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TenderCreateRequest содержит только поля, которые задаёт клиент:
+// идентификатор, версию, статус и время вскрытия назначает сервер.
+type TenderCreateRequest struct {
+	Name               string           `json:"name" binding:"required,max=100"`
+	Description        string           `json:"description" binding:"required,max=500"`
+	ServiceType        string           `json:"serviceType" binding:"required,oneof=Construction Delivery Manufacture"`
+	OrganizationID     uuid.UUID        `json:"organizationId" binding:"required"`
+	CreatorUsername    string           `json:"creatorUsername"`
+	MaxBudget          *decimal.Decimal `json:"maxBudget"`
+	Currency           string           `json:"currency" binding:"omitempty,iso4217"`
+	SubmissionDeadline *time.Time       `json:"submissionDeadline"`
+	PublishAt          *time.Time       `json:"publishAt"`
+	Sealed             bool             `json:"sealed"`
 }
 
 type TenderUpdateRequest struct {
-	Name        *string `json:"name" binding:"omitempty,max=100"`
-	Description *string `json:"description" binding:"omitempty,max=500"`
-	ServiceType *string `json:"serviceType" binding:"omitempty,oneof=Construction Delivery Manufacture"`
+	Name               *string          `json:"name" binding:"omitempty,max=100"`
+	Description        *string          `json:"description" binding:"omitempty,max=500"`
+	ServiceType        *string          `json:"serviceType" binding:"omitempty,oneof=Construction Delivery Manufacture"`
+	MaxBudget          *decimal.Decimal `json:"maxBudget"`
+	Currency           *string          `json:"currency" binding:"omitempty,iso4217"`
+	SubmissionDeadline *time.Time       `json:"submissionDeadline"`
+	PublishAt          *time.Time       `json:"publishAt"`
+	Sealed             *bool            `json:"sealed"`
+}
+
+type TenderOwnersRequest struct {
+	Usernames []string `json:"usernames" binding:"max=50,dive,required"`
+}
+
+type TenderOwnerResponse struct {
+	UserID   uuid.UUID `json:"userId"`
+	Username string    `json:"username"`
+}
+
+type TenderCreatorTransferRequest struct {
+	Username string `json:"username" binding:"required"`
 }
 
 type RollbackTenderRequest struct {
 	TenderID      string `uri:"tenderId" binding:"required,max=100"`
 	TenderVersion int    `uri:"version" binding:"required,min=1"`
-	Username      string `form:"username" binding:"required"`
 }
 
 type CreateBidRequest struct {
@@ -36,26 +88,54 @@ type CreateBidRequest struct {
 }
 
 type BidCreateRequest struct {
-	Name        string `json:"name" binding:"required,max=100"`
-	Description string `json:"description" binding:"required,max=500"`
-	TenderID    string `json:"tenderId" binding:"required,uuid"`
-	AuthorType  string `json:"authorType" binding:"required,oneof=User Organization"`
-	AuthorID    string `json:"authorId" binding:"required,uuid"`
+	Name        string           `json:"name" binding:"required,max=100"`
+	Description string           `json:"description" binding:"required,max=500"`
+	TenderID    string           `json:"tenderId" binding:"required,uuid"`
+	AuthorType  string           `json:"authorType" binding:"required,oneof=User Organization"`
+	AuthorID    string           `json:"authorId" binding:"omitempty,uuid"`
+	Price       *decimal.Decimal `json:"price"`
+	Currency    string           `json:"currency" binding:"omitempty,iso4217"`
 }
 
 type BidCreateResponse struct {
-	ID         uuid.UUID `json:"id"`
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	AuthorType string    `json:"authorType"`
-	AuthorID   uuid.UUID `json:"authorId"`
-	Version    int       `json:"version"`
-	CreatedAt  string    `json:"createdAt"`
+	ID         uuid.UUID        `json:"id"`
+	Name       string           `json:"name"`
+	Status     string           `json:"status"`
+	AuthorType string           `json:"authorType"`
+	AuthorID   uuid.UUID        `json:"authorId"`
+	Version    int              `json:"version"`
+	Price      *decimal.Decimal `json:"price,omitempty"`
+	Currency   string           `json:"currency,omitempty"`
+	Selected   *bool            `json:"selected,omitempty"`
+	Sealed     bool             `json:"sealed,omitempty"`
+	CreatedAt  string           `json:"createdAt"`
+}
+
+func NewBidResponse(bid models.Bid) BidCreateResponse {
+	return BidCreateResponse{
+		ID:         bid.ID,
+		Name:       bid.Name,
+		Status:     bid.Status,
+		AuthorType: bid.AuthorType,
+		AuthorID:   bid.AuthorID,
+		Version:    bid.Version,
+		Price:      bid.Price,
+		Currency:   bid.Currency,
+		Selected:   bid.Selected,
+		CreatedAt:  bid.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
+type SealedBidsResponse struct {
+	Sealed bool  `json:"sealed"`
+	Count  int64 `json:"count"`
 }
 
 type BidEditRequest struct {
-	Name        string `json:"name" binding:"required,max=100"`
-	Description string `json:"description" binding:"required,max=500"`
+	Name        string           `json:"name" binding:"required,max=100"`
+	Description string           `json:"description" binding:"required,max=500"`
+	Price       *decimal.Decimal `json:"price"`
+	Currency    *string          `json:"currency" binding:"omitempty,iso4217"`
 }
 
 type BidReviewResponse struct {
@@ -63,3 +143,222 @@ type BidReviewResponse struct {
 	Description string    `json:"description"`
 	CreatedAt   string    `json:"createdAt"`
 }
+
+type EmployeeCreateRequest struct {
+	Username  string `json:"username" binding:"required,max=50"`
+	FirstName string `json:"firstName" binding:"max=50"`
+	LastName  string `json:"lastName" binding:"max=50"`
+	Email     string `json:"email" binding:"omitempty,email,max=255"`
+	Language  string `json:"language" binding:"omitempty,oneof=ru en"`
+	Password  string `json:"password" binding:"omitempty,min=8,max=72"`
+}
+
+type EmployeeUpdateRequest struct {
+	FirstName *string `json:"firstName" binding:"omitempty,max=50"`
+	LastName  *string `json:"lastName" binding:"omitempty,max=50"`
+	// Пустая строка удаляет адрес.
+	Email    *string `json:"email" binding:"omitempty,max=255"`
+	Language *string `json:"language" binding:"omitempty,oneof=ru en"`
+	Password *string `json:"password" binding:"omitempty,min=8,max=72"`
+}
+
+type EmployeeResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Email     *string   `json:"email,omitempty"`
+	Language  string    `json:"language"`
+	IsActive  bool      `json:"isActive"`
+	CreatedAt string    `json:"createdAt"`
+	UpdatedAt string    `json:"updatedAt"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+type BidDecisionVote struct {
+	ResponsibleID uuid.UUID `json:"responsibleId"`
+	Username      string    `json:"username"`
+	Decision      string    `json:"decision"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type BidDecisionsResponse struct {
+	BidID    uuid.UUID         `json:"bidId"`
+	Decision *string           `json:"decision"`
+	Quorum   int               `json:"quorum"`
+	Votes    []BidDecisionVote `json:"votes"`
+}
+
+type OrganizationCreateRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Type        string `json:"type" binding:"required,oneof=IE LLC JSC"`
+}
+
+type OrganizationUpdateRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	Type        *string `json:"type" binding:"omitempty,oneof=IE LLC JSC"`
+}
+
+type OrganizationResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	CreatedAt   string    `json:"createdAt"`
+	UpdatedAt   string    `json:"updatedAt"`
+}
+
+type ResponsibleAddRequest struct {
+	UserID   string `json:"userId" binding:"omitempty,uuid"`
+	Username string `json:"username" binding:"omitempty,max=50"`
+}
+
+type ResponsibleResponse struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	UserID         uuid.UUID `json:"userId"`
+	Username       string    `json:"username"`
+}
+
+type TenderCriterionRequest struct {
+	Name   string `json:"name" binding:"required,max=100"`
+	Weight int    `json:"weight" binding:"required,min=1,max=100"`
+}
+
+type TenderCriteriaRequest struct {
+	Criteria []TenderCriterionRequest `json:"criteria" binding:"required,max=20,dive"`
+}
+
+type TenderCriterionResponse struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Weight int       `json:"weight"`
+}
+
+type BidScoreRequest struct {
+	CriterionID string `json:"criterionId" binding:"required,uuid"`
+	Score       int    `json:"score" binding:"min=0,max=10"`
+	Comment     string `json:"comment" binding:"max=500"`
+}
+
+type BidScoresRequest struct {
+	Scores []BidScoreRequest `json:"scores" binding:"required,min=1,dive"`
+}
+
+type EvaluatorScore struct {
+	EvaluatorID uuid.UUID `json:"evaluatorId"`
+	Username    string    `json:"username"`
+	Score       int       `json:"score"`
+	Comment     string    `json:"comment,omitempty"`
+}
+
+type CriterionScore struct {
+	CriterionID uuid.UUID        `json:"criterionId"`
+	Name        string           `json:"name"`
+	Weight      int              `json:"weight"`
+	Average     *float64         `json:"average"`
+	Evaluations []EvaluatorScore `json:"evaluations"`
+}
+
+type BidRankingEntry struct {
+	Rank     int               `json:"rank"`
+	Bid      BidCreateResponse `json:"bid"`
+	Total    float64           `json:"total"`
+	Criteria []CriterionScore  `json:"criteria"`
+}
+
+type AttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	UploadedBy  uuid.UUID `json:"uploadedBy"`
+	CreatedAt   string    `json:"createdAt"`
+}
+
+// BidVersionResponse — полное состояние предложения в одной из версий.
+type BidVersionResponse struct {
+	BidCreateResponse
+	Description string    `json:"description"`
+	TenderID    uuid.UUID `json:"tenderId"`
+	Decision    *string   `json:"decision"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type VersionDiffResponse struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+type WebhookCreateRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=2000"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1,dive,required"`
+}
+
+// WebhookResponse — вебхук организации. Secret заполнен только в ответе
+// на создание.
+type WebhookResponse struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	URL            string    `json:"url"`
+	EventTypes     []string  `json:"eventTypes"`
+	Secret         string    `json:"secret,omitempty"`
+	CreatedAt      string    `json:"createdAt"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	WebhookID  uuid.UUID       `json:"webhookId"`
+	EventID    uuid.UUID       `json:"eventId"`
+	EventType  string          `json:"eventType"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	LastStatus int             `json:"lastStatus,omitempty"`
+	LastError  string          `json:"lastError,omitempty"`
+	DeadAt     *string         `json:"deadAt,omitempty"`
+	CreatedAt  string          `json:"createdAt"`
+}
+
+type NotificationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	TenderID  *uuid.UUID `json:"tenderId,omitempty"`
+	BidID     *uuid.UUID `json:"bidId,omitempty"`
+	Read      bool       `json:"read"`
+	ReadAt    *string    `json:"readAt,omitempty"`
+	CreatedAt string     `json:"createdAt"`
+}
+
+type NotificationPreference struct {
+	Type    string `json:"type" binding:"required"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+type NotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences" binding:"required,dive"`
+}
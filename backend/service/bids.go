@@ -0,0 +1,589 @@
+package service
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/notifications"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/stream"
+	"ZADANIE-6105/webhooks"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type BidService struct {
+	repositories repository.Repositories
+}
+
+// find загружает предложение вместе с его тендером.
+func find(ctx context.Context, repositories repository.Repositories, id uuid.UUID) (models.Bid, models.Tender, error) {
+	bid, err := repositories.Bids.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return bid, models.Tender{}, notFound("Bid not found")
+		}
+		return bid, models.Tender{}, err
+	}
+
+	tender, err := repositories.Tenders.FindByID(ctx, bid.TenderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return bid, tender, notFound("Tender not found")
+	}
+	return bid, tender, err
+}
+
+// lock загружает предложение и его тендер и блокирует их до конца
+// транзакции. Тендер блокируется первым, как и в остальных изменениях,
+// поэтому все изменения тендера и его предложений выполняются по очереди.
+func lock(ctx context.Context, repositories repository.Repositories, id uuid.UUID) (models.Bid, models.Tender, error) {
+	bid, _, err := find(ctx, repositories, id)
+	if err != nil {
+		return bid, models.Tender{}, err
+	}
+
+	tender, err := lockTender(ctx, repositories, bid.TenderID)
+	if err != nil {
+		return bid, tender, err
+	}
+
+	bid, err = repositories.Bids.FindForUpdate(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return bid, tender, notFound("Bid not found")
+	}
+	return bid, tender, err
+}
+
+// responsible проверяет, что сотрудник отвечает за организацию тендера.
+func responsible(ctx context.Context, repositories repository.Repositories, tender models.Tender, employee *models.Employee, reason string) error {
+	responsible, err := repositories.Organizations.IsResponsible(ctx, tender.OrganizationID, employee.ID)
+	if err != nil {
+		return err
+	}
+	if !responsible {
+		return forbidden(reason)
+	}
+	return nil
+}
+
+// change — аналог TenderService.change для предложений: изменение,
+// запись прежней версии в историю и увеличение версии в одной транзакции
+// под блокировкой тендера и предложения. authorize проверяет права
+// сотрудника уже под блокировкой, action записывается в журнал аудита.
+func (s *BidService) change(ctx context.Context, employee *models.Employee, id uuid.UUID, action string, authorize func(repositories repository.Repositories, bid models.Bid, tender models.Tender) error, check VersionCheck, fn func(repositories repository.Repositories, bid *models.Bid, tender models.Tender) error) (models.Bid, models.Tender, error) {
+	var bid models.Bid
+	var tender models.Tender
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		var err error
+		if bid, tender, err = lock(ctx, r, id); err != nil {
+			return err
+		}
+
+		if err := authorize(r, bid, tender); err != nil {
+			return err
+		}
+
+		if check != nil && !check(bid.Version) {
+			return ErrVersionMismatch
+		}
+
+		history := models.NewBidHistory(bid)
+
+		if err := fn(r, &bid, tender); err != nil {
+			return err
+		}
+
+		if err := r.Bids.CreateHistory(ctx, &history); err != nil {
+			return err
+		}
+
+		bid.Version = history.Version + 1
+		if err := r.Bids.Save(ctx, &bid); err != nil {
+			return err
+		}
+
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:        action,
+			EntityType:    audit.EntityBid,
+			EntityID:      bid.ID,
+			VersionBefore: history.Version,
+			VersionAfter:  bid.Version,
+			Actor:         employee,
+		}); err != nil {
+			return err
+		}
+
+		eventType := stream.EventBidEdited
+		if bid.Status != history.Status {
+			eventType = stream.EventBidStatus
+		}
+		return stream.BidChanged(ctx, r, eventType, bid, schemas.NewBidResponse(bid))
+	})
+	return bid, tender, err
+}
+
+// validatePrice проверяет цену предложения. Если у тендера задан
+// потолок бюджета, цена обязательна, должна быть в валюте тендера и
+// не превышать потолок.
+func validatePrice(price *decimal.Decimal, currency string, tender models.Tender) error {
+	if price != nil {
+		if !price.IsPositive() {
+			return invalid("price must be positive")
+		}
+		if !price.Equal(price.Round(2)) {
+			return invalid("price must have at most 2 decimal places")
+		}
+		if currency == "" {
+			return invalid("currency is required when price is set")
+		}
+	}
+
+	if tender.MaxBudget == nil {
+		return nil
+	}
+	if price == nil {
+		return invalid("price is required for tenders with maxBudget")
+	}
+	if currency != tender.Currency {
+		return invalid("price currency must match tender currency " + tender.Currency)
+	}
+	if price.GreaterThan(*tender.MaxBudget) {
+		return invalid("price exceeds tender maxBudget")
+	}
+	return nil
+}
+
+// AcceptingBids проверяет, что тендер принимает предложения: он
+// опубликован и срок подачи не истёк. Черновик и закрытый тендер
+// предложений не принимают, даже если срок ещё не наступил.
+func AcceptingBids(tender models.Tender) error {
+	if tender.Status != models.TenderStatusPublished {
+		return invalid("Tender is not accepting bids")
+	}
+	if tender.SubmissionClosed(time.Now()) {
+		return invalid("Submission deadline for this tender has passed")
+	}
+	return nil
+}
+
+// Create подаёт предложение от имени сотрудника. Предложение от
+// организации может подать только ответственный за неё.
+func (s *BidService) Create(ctx context.Context, employee *models.Employee, input schemas.BidCreateRequest) (models.Bid, error) {
+	tenderID, err := uuid.Parse(input.TenderID)
+	if err != nil {
+		return models.Bid{}, invalid("Invalid TenderID format")
+	}
+
+	bid := models.Bid{
+		Name:        input.Name,
+		Description: input.Description,
+		TenderID:    tenderID,
+		AuthorType:  input.AuthorType,
+		AuthorID:    employee.ID,
+		Price:       input.Price,
+		Currency:    input.Currency,
+		Version:     1,
+		Status:      "Created",
+		CreatedAt:   time.Now(),
+	}
+
+	// Тендер блокируется, чтобы предложение не появилось у тендера,
+	// который параллельно закрывается.
+	err = s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		tender, err := r.Tenders.FindForUpdate(ctx, tenderID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return invalid("Tender with the given ID does not exist")
+			}
+			return err
+		}
+
+		if err := AcceptingBids(tender); err != nil {
+			return err
+		}
+
+		if err := validatePrice(input.Price, input.Currency, tender); err != nil {
+			return err
+		}
+
+		if input.AuthorType == "Organization" {
+			if err := responsible(ctx, r, tender, employee, "Unauthorized to create bid as Organization"); err != nil {
+				return err
+			}
+		}
+
+		if err := r.Bids.Create(ctx, &bid); err != nil {
+			return err
+		}
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:       audit.ActionCreate,
+			EntityType:   audit.EntityBid,
+			EntityID:     bid.ID,
+			VersionAfter: bid.Version,
+			Actor:        employee,
+		}); err != nil {
+			return err
+		}
+		if err := stream.BidChanged(ctx, r, stream.EventBidCreated, bid, schemas.NewBidResponse(bid)); err != nil {
+			return err
+		}
+		if err := notifications.BidCreated(ctx, r, bid, tender); err != nil {
+			return err
+		}
+		return webhooks.BidCreated(ctx, r, bid, tender)
+	})
+	return bid, err
+}
+
+// ChangeStatus переводит предложение в статус status. Менять статус
+// могут автор и ответственные за организацию тендера и только пока
+// тендер принимает предложения. Вместе с предложением возвращается его
+// тендер.
+func (s *BidService) ChangeStatus(ctx context.Context, employee *models.Employee, id uuid.UUID, status string, check VersionCheck) (models.Bid, models.Tender, error) {
+	switch status {
+	case "":
+		return models.Bid{}, models.Tender{}, invalid("Status is required")
+	case "Created", "Published", "Canceled":
+	default:
+		return models.Bid{}, models.Tender{}, invalid("Invalid status value")
+	}
+
+	authorize := func(r repository.Repositories, bid models.Bid, tender models.Tender) error {
+		if bid.AuthorID != employee.ID {
+			if err := responsible(ctx, r, tender, employee, "User is not authorized to update this bid"); err != nil {
+				return err
+			}
+		}
+		return AcceptingBids(tender)
+	}
+
+	return s.change(ctx, employee, id, audit.ActionStatus, authorize, check, func(r repository.Repositories, bid *models.Bid, tender models.Tender) error {
+		bid.Status = status
+		return nil
+	})
+}
+
+// Edit меняет предложение. Править его может только автор и только пока
+// тендер принимает предложения.
+func (s *BidService) Edit(ctx context.Context, employee *models.Employee, id uuid.UUID, input schemas.BidEditRequest, check VersionCheck) (models.Bid, error) {
+	authorize := func(r repository.Repositories, bid models.Bid, tender models.Tender) error {
+		if bid.AuthorID != employee.ID {
+			return forbidden("User is not authorized to edit this bid")
+		}
+		return AcceptingBids(tender)
+	}
+
+	bid, _, err := s.change(ctx, employee, id, audit.ActionEdit, authorize, check, func(r repository.Repositories, bid *models.Bid, tender models.Tender) error {
+		if input.Price != nil {
+			bid.Price = input.Price
+		}
+		if input.Currency != nil {
+			bid.Currency = *input.Currency
+		}
+		if err := validatePrice(bid.Price, bid.Currency, tender); err != nil {
+			return err
+		}
+
+		bid.Name = input.Name
+		bid.Description = input.Description
+		return nil
+	})
+	return bid, err
+}
+
+// Rollback восстанавливает предложение из версии version. Откатывать
+// может только автор и только пока тендер принимает предложения.
+// Решение по предложению и время подачи откат не меняет: решение
+// принимают ответственные, а не автор.
+func (s *BidService) Rollback(ctx context.Context, employee *models.Employee, id uuid.UUID, version int, check VersionCheck) (models.Bid, error) {
+	authorize := func(r repository.Repositories, bid models.Bid, tender models.Tender) error {
+		if bid.AuthorID != employee.ID {
+			return forbidden("User is not authorized to rollback this bid")
+		}
+		return AcceptingBids(tender)
+	}
+
+	bid, _, err := s.change(ctx, employee, id, audit.ActionRollback, authorize, check, func(r repository.Repositories, bid *models.Bid, tender models.Tender) error {
+		history, err := r.Bids.FindHistory(ctx, bid.ID, version)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("Version not found in history")
+		}
+		if err != nil {
+			return err
+		}
+
+		bid.Name = history.Name
+		bid.Description = history.Description
+		bid.Status = history.Status
+		bid.TenderID = history.TenderID
+		bid.AuthorType = history.AuthorType
+		bid.AuthorID = history.AuthorID
+		bid.Price = history.Price
+		bid.Currency = history.Currency
+		// Бюджет тендера мог измениться с тех пор, как была сохранена версия.
+		return validatePrice(bid.Price, bid.Currency, tender)
+	})
+	return bid, err
+}
+
+// SubmitDecision записывает голос ответственного за организацию и
+// пересчитывает итоговое решение. Согласованное предложение становится
+// победителем, а тендер закрывается; тендер возвращается в новом
+// состоянии.
+func (s *BidService) SubmitDecision(ctx context.Context, employee *models.Employee, id uuid.UUID, decision string) (models.Bid, models.Tender, error) {
+	if decision != models.DecisionApproved && decision != models.DecisionRejected {
+		return models.Bid{}, models.Tender{}, invalid("Invalid decision value")
+	}
+
+	var bid models.Bid
+	var tender models.Tender
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		// Блокировка тендера сериализует решения по всем его предложениям,
+		// чтобы у тендера не оказалось двух победителей.
+		var err error
+		if bid, tender, err = lock(ctx, r, id); err != nil {
+			return err
+		}
+
+		if err := responsible(ctx, r, tender, employee, "User is not authorized to submit decision for this bid"); err != nil {
+			return err
+		}
+
+		// Черновик и отозванное предложение не рассматриваются.
+		if bid.Status != "Published" {
+			return invalid("Only published bids can be decided")
+		}
+
+		if tender.IsSealed(time.Now()) {
+			return invalid("Bids are sealed until the submission deadline")
+		}
+
+		// Тендер может быть уже закрыт по сроку, но победитель у него один.
+		if _, err := r.Bids.Winner(ctx, tender.ID); err == nil {
+			return invalid("Tender already has an approved bid")
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		// Повторный голос того же ответственного заменяет предыдущий.
+		vote := models.BidDecision{
+			BidID:         bid.ID,
+			ResponsibleID: employee.ID,
+			Decision:      decision,
+		}
+		if err := r.Bids.SaveVote(ctx, &vote); err != nil {
+			return err
+		}
+
+		aggregate, _, err := aggregateDecision(ctx, r, bid.ID, tender.OrganizationID)
+		if err != nil {
+			return err
+		}
+
+		// Изменение итогового решения и выбор победителя меняют версию
+		// предложения, как и правки автора.
+		history := models.NewBidHistory(bid)
+		previousDecision, previousSelected := bid.Decision, bid.Selected
+		bid.Decision = aggregate
+		if aggregate != nil && *aggregate == models.DecisionApproved {
+			if err := closeTenderWithWinner(ctx, r, employee, &tender, &bid); err != nil {
+				return err
+			}
+		}
+		if !equal(previousDecision, bid.Decision) || !equal(previousSelected, bid.Selected) {
+			if err := r.Bids.CreateHistory(ctx, &history); err != nil {
+				return err
+			}
+			bid.Version = history.Version + 1
+		}
+		if err := r.Bids.Save(ctx, &bid); err != nil {
+			return err
+		}
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:        audit.ActionDecision,
+			EntityType:    audit.EntityBid,
+			EntityID:      bid.ID,
+			VersionBefore: history.Version,
+			VersionAfter:  bid.Version,
+			Actor:         employee,
+		}); err != nil {
+			return err
+		}
+		if err := notifications.BidDecided(ctx, r, bid, tender, previousDecision); err != nil {
+			return err
+		}
+		return webhooks.BidDecided(ctx, r, bid, tender, previousDecision)
+	})
+	return bid, tender, err
+}
+
+// equal сравнивает необязательные значения: nil равен только nil.
+func equal[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// closeTenderWithWinner закрывает тендер после согласования предложения:
+// сохраняет версию тендера в истории, переводит его в Closed (если он ещё
+// не закрыт по сроку) и помечает согласованное предложение выбранным,
+// а остальные — невыбранными. Версии остальных предложений, у которых
+// изменилась отметка, увеличиваются здесь же; победителя сохраняет
+// вызывающий.
+// Должна вызываться внутри транзакции, в которой тендер заблокирован.
+func closeTenderWithWinner(ctx context.Context, repositories repository.Repositories, employee *models.Employee, tender *models.Tender, winner *models.Bid) error {
+	if tender.Status != models.TenderStatusClosed {
+		history := models.NewTenderHistory(*tender)
+		if err := repositories.Tenders.CreateHistory(ctx, &history); err != nil {
+			return err
+		}
+
+		tender.Status = models.TenderStatusClosed
+		tender.Version++
+		if err := repositories.Tenders.Save(ctx, tender); err != nil {
+			return err
+		}
+
+		if err := audit.Write(ctx, repositories, audit.Event{
+			Action:        audit.ActionStatus,
+			EntityType:    audit.EntityTender,
+			EntityID:      tender.ID,
+			VersionBefore: history.Version,
+			VersionAfter:  tender.Version,
+			Actor:         employee,
+		}); err != nil {
+			return err
+		}
+
+		if err := tenderStatusChanged(ctx, repositories, *tender, history.Status); err != nil {
+			return err
+		}
+	}
+
+	bids, err := repositories.Bids.ListByTender(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+	for _, bid := range bids {
+		if bid.ID == winner.ID || (bid.Selected != nil && !*bid.Selected) {
+			continue
+		}
+		history := models.NewBidHistory(bid)
+		if err := repositories.Bids.CreateHistory(ctx, &history); err != nil {
+			return err
+		}
+		selected := false
+		bid.Selected = &selected
+		bid.Version = history.Version + 1
+		if err := repositories.Bids.Save(ctx, &bid); err != nil {
+			return err
+		}
+	}
+
+	selected := true
+	winner.Selected = &selected
+	return nil
+}
+
+// AggregateDecision возвращает итоговое решение по предложению и кворум.
+// Пока кворум не набран, решение nil.
+func (s *BidService) AggregateDecision(ctx context.Context, bid models.Bid, tender models.Tender) (*string, int, error) {
+	return aggregateDecision(ctx, s.repositories, bid.ID, tender.OrganizationID)
+}
+
+// aggregateDecision вычисляет итоговое решение по голосам ответственных:
+// любой Rejected отклоняет предложение, для согласования нужно не меньше
+// min(3, число ответственных организации) голосов Approved. Учитываются
+// только голоса тех, кто сейчас отвечает за организацию и не
+// деактивирован: голоса ушедших сотрудников сохраняются, но не считаются.
+func aggregateDecision(ctx context.Context, repositories repository.Repositories, bidID uuid.UUID, organizationID uuid.UUID) (*string, int, error) {
+	responsibles, err := repositories.Organizations.Responsibles(ctx, organizationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	active := make(map[uuid.UUID]bool, len(responsibles))
+	for _, responsible := range responsibles {
+		if responsible.IsActive() {
+			active[responsible.ID] = true
+		}
+	}
+	quorum := min(3, len(active))
+
+	votes, err := repositories.Bids.Votes(ctx, bidID)
+	if err != nil {
+		return nil, quorum, err
+	}
+
+	approvals := 0
+	for _, vote := range votes {
+		if !active[vote.ResponsibleID] {
+			continue
+		}
+		if vote.Decision == models.DecisionRejected {
+			decision := models.DecisionRejected
+			return &decision, quorum, nil
+		}
+		approvals++
+	}
+
+	if quorum > 0 && approvals >= quorum {
+		decision := models.DecisionApproved
+		return &decision, quorum, nil
+	}
+
+	return nil, quorum, nil
+}
+
+// SendFeedback оставляет отзыв ответственного за организацию на
+// предложение. Пока тендер запечатан, отзывы не принимаются.
+func (s *BidService) SendFeedback(ctx context.Context, employee *models.Employee, id uuid.UUID, feedback string) (models.Bid, error) {
+	if feedback == "" {
+		return models.Bid{}, invalid("Feedback is required")
+	}
+	if len(feedback) > 1000 {
+		return models.Bid{}, invalid("Feedback exceeds maximum length of 1000 characters")
+	}
+
+	var bid models.Bid
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		var tender models.Tender
+		var err error
+		if bid, tender, err = find(ctx, r, id); err != nil {
+			return err
+		}
+
+		if err := responsible(ctx, r, tender, employee, "User is not authorized to submit feedback for this bid"); err != nil {
+			return err
+		}
+
+		if tender.IsSealed(time.Now()) {
+			return invalid("Bids are sealed until the submission deadline")
+		}
+
+		record := models.BidFeedback{
+			BidID:     bid.ID,
+			Feedback:  feedback,
+			AuthorID:  employee.ID,
+			CreatedAt: time.Now(),
+		}
+		if err := r.Bids.CreateFeedback(ctx, &record); err != nil {
+			return err
+		}
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:     audit.ActionFeedback,
+			EntityType: audit.EntityBid,
+			EntityID:   bid.ID,
+			Actor:      employee,
+		}); err != nil {
+			return err
+		}
+		return notifications.BidFeedback(ctx, r, bid, tender, feedback)
+	})
+	return bid, err
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EvaluationService struct {
+	repositories repository.Repositories
+}
+
+// SetCriteria заменяет критерии оценки тендера. После появления первых
+// оценок критерии менять нельзя, чтобы не потерять оценки. Оценки
+// считаются под блокировкой тендера, которую берёт и Score, поэтому
+// оценка не может появиться между проверкой и заменой.
+func (s *EvaluationService) SetCriteria(ctx context.Context, employee *models.Employee, tenderID uuid.UUID, input []schemas.TenderCriterionRequest) ([]models.TenderCriterion, error) {
+	criteria := make([]models.TenderCriterion, 0, len(input))
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		tender, err := lockTender(ctx, r, tenderID)
+		if err != nil {
+			return err
+		}
+
+		if err := manageable(ctx, r, tender, employee, "Unauthorized to update this tender"); err != nil {
+			return err
+		}
+
+		scored, err := r.Evaluations.CountScores(ctx, tender.ID)
+		if err != nil {
+			return err
+		}
+		if scored > 0 {
+			return invalid("Criteria cannot be changed after bids were scored")
+		}
+
+		for _, criterion := range input {
+			criteria = append(criteria, models.TenderCriterion{
+				TenderID: tender.ID,
+				Name:     criterion.Name,
+				Weight:   criterion.Weight,
+			})
+		}
+		if err := r.Evaluations.ReplaceCriteria(ctx, tender.ID, criteria); err != nil {
+			return err
+		}
+		return audit.Write(ctx, r, audit.Event{
+			Action:     audit.ActionCriteria,
+			EntityType: audit.EntityTender,
+			EntityID:   tender.ID,
+			Actor:      employee,
+		})
+	})
+	return criteria, err
+}
+
+// Score сохраняет оценки ответственного по критериям тендера. Повторная
+// оценка по тому же критерию заменяет предыдущую; один критерий в
+// запросе можно указать только один раз.
+func (s *EvaluationService) Score(ctx context.Context, employee *models.Employee, bidID uuid.UUID, input []schemas.BidScoreRequest) error {
+	return s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		bid, tender, err := lock(ctx, r, bidID)
+		if err != nil {
+			return err
+		}
+
+		if err := responsible(ctx, r, tender, employee, "User is not authorized to score this bid"); err != nil {
+			return err
+		}
+
+		if tender.IsSealed(time.Now()) {
+			return invalid("Bids are sealed until the submission deadline")
+		}
+
+		criteria, err := r.Evaluations.Criteria(ctx, tender.ID)
+		if err != nil {
+			return err
+		}
+		known := make(map[uuid.UUID]bool, len(criteria))
+		for _, criterion := range criteria {
+			known[criterion.ID] = true
+		}
+
+		scored := make(map[uuid.UUID]bool, len(input))
+		scores := make([]models.BidScore, 0, len(input))
+		for _, score := range input {
+			criterionID, err := uuid.Parse(score.CriterionID)
+			if err != nil || !known[criterionID] {
+				return invalid("Criterion " + score.CriterionID + " does not belong to the tender")
+			}
+			if scored[criterionID] {
+				return invalid("Criterion " + score.CriterionID + " is scored more than once")
+			}
+			scored[criterionID] = true
+
+			scores = append(scores, models.BidScore{
+				BidID:       bid.ID,
+				CriterionID: criterionID,
+				EvaluatorID: employee.ID,
+				Score:       score.Score,
+				Comment:     score.Comment,
+			})
+		}
+
+		if err := r.Evaluations.SaveScores(ctx, scores); err != nil {
+			return err
+		}
+		return audit.Write(ctx, r, audit.Event{
+			Action:     audit.ActionScore,
+			EntityType: audit.EntityBid,
+			EntityID:   bid.ID,
+			Actor:      employee,
+		})
+	})
+}
@@ -0,0 +1,70 @@
+// Package service содержит правила работы с тендерами и предложениями:
+// кто может их менять, как растут версии, как принимаются решения.
+// Обработчики HTTP только разбирают запрос и переводят ошибки сервисов
+// в ответ, поэтому те же сервисы можно вызывать из других транспортов.
+package service
+
+import (
+	"ZADANIE-6105/repository"
+	"errors"
+)
+
+// Виды ошибок сервисов. Конкретная ошибка — *Error с сообщением для
+// клиента; проверять её вид нужно через errors.Is.
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrForbidden = errors.New("forbidden")
+	ErrConflict  = errors.New("conflict")
+	ErrInvalid   = errors.New("invalid request")
+)
+
+// ErrVersionMismatch возвращается, если версия сущности не прошла
+// VersionCheck.
+var ErrVersionMismatch = &Error{Kind: ErrConflict, Reason: "Resource was modified by another request"}
+
+// Error — нарушение правила. Reason можно показать клиенту.
+type Error struct {
+	Kind   error
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return e.Reason
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func notFound(reason string) error {
+	return &Error{Kind: ErrNotFound, Reason: reason}
+}
+
+func forbidden(reason string) error {
+	return &Error{Kind: ErrForbidden, Reason: reason}
+}
+
+func invalid(reason string) error {
+	return &Error{Kind: ErrInvalid, Reason: reason}
+}
+
+// VersionCheck проверяет текущую версию сущности перед изменением,
+// например против If-Match. nil разрешает любую версию.
+type VersionCheck func(version int) bool
+
+// Services — сервисы поверх одного хранилища.
+type Services struct {
+	Tenders     *TenderService
+	Bids        *BidService
+	Evaluations *EvaluationService
+}
+
+// New создаёт сервисы. Изменения выполняются в repositories.Transaction,
+// поэтому сервисы работают и с Postgres, и с хранилищем в памяти.
+func New(repositories repository.Repositories) Services {
+	return Services{
+		Tenders:     &TenderService{repositories: repositories},
+		Bids:        &BidService{repositories: repositories},
+		Evaluations: &EvaluationService{repositories: repositories},
+	}
+}
@@ -0,0 +1,969 @@
+package service
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/stream"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var ctx = context.Background()
+
+func pointer[T any](value T) *T {
+	return &value
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectError(t *testing.T, err error, kind error) {
+	t.Helper()
+	if !errors.Is(err, kind) {
+		t.Fatalf("expected %v, got %v", kind, err)
+	}
+}
+
+// fixture — организация с ответственными alice и bob, сторонний
+// сотрудник carol и опубликованный тендер организации.
+type fixture struct {
+	repositories repository.Repositories
+	services     Services
+
+	organization models.Organization
+	alice        models.Employee
+	bob          models.Employee
+	carol        models.Employee
+	tender       models.Tender
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	repositories := repository.NewMemory()
+	f := &fixture{
+		repositories: repositories,
+		services:     New(repositories),
+		organization: models.Organization{Name: "Alpha"},
+		alice:        models.Employee{Username: "alice"},
+		bob:          models.Employee{Username: "bob"},
+		carol:        models.Employee{Username: "carol"},
+	}
+
+	check(t, repositories.Organizations.Create(ctx, &f.organization))
+	for _, employee := range []*models.Employee{&f.alice, &f.bob, &f.carol} {
+		check(t, repositories.Employees.Create(ctx, employee))
+	}
+	for _, employee := range []models.Employee{f.alice, f.bob} {
+		check(t, repositories.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{
+			OrganizationID: f.organization.ID,
+			UserID:         employee.ID,
+		}))
+	}
+
+	f.tender = f.createTender(t, models.Tender{
+		Name:        "Bridge",
+		Description: "Build a bridge",
+		ServiceType: "Construction",
+		Status:      models.TenderStatusPublished,
+	})
+	return f
+}
+
+func (f *fixture) createTender(t *testing.T, tender models.Tender) models.Tender {
+	t.Helper()
+	tender.OrganizationID = f.organization.ID
+	tender.CreatorUsername = f.alice.Username
+	check(t, f.repositories.Tenders.Create(ctx, &tender))
+	return tender
+}
+
+func (f *fixture) createBid(t *testing.T, author models.Employee, name string) models.Bid {
+	t.Helper()
+	bid, err := f.services.Bids.Create(ctx, &author, schemas.BidCreateRequest{
+		Name:        name,
+		Description: name,
+		TenderID:    f.tender.ID.String(),
+		AuthorType:  "User",
+	})
+	check(t, err)
+	return bid
+}
+
+// publishBid публикует предложение от имени автора: решение принимается
+// только по опубликованным предложениям.
+func (f *fixture) publishBid(t *testing.T, author models.Employee, bid models.Bid) models.Bid {
+	t.Helper()
+	bid, _, err := f.services.Bids.ChangeStatus(ctx, &author, bid.ID, "Published", nil)
+	check(t, err)
+	return bid
+}
+
+func (f *fixture) events(t *testing.T, tenderID uuid.UUID) []string {
+	t.Helper()
+	events, err := f.repositories.Events.After(ctx, tenderID, 0, 100)
+	check(t, err)
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func (f *fixture) notifications(t *testing.T, employee models.Employee) []models.Notification {
+	t.Helper()
+	result, err := f.repositories.Notifications.List(ctx, employee.ID, repository.NotificationFilter{}, pagination.Page{Limit: pagination.MaxLimit})
+	check(t, err)
+	return result.Items
+}
+
+// actions возвращает действия из журнала аудита, старые первыми.
+func (f *fixture) actions(t *testing.T, entityID uuid.UUID) []string {
+	t.Helper()
+	result, err := f.repositories.Audit.List(ctx, repository.AuditFilter{EntityID: &entityID}, pagination.Page{Limit: pagination.MaxLimit})
+	check(t, err)
+	actions := make([]string, 0, len(result.Items))
+	for i := len(result.Items) - 1; i >= 0; i-- {
+		actions = append(actions, result.Items[i].Action)
+	}
+	return actions
+}
+
+func TestTenderUpdate(t *testing.T) {
+	f := newFixture(t)
+	update := schemas.TenderUpdateRequest{Name: pointer("Bridge 2")}
+
+	_, err := f.services.Tenders.Update(ctx, &f.carol, f.tender.ID, update, nil)
+	expectError(t, err, ErrForbidden)
+
+	_, err = f.services.Tenders.Update(ctx, &f.alice, uuid.New(), update, nil)
+	expectError(t, err, ErrNotFound)
+
+	_, err = f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, update, func(version int) bool { return version == 2 })
+	expectError(t, err, ErrConflict)
+
+	tender, err := f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, update, func(version int) bool { return version == 1 })
+	check(t, err)
+	if tender.Name != "Bridge 2" || tender.Version != 2 {
+		t.Fatalf("unexpected tender %q version %d", tender.Name, tender.Version)
+	}
+
+	history, err := f.repositories.Tenders.FindHistory(ctx, f.tender.ID, 1)
+	check(t, err)
+	if history.Name != "Bridge" {
+		t.Fatalf("history keeps %q", history.Name)
+	}
+
+	stored, err := f.repositories.Tenders.FindByID(ctx, f.tender.ID)
+	check(t, err)
+	if stored.Name != "Bridge 2" || stored.Version != 2 {
+		t.Fatalf("stored tender %q version %d", stored.Name, stored.Version)
+	}
+}
+
+func TestTenderUpdateRejectedChangesNothing(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, schemas.TenderUpdateRequest{
+		MaxBudget: pointer(decimal.NewFromInt(-1)),
+	}, nil)
+	expectError(t, err, ErrInvalid)
+
+	stored, err := f.repositories.Tenders.FindByID(ctx, f.tender.ID)
+	check(t, err)
+	if stored.Version != 1 || stored.MaxBudget != nil {
+		t.Fatalf("rejected update was saved: version %d", stored.Version)
+	}
+	history, err := f.repositories.Tenders.ListHistory(ctx, f.tender.ID)
+	check(t, err)
+	if len(history) != 0 {
+		t.Fatalf("rejected update wrote %d history rows", len(history))
+	}
+}
+
+func TestTenderConcurrentUpdates(t *testing.T) {
+	f := newFixture(t)
+	const updates = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, schemas.TenderUpdateRequest{
+				Description: pointer(uuid.NewString()),
+			}, nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		check(t, err)
+	}
+
+	stored, err := f.repositories.Tenders.FindByID(ctx, f.tender.ID)
+	check(t, err)
+	if stored.Version != updates+1 {
+		t.Fatalf("version %d, want %d", stored.Version, updates+1)
+	}
+
+	history, err := f.repositories.Tenders.ListHistory(ctx, f.tender.ID)
+	check(t, err)
+	seen := make(map[int]bool)
+	for _, row := range history {
+		seen[row.Version] = true
+	}
+	for version := 1; version <= updates; version++ {
+		if !seen[version] {
+			t.Fatalf("history has no version %d", version)
+		}
+	}
+	if len(history) != updates {
+		t.Fatalf("history has %d rows, want %d", len(history), updates)
+	}
+}
+
+func TestTenderChangeStatus(t *testing.T) {
+	f := newFixture(t)
+	draft := f.createTender(t, models.Tender{Name: "Depot", Description: "Depot", ServiceType: "Delivery"})
+
+	_, err := f.services.Tenders.ChangeStatus(ctx, &f.alice, draft.ID, "Archived", nil)
+	expectError(t, err, ErrInvalid)
+
+	tender, err := f.services.Tenders.ChangeStatus(ctx, &f.alice, draft.ID, models.TenderStatusPublished, nil)
+	check(t, err)
+	if tender.Status != models.TenderStatusPublished || tender.Version != 2 {
+		t.Fatalf("status %s version %d", tender.Status, tender.Version)
+	}
+
+	if events := f.events(t, draft.ID); len(events) != 1 || events[0] != stream.EventTenderStatus {
+		t.Fatalf("events %v", events)
+	}
+	// Публикация уведомляет всех ответственных, включая того, кто её выполнил.
+	for _, employee := range []models.Employee{f.alice, f.bob} {
+		if notifications := f.notifications(t, employee); len(notifications) != 1 {
+			t.Fatalf("%s has %d notifications", employee.Username, len(notifications))
+		}
+	}
+}
+
+func TestTenderRollback(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, schemas.TenderUpdateRequest{Name: pointer("Bridge 2")}, nil)
+	check(t, err)
+
+	_, err = f.services.Tenders.Rollback(ctx, &f.alice, f.tender.ID, 7, nil)
+	expectError(t, err, ErrNotFound)
+
+	_, err = f.services.Tenders.Rollback(ctx, &f.carol, f.tender.ID, 1, nil)
+	expectError(t, err, ErrForbidden)
+
+	tender, err := f.services.Tenders.Rollback(ctx, &f.alice, f.tender.ID, 1, nil)
+	check(t, err)
+	if tender.Name != "Bridge" || tender.Version != 3 {
+		t.Fatalf("rolled back to %q version %d", tender.Name, tender.Version)
+	}
+}
+
+func TestBidCreate(t *testing.T) {
+	f := newFixture(t)
+	closed := f.createTender(t, models.Tender{
+		Name:               "Canteen",
+		Description:        "Canteen",
+		ServiceType:        "Delivery",
+		Status:             models.TenderStatusPublished,
+		SubmissionDeadline: pointer(time.Now().Add(-time.Hour)),
+	})
+	draft := f.createTender(t, models.Tender{
+		Name:               "Depot",
+		Description:        "Depot",
+		ServiceType:        "Delivery",
+		SubmissionDeadline: pointer(time.Now().Add(time.Hour)),
+	})
+	budget := f.createTender(t, models.Tender{
+		Name:        "Asphalt",
+		Description: "Asphalt",
+		ServiceType: "Construction",
+		Status:      models.TenderStatusPublished,
+		MaxBudget:   pointer(decimal.NewFromInt(100)),
+		Currency:    "RUB",
+	})
+
+	tests := []struct {
+		name     string
+		employee models.Employee
+		input    schemas.BidCreateRequest
+		kind     error
+	}{
+		{
+			name:     "unknown tender",
+			employee: f.carol,
+			input:    schemas.BidCreateRequest{TenderID: uuid.NewString(), AuthorType: "User"},
+			kind:     ErrInvalid,
+		},
+		{
+			name:     "draft tender",
+			employee: f.carol,
+			input:    schemas.BidCreateRequest{TenderID: draft.ID.String(), AuthorType: "User"},
+			kind:     ErrInvalid,
+		},
+		{
+			name:     "deadline passed",
+			employee: f.carol,
+			input:    schemas.BidCreateRequest{TenderID: closed.ID.String(), AuthorType: "User"},
+			kind:     ErrInvalid,
+		},
+		{
+			name:     "price over budget",
+			employee: f.carol,
+			input: schemas.BidCreateRequest{
+				TenderID:   budget.ID.String(),
+				AuthorType: "User",
+				Price:      pointer(decimal.NewFromInt(101)),
+				Currency:   "RUB",
+			},
+			kind: ErrInvalid,
+		},
+		{
+			name:     "organization bid by outsider",
+			employee: f.carol,
+			input:    schemas.BidCreateRequest{TenderID: f.tender.ID.String(), AuthorType: "Organization"},
+			kind:     ErrForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.input.Name = "Bid"
+			test.input.Description = "Bid"
+			_, err := f.services.Bids.Create(ctx, &test.employee, test.input)
+			expectError(t, err, test.kind)
+		})
+	}
+
+	count, err := f.repositories.Bids.Count(ctx, repository.BidFilter{})
+	check(t, err)
+	if count != 0 {
+		t.Fatalf("rejected bids were saved: %d", count)
+	}
+
+	bid := f.createBid(t, f.carol, "Road")
+	if bid.Version != 1 || bid.AuthorID != f.carol.ID {
+		t.Fatalf("unexpected bid %+v", bid)
+	}
+	if events := f.events(t, f.tender.ID); len(events) != 1 || events[0] != stream.EventBidCreated {
+		t.Fatalf("events %v", events)
+	}
+	if notifications := f.notifications(t, f.alice); len(notifications) != 1 {
+		t.Fatalf("alice has %d notifications", len(notifications))
+	}
+}
+
+func TestEventsAreNumberedPerTender(t *testing.T) {
+	f := newFixture(t)
+	other := f.createTender(t, models.Tender{Name: "Canteen", Status: models.TenderStatusPublished})
+	const bids = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*bids)
+	for i := 0; i < bids; i++ {
+		for _, tenderID := range []uuid.UUID{f.tender.ID, other.ID} {
+			wg.Add(1)
+			go func(tenderID uuid.UUID) {
+				defer wg.Done()
+				_, err := f.services.Bids.Create(ctx, &f.carol, schemas.BidCreateRequest{
+					Name:        "Offer",
+					Description: "Offer",
+					TenderID:    tenderID.String(),
+					AuthorType:  "User",
+				})
+				errs <- err
+			}(tenderID)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		check(t, err)
+	}
+
+	for _, tenderID := range []uuid.UUID{f.tender.ID, other.ID} {
+		events, err := f.repositories.Events.After(ctx, tenderID, 0, 100)
+		check(t, err)
+		if len(events) != bids {
+			t.Fatalf("tender has %d events, want %d", len(events), bids)
+		}
+		for i, event := range events {
+			if event.Seq != int64(i+1) {
+				t.Fatalf("event %d has seq %d", i, event.Seq)
+			}
+		}
+	}
+}
+
+func TestBidEdit(t *testing.T) {
+	f := newFixture(t)
+	bid := f.createBid(t, f.carol, "Road")
+	input := schemas.BidEditRequest{Name: "Road 2", Description: "Road 2"}
+
+	_, err := f.services.Bids.Edit(ctx, &f.alice, bid.ID, input, nil)
+	expectError(t, err, ErrForbidden)
+
+	edited, err := f.services.Bids.Edit(ctx, &f.carol, bid.ID, input, nil)
+	check(t, err)
+	if edited.Name != "Road 2" || edited.Version != 2 {
+		t.Fatalf("edited to %q version %d", edited.Name, edited.Version)
+	}
+
+	history, err := f.repositories.Bids.FindHistory(ctx, bid.ID, 1)
+	check(t, err)
+	if history.Name != "Road" {
+		t.Fatalf("history keeps %q", history.Name)
+	}
+
+	_, err = f.services.Tenders.ChangeStatus(ctx, &f.alice, f.tender.ID, models.TenderStatusClosed, nil)
+	check(t, err)
+	_, err = f.services.Bids.Edit(ctx, &f.carol, bid.ID, input, nil)
+	expectError(t, err, ErrInvalid)
+}
+
+func TestSubmitDecision(t *testing.T) {
+	f := newFixture(t)
+	road := f.publishBid(t, f.carol, f.createBid(t, f.carol, "Road"))
+	river := f.publishBid(t, f.carol, f.createBid(t, f.carol, "River"))
+
+	_, _, err := f.services.Bids.SubmitDecision(ctx, &f.carol, road.ID, models.DecisionApproved)
+	expectError(t, err, ErrForbidden)
+
+	// Ответственных двое, поэтому первого согласования мало.
+	bid, _, err := f.services.Bids.SubmitDecision(ctx, &f.alice, road.ID, models.DecisionApproved)
+	check(t, err)
+	if bid.Decision != nil {
+		t.Fatalf("decided before quorum: %s", *bid.Decision)
+	}
+
+	bid, tender, err := f.services.Bids.SubmitDecision(ctx, &f.bob, road.ID, models.DecisionApproved)
+	check(t, err)
+	if bid.Decision == nil || *bid.Decision != models.DecisionApproved {
+		t.Fatalf("decision %v", bid.Decision)
+	}
+	if tender.Status != models.TenderStatusClosed {
+		t.Fatalf("tender is %s", tender.Status)
+	}
+
+	winner, err := f.repositories.Bids.Winner(ctx, f.tender.ID)
+	check(t, err)
+	if winner.ID != road.ID {
+		t.Fatalf("winner %s", winner.Name)
+	}
+	loser, err := f.repositories.Bids.FindByID(ctx, river.ID)
+	check(t, err)
+	if loser.Selected == nil || *loser.Selected {
+		t.Fatalf("river selected %v", loser.Selected)
+	}
+
+	// Голос без кворума не меняет предложение, решение и выбор меняют
+	// версию: прежний ETag больше не подходит.
+	if winner.Version != road.Version+1 || loser.Version != river.Version+1 {
+		t.Fatalf("versions after decision: road %d, river %d", winner.Version, loser.Version)
+	}
+
+	_, _, err = f.services.Bids.SubmitDecision(ctx, &f.alice, river.ID, models.DecisionApproved)
+	expectError(t, err, ErrInvalid)
+}
+
+func TestSubmitDecisionRejected(t *testing.T) {
+	f := newFixture(t)
+	road := f.publishBid(t, f.carol, f.createBid(t, f.carol, "Road"))
+
+	bid, tender, err := f.services.Bids.SubmitDecision(ctx, &f.alice, road.ID, models.DecisionRejected)
+	check(t, err)
+	if bid.Decision == nil || *bid.Decision != models.DecisionRejected {
+		t.Fatalf("decision %v", bid.Decision)
+	}
+	if tender.Status != models.TenderStatusPublished {
+		t.Fatalf("tender is %s", tender.Status)
+	}
+}
+
+func TestSubmitDecisionRequiresPublishedBid(t *testing.T) {
+	f := newFixture(t)
+	draft := f.createBid(t, f.carol, "Draft")
+	canceled := f.createBid(t, f.carol, "Canceled")
+	_, _, err := f.services.Bids.ChangeStatus(ctx, &f.carol, canceled.ID, "Canceled", nil)
+	check(t, err)
+
+	for _, bid := range []models.Bid{draft, canceled} {
+		for _, responsible := range []models.Employee{f.alice, f.bob} {
+			_, _, err := f.services.Bids.SubmitDecision(ctx, &responsible, bid.ID, models.DecisionApproved)
+			expectError(t, err, ErrInvalid)
+		}
+		votes, err := f.repositories.Bids.Votes(ctx, bid.ID)
+		check(t, err)
+		if len(votes) != 0 {
+			t.Fatalf("%s bid has %d votes", bid.Name, len(votes))
+		}
+	}
+
+	if _, err := f.repositories.Bids.Winner(ctx, f.tender.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("tender has a winner: %v", err)
+	}
+}
+
+func TestSendFeedback(t *testing.T) {
+	f := newFixture(t)
+	sealed := f.createTender(t, models.Tender{
+		Name:               "Sealed",
+		Description:        "Sealed",
+		ServiceType:        "Delivery",
+		Status:             models.TenderStatusPublished,
+		Sealed:             true,
+		SubmissionDeadline: pointer(time.Now().Add(time.Hour)),
+	})
+	f.tender = sealed
+	hidden := f.createBid(t, f.carol, "Hidden")
+
+	_, err := f.services.Bids.SendFeedback(ctx, &f.alice, hidden.ID, "Too expensive")
+	expectError(t, err, ErrInvalid)
+
+	f.tender = f.createTender(t, models.Tender{
+		Name:        "Open",
+		Description: "Open",
+		ServiceType: "Delivery",
+		Status:      models.TenderStatusPublished,
+	})
+	open := f.createBid(t, f.carol, "Open")
+
+	_, err = f.services.Bids.SendFeedback(ctx, &f.carol, open.ID, "Too expensive")
+	expectError(t, err, ErrForbidden)
+
+	_, err = f.services.Bids.SendFeedback(ctx, &f.alice, open.ID, "Too expensive")
+	check(t, err)
+	notifications := f.notifications(t, f.carol)
+	if len(notifications) != 1 || notifications[0].Type != "bid.feedback" {
+		t.Fatalf("carol notifications %+v", notifications)
+	}
+}
+
+func TestScoreBid(t *testing.T) {
+	f := newFixture(t)
+	bid := f.createBid(t, f.carol, "Road")
+
+	criteria, err := f.services.Evaluations.SetCriteria(ctx, &f.alice, f.tender.ID, []schemas.TenderCriterionRequest{
+		{Name: "Price", Weight: 2},
+		{Name: "Quality", Weight: 1},
+	})
+	check(t, err)
+	price := criteria[0].ID.String()
+
+	tests := []struct {
+		name     string
+		employee models.Employee
+		scores   []schemas.BidScoreRequest
+		kind     error
+	}{
+		{
+			name:     "outsider",
+			employee: f.carol,
+			scores:   []schemas.BidScoreRequest{{CriterionID: price, Score: 5}},
+			kind:     ErrForbidden,
+		},
+		{
+			name:     "foreign criterion",
+			employee: f.alice,
+			scores:   []schemas.BidScoreRequest{{CriterionID: uuid.NewString(), Score: 5}},
+			kind:     ErrInvalid,
+		},
+		{
+			name:     "duplicate criterion",
+			employee: f.alice,
+			scores:   []schemas.BidScoreRequest{{CriterionID: price, Score: 5}, {CriterionID: price, Score: 7}},
+			kind:     ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := f.services.Evaluations.Score(ctx, &test.employee, bid.ID, test.scores)
+			expectError(t, err, test.kind)
+		})
+	}
+
+	scores, err := f.repositories.Evaluations.Scores(ctx, f.tender.ID)
+	check(t, err)
+	if len(scores) != 0 {
+		t.Fatalf("rejected scores were saved: %d", len(scores))
+	}
+
+	check(t, f.services.Evaluations.Score(ctx, &f.alice, bid.ID, []schemas.BidScoreRequest{{CriterionID: price, Score: 5}}))
+	check(t, f.services.Evaluations.Score(ctx, &f.alice, bid.ID, []schemas.BidScoreRequest{{CriterionID: price, Score: 8}}))
+	scores, err = f.repositories.Evaluations.Scores(ctx, f.tender.ID)
+	check(t, err)
+	if len(scores) != 1 || scores[0].Score != 8 {
+		t.Fatalf("scores %+v", scores)
+	}
+}
+
+func TestSetCriteria(t *testing.T) {
+	f := newFixture(t)
+	bid := f.createBid(t, f.carol, "Road")
+	input := []schemas.TenderCriterionRequest{{Name: "Price", Weight: 1}}
+
+	_, err := f.services.Evaluations.SetCriteria(ctx, &f.carol, f.tender.ID, input)
+	expectError(t, err, ErrForbidden)
+
+	_, err = f.services.Evaluations.SetCriteria(ctx, &f.alice, uuid.New(), input)
+	expectError(t, err, ErrNotFound)
+
+	criteria, err := f.services.Evaluations.SetCriteria(ctx, &f.alice, f.tender.ID, input)
+	check(t, err)
+	check(t, f.services.Evaluations.Score(ctx, &f.bob, bid.ID, []schemas.BidScoreRequest{
+		{CriterionID: criteria[0].ID.String(), Score: 3},
+	}))
+
+	_, err = f.services.Evaluations.SetCriteria(ctx, &f.alice, f.tender.ID, input)
+	expectError(t, err, ErrInvalid)
+}
+
+// Замена критериев и оценка выполняются под блокировкой тендера: либо
+// оценка сохраняется по действующему критерию, либо критерии заменяются
+// до неё, и оценка по удалённому критерию отклоняется.
+func TestSetCriteriaRacesWithScoring(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		f := newFixture(t)
+		bid := f.createBid(t, f.carol, "Road")
+		criteria, err := f.services.Evaluations.SetCriteria(ctx, &f.alice, f.tender.ID, []schemas.TenderCriterionRequest{{Name: "Price", Weight: 1}})
+		check(t, err)
+
+		var wg sync.WaitGroup
+		var replaceErr, scoreErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, replaceErr = f.services.Evaluations.SetCriteria(ctx, &f.alice, f.tender.ID, []schemas.TenderCriterionRequest{{Name: "Quality", Weight: 1}})
+		}()
+		go func() {
+			defer wg.Done()
+			scoreErr = f.services.Evaluations.Score(ctx, &f.bob, bid.ID, []schemas.BidScoreRequest{
+				{CriterionID: criteria[0].ID.String(), Score: 3},
+			})
+		}()
+		wg.Wait()
+
+		if (replaceErr == nil) == (scoreErr == nil) {
+			t.Fatalf("replace: %v, score: %v", replaceErr, scoreErr)
+		}
+		expectError(t, errors.Join(replaceErr, scoreErr), ErrInvalid)
+
+		scores, err := f.repositories.Evaluations.CountScores(ctx, f.tender.ID)
+		check(t, err)
+		want := int64(0)
+		if scoreErr == nil {
+			want = 1
+		}
+		if scores != want {
+			t.Fatalf("%d scores, want %d", scores, want)
+		}
+	}
+}
+
+func TestBidRollbackKeepsDecision(t *testing.T) {
+	f := newFixture(t)
+	bid := f.publishBid(t, f.carol, f.createBid(t, f.carol, "Road"))
+
+	_, err := f.services.Bids.Edit(ctx, &f.carol, bid.ID, schemas.BidEditRequest{Name: "Road 2", Description: "Road 2"}, nil)
+	check(t, err)
+	_, _, err = f.services.Bids.SubmitDecision(ctx, &f.alice, bid.ID, models.DecisionRejected)
+	check(t, err)
+
+	_, err = f.services.Bids.Rollback(ctx, &f.alice, bid.ID, 2, nil)
+	expectError(t, err, ErrForbidden)
+
+	rolledBack, err := f.services.Bids.Rollback(ctx, &f.carol, bid.ID, 2, nil)
+	check(t, err)
+	if rolledBack.Name != "Road" || rolledBack.Version != 5 {
+		t.Fatalf("rolled back to %q version %d", rolledBack.Name, rolledBack.Version)
+	}
+	if rolledBack.Decision == nil || *rolledBack.Decision != models.DecisionRejected {
+		t.Fatalf("rollback changed decision to %v", rolledBack.Decision)
+	}
+	if !rolledBack.CreatedAt.Equal(bid.CreatedAt) {
+		t.Fatalf("rollback changed createdAt from %v to %v", bid.CreatedAt, rolledBack.CreatedAt)
+	}
+}
+
+func TestBidChangesAfterSubmissionCloses(t *testing.T) {
+	f := newFixture(t)
+	f.tender = f.createTender(t, models.Tender{
+		Name:        "Bridge",
+		Description: "Build a bridge",
+		ServiceType: "Construction",
+		Status:      models.TenderStatusPublished,
+		MaxBudget:   pointer(decimal.NewFromInt(1000)),
+		Currency:    "RUB",
+	})
+	bid, err := f.services.Bids.Create(ctx, &f.carol, schemas.BidCreateRequest{
+		Name:        "Road",
+		Description: "Road",
+		TenderID:    f.tender.ID.String(),
+		AuthorType:  "User",
+		Price:       pointer(decimal.NewFromInt(900)),
+		Currency:    "RUB",
+	})
+	check(t, err)
+	_, err = f.services.Bids.Edit(ctx, &f.carol, bid.ID, schemas.BidEditRequest{Name: "Road", Description: "Road", Price: pointer(decimal.NewFromInt(500))}, nil)
+	check(t, err)
+	_, _, err = f.services.Bids.ChangeStatus(ctx, &f.carol, bid.ID, "Canceled", nil)
+	check(t, err)
+
+	// Версия 1 с ценой 900 больше не укладывается в бюджет.
+	_, err = f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, schemas.TenderUpdateRequest{MaxBudget: pointer(decimal.NewFromInt(800))}, nil)
+	check(t, err)
+	_, err = f.services.Bids.Rollback(ctx, &f.carol, bid.ID, 1, nil)
+	expectError(t, err, ErrInvalid)
+
+	_, err = f.services.Tenders.ChangeStatus(ctx, &f.alice, f.tender.ID, models.TenderStatusClosed, nil)
+	check(t, err)
+
+	_, _, err = f.services.Bids.ChangeStatus(ctx, &f.carol, bid.ID, "Published", nil)
+	expectError(t, err, ErrInvalid)
+	_, _, err = f.services.Bids.ChangeStatus(ctx, &f.alice, bid.ID, "Published", nil)
+	expectError(t, err, ErrInvalid)
+	_, err = f.services.Bids.Rollback(ctx, &f.carol, bid.ID, 2, nil)
+	expectError(t, err, ErrInvalid)
+
+	stored, err := f.repositories.Bids.FindByID(ctx, bid.ID)
+	check(t, err)
+	if stored.Status != "Canceled" || stored.Version != 3 {
+		t.Fatalf("bid changed to %s version %d", stored.Status, stored.Version)
+	}
+}
+
+func TestSubmitDecisionCountsCurrentResponsibles(t *testing.T) {
+	f := newFixture(t)
+	dave := models.Employee{Username: "dave"}
+	check(t, f.repositories.Employees.Create(ctx, &dave))
+	check(t, f.repositories.Organizations.AddResponsible(ctx, &models.OrganizationResponsible{
+		OrganizationID: f.organization.ID,
+		UserID:         dave.ID,
+	}))
+
+	road := f.publishBid(t, f.carol, f.createBid(t, f.carol, "Road"))
+	river := f.publishBid(t, f.carol, f.createBid(t, f.carol, "River"))
+
+	// Отказ dave отклонил бы предложения, но dave деактивирован, а alice
+	// больше не отвечает за организацию.
+	_, _, err := f.services.Bids.SubmitDecision(ctx, &dave, road.ID, models.DecisionRejected)
+	check(t, err)
+	_, _, err = f.services.Bids.SubmitDecision(ctx, &f.alice, river.ID, models.DecisionRejected)
+	check(t, err)
+
+	dave.DeactivatedAt = pointer(time.Now())
+	check(t, f.repositories.Employees.Save(ctx, &dave))
+	check(t, f.repositories.Organizations.RemoveResponsible(ctx, f.organization.ID, f.alice.ID))
+
+	bid, _, err := f.services.Bids.SubmitDecision(ctx, &f.bob, river.ID, models.DecisionApproved)
+	check(t, err)
+	if bid.Decision == nil || *bid.Decision != models.DecisionApproved {
+		t.Fatalf("river decision %v", bid.Decision)
+	}
+
+	decision, quorum, err := f.services.Bids.AggregateDecision(ctx, road, f.tender)
+	check(t, err)
+	if decision != nil || quorum != 1 {
+		t.Fatalf("road decision %v with quorum %d", decision, quorum)
+	}
+}
+
+func TestTenderWithWinnerCannotReopen(t *testing.T) {
+	f := newFixture(t)
+	bid := f.publishBid(t, f.carol, f.createBid(t, f.carol, "Road"))
+
+	_, err := f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, schemas.TenderUpdateRequest{Name: pointer("Bridge 2")}, nil)
+	check(t, err)
+	for _, responsible := range []models.Employee{f.alice, f.bob} {
+		_, _, err := f.services.Bids.SubmitDecision(ctx, &responsible, bid.ID, models.DecisionApproved)
+		check(t, err)
+	}
+
+	for _, status := range []string{models.TenderStatusPublished, models.TenderStatusCreated} {
+		_, err = f.services.Tenders.ChangeStatus(ctx, &f.alice, f.tender.ID, status, nil)
+		expectError(t, err, ErrInvalid)
+	}
+	_, err = f.services.Tenders.Rollback(ctx, &f.alice, f.tender.ID, 1, nil)
+	expectError(t, err, ErrInvalid)
+
+	stored, err := f.repositories.Tenders.FindByID(ctx, f.tender.ID)
+	check(t, err)
+	if stored.Status != models.TenderStatusClosed {
+		t.Fatalf("tender reopened to %s", stored.Status)
+	}
+
+	// Закрытие без победителя можно отменить.
+	other := f.createTender(t, models.Tender{Name: "Depot", Description: "Depot", ServiceType: "Delivery", Status: models.TenderStatusClosed})
+	_, err = f.services.Tenders.ChangeStatus(ctx, &f.alice, other.ID, models.TenderStatusPublished, nil)
+	check(t, err)
+}
+
+func TestSealedTenderClosedEarlyCannotReopen(t *testing.T) {
+	f := newFixture(t)
+	f.tender = f.createTender(t, models.Tender{
+		Name:               "Sealed",
+		Description:        "Sealed",
+		ServiceType:        "Delivery",
+		Status:             models.TenderStatusPublished,
+		Sealed:             true,
+		SubmissionDeadline: pointer(time.Now().Add(time.Hour)),
+	})
+	f.createBid(t, f.carol, "Road")
+
+	closed, err := f.services.Tenders.ChangeStatus(ctx, &f.alice, f.tender.ID, models.TenderStatusClosed, nil)
+	check(t, err)
+
+	// Закрытие вскрыло предложения: организация видит их содержимое.
+	if closed.IsSealed(time.Now()) {
+		t.Fatal("closed tender is still sealed")
+	}
+	bids, err := f.repositories.Bids.List(ctx, repository.BidFilter{TenderID: &f.tender.ID}, repository.BidOrderCreated, pagination.Page{Limit: pagination.MaxLimit})
+	check(t, err)
+	if len(bids.Items) != 1 || bids.Items[0].Name != "Road" {
+		t.Fatalf("bids after closing %+v", bids.Items)
+	}
+
+	for _, status := range []string{models.TenderStatusPublished, models.TenderStatusCreated} {
+		_, err = f.services.Tenders.ChangeStatus(ctx, &f.alice, f.tender.ID, status, nil)
+		expectError(t, err, ErrInvalid)
+	}
+	_, err = f.services.Tenders.Rollback(ctx, &f.alice, f.tender.ID, closed.Version-1, nil)
+	expectError(t, err, ErrInvalid)
+
+	stored, err := f.repositories.Tenders.FindByID(ctx, f.tender.ID)
+	check(t, err)
+	if stored.Status != models.TenderStatusClosed {
+		t.Fatalf("tender reopened to %s", stored.Status)
+	}
+}
+
+func TestTenderOwners(t *testing.T) {
+	f := newFixture(t)
+	update := schemas.TenderUpdateRequest{Description: pointer("Owned")}
+
+	_, err := f.services.Tenders.SetOwners(ctx, &f.alice, f.tender.ID, []string{"carol"})
+	expectError(t, err, ErrInvalid)
+	_, err = f.services.Tenders.SetOwners(ctx, &f.alice, f.tender.ID, []string{"nobody"})
+	expectError(t, err, ErrInvalid)
+
+	owners, err := f.services.Tenders.SetOwners(ctx, &f.alice, f.tender.ID, []string{"bob"})
+	check(t, err)
+	if len(owners) != 1 || owners[0].ID != f.bob.ID {
+		t.Fatalf("owners %+v", owners)
+	}
+
+	_, err = f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, update, nil)
+	expectError(t, err, ErrForbidden)
+	_, err = f.services.Tenders.Update(ctx, &f.bob, f.tender.ID, update, nil)
+	check(t, err)
+
+	// Если единственный владелец деактивирован, тендером снова управляют
+	// все активные ответственные.
+	f.bob.DeactivatedAt = pointer(time.Now())
+	check(t, f.repositories.Employees.Save(ctx, &f.bob))
+	_, err = f.services.Tenders.Update(ctx, &f.alice, f.tender.ID, update, nil)
+	check(t, err)
+	_, err = f.services.Tenders.Update(ctx, &f.bob, f.tender.ID, update, nil)
+	expectError(t, err, ErrForbidden)
+}
+
+func TestTenderTransferCreator(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.services.Tenders.TransferCreator(ctx, &f.alice, f.tender.ID, "carol", nil)
+	expectError(t, err, ErrInvalid)
+	_, err = f.services.Tenders.TransferCreator(ctx, &f.carol, f.tender.ID, "bob", nil)
+	expectError(t, err, ErrForbidden)
+
+	tender, err := f.services.Tenders.TransferCreator(ctx, &f.alice, f.tender.ID, "bob", nil)
+	check(t, err)
+	if tender.CreatorUsername != "bob" || tender.Version != 2 {
+		t.Fatalf("creator %s version %d", tender.CreatorUsername, tender.Version)
+	}
+
+	history, err := f.repositories.Tenders.FindHistory(ctx, f.tender.ID, 1)
+	check(t, err)
+	if history.CreatorUsername != "alice" {
+		t.Fatalf("history keeps creator %s", history.CreatorUsername)
+	}
+}
+
+func TestAuditIsWrittenWithChanges(t *testing.T) {
+	f := newFixture(t)
+
+	tender, err := f.services.Tenders.Create(ctx, &f.alice, models.Tender{
+		Name:           "Depot",
+		Description:    "Depot",
+		ServiceType:    "Delivery",
+		OrganizationID: f.organization.ID,
+	})
+	check(t, err)
+
+	// Отклонённые изменения в журнал не попадают.
+	_, err = f.services.Tenders.Update(ctx, &f.carol, tender.ID, schemas.TenderUpdateRequest{Name: pointer("Depot 2")}, nil)
+	expectError(t, err, ErrForbidden)
+	_, err = f.services.Tenders.Update(ctx, &f.alice, tender.ID, schemas.TenderUpdateRequest{MaxBudget: pointer(decimal.NewFromInt(-1))}, nil)
+	expectError(t, err, ErrInvalid)
+
+	_, err = f.services.Tenders.Update(ctx, &f.alice, tender.ID, schemas.TenderUpdateRequest{Name: pointer("Depot 2")}, nil)
+	check(t, err)
+	_, err = f.services.Tenders.SetOwners(ctx, &f.alice, tender.ID, []string{"alice", "bob"})
+	check(t, err)
+	_, err = f.services.Tenders.TransferCreator(ctx, &f.alice, tender.ID, "bob", nil)
+	check(t, err)
+	_, err = f.services.Tenders.ChangeStatus(ctx, &f.bob, tender.ID, models.TenderStatusPublished, nil)
+	check(t, err)
+
+	want := []string{audit.ActionCreate, audit.ActionEdit, audit.ActionOwners, audit.ActionTransfer, audit.ActionStatus}
+	if got := f.actions(t, tender.ID); !slices.Equal(got, want) {
+		t.Fatalf("audit actions %v, want %v", got, want)
+	}
+
+	result, err := f.repositories.Audit.List(ctx, repository.AuditFilter{Action: audit.ActionTransfer}, pagination.Page{Limit: 1})
+	check(t, err)
+	transfer := result.Items[0]
+	if transfer.ActorUsername != "alice" || *transfer.VersionBefore != 2 || *transfer.VersionAfter != 3 {
+		t.Fatalf("transfer record %+v", transfer)
+	}
+}
+
+func TestAuditCoversBidDecisions(t *testing.T) {
+	f := newFixture(t)
+	bid := f.publishBid(t, f.carol, f.createBid(t, f.carol, "Road"))
+
+	for _, responsible := range []models.Employee{f.alice, f.bob} {
+		_, _, err := f.services.Bids.SubmitDecision(ctx, &responsible, bid.ID, models.DecisionApproved)
+		check(t, err)
+	}
+
+	want := []string{audit.ActionCreate, audit.ActionStatus, audit.ActionDecision, audit.ActionDecision}
+	if got := f.actions(t, bid.ID); !slices.Equal(got, want) {
+		t.Fatalf("bid audit actions %v, want %v", got, want)
+	}
+	if got := f.actions(t, f.tender.ID); !slices.Equal(got, []string{audit.ActionStatus}) {
+		t.Fatalf("tender audit actions %v", got)
+	}
+}
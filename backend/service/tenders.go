@@ -0,0 +1,357 @@
+package service
+
+import (
+	"ZADANIE-6105/audit"
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/notifications"
+	"ZADANIE-6105/policy"
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/schemas"
+	"ZADANIE-6105/stream"
+	"ZADANIE-6105/webhooks"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TenderService struct {
+	repositories repository.Repositories
+}
+
+// lockTender загружает тендер и блокирует его до конца транзакции.
+func lockTender(ctx context.Context, repositories repository.Repositories, id uuid.UUID) (models.Tender, error) {
+	tender, err := repositories.Tenders.FindForUpdate(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return tender, notFound("Tender not found")
+	}
+	return tender, err
+}
+
+// manageable проверяет, что сотрудник может менять тендер. reason —
+// сообщение при отказе.
+func manageable(ctx context.Context, repositories repository.Repositories, tender models.Tender, employee *models.Employee, reason string) error {
+	manageable, err := policy.CanManageTender(ctx, repositories, tender, employee.ID)
+	if err != nil {
+		return err
+	}
+	if !manageable {
+		return forbidden(reason)
+	}
+	return nil
+}
+
+// change применяет fn к тендеру в одной транзакции: строка тендера
+// блокируется, права сотрудника проверяются уже под блокировкой, прежнее
+// состояние сохраняется в историю, версия увеличивается на единицу.
+// Параллельные изменения одного тендера выполняются по очереди, поэтому
+// ни одна версия не теряется и не записывается дважды, а отозванные
+// права не успевают устареть. Действие action записывается в журнал
+// аудита в той же транзакции. reason — сообщение при отказе в правах.
+// Ошибка fn откатывает транзакцию.
+func (s *TenderService) change(ctx context.Context, employee *models.Employee, id uuid.UUID, action string, reason string, check VersionCheck, fn func(repositories repository.Repositories, tender *models.Tender) error) (models.Tender, error) {
+	var tender models.Tender
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		var err error
+		if tender, err = lockTender(ctx, r, id); err != nil {
+			return err
+		}
+
+		if err := manageable(ctx, r, tender, employee, reason); err != nil {
+			return err
+		}
+
+		if check != nil && !check(tender.Version) {
+			return ErrVersionMismatch
+		}
+
+		history := models.NewTenderHistory(tender)
+
+		if err := fn(r, &tender); err != nil {
+			return err
+		}
+
+		if err := r.Tenders.CreateHistory(ctx, &history); err != nil {
+			return err
+		}
+
+		tender.Version = history.Version + 1
+		if err := r.Tenders.Save(ctx, &tender); err != nil {
+			return err
+		}
+
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:        action,
+			EntityType:    audit.EntityTender,
+			EntityID:      tender.ID,
+			VersionBefore: history.Version,
+			VersionAfter:  tender.Version,
+			Actor:         employee,
+		}); err != nil {
+			return err
+		}
+
+		return tenderStatusChanged(ctx, r, tender, history.Status)
+	})
+	return tender, err
+}
+
+// tenderStatusChanged сообщает о смене статуса тендера в ленту событий,
+// уведомления и вебхуки.
+func tenderStatusChanged(ctx context.Context, repositories repository.Repositories, tender models.Tender, previousStatus string) error {
+	if err := stream.TenderStatusChanged(ctx, repositories, tender, previousStatus); err != nil {
+		return err
+	}
+	if err := notifications.TenderStatusChanged(ctx, repositories, tender, previousStatus); err != nil {
+		return err
+	}
+	return webhooks.TenderStatusChanged(ctx, repositories, tender, previousStatus)
+}
+
+// validateSchedule проверяет сроки тендера. Новый срок подачи
+// предложений (changedDeadline) не может быть в прошлом.
+func validateSchedule(tender *models.Tender, changedDeadline bool) error {
+	if changedDeadline && tender.SubmissionDeadline != nil && tender.SubmissionDeadline.Before(time.Now()) {
+		return invalid("submissionDeadline must be in the future")
+	}
+	if tender.SubmissionDeadline != nil && tender.PublishAt != nil && !tender.PublishAt.Before(*tender.SubmissionDeadline) {
+		return invalid("publishAt must be before submissionDeadline")
+	}
+	return nil
+}
+
+func validateBudget(tender *models.Tender) error {
+	if tender.MaxBudget == nil {
+		return nil
+	}
+	if !tender.MaxBudget.IsPositive() {
+		return invalid("maxBudget must be positive")
+	}
+	if !tender.MaxBudget.Equal(tender.MaxBudget.Round(2)) {
+		return invalid("maxBudget must have at most 2 decimal places")
+	}
+	if tender.Currency == "" {
+		return invalid("currency is required when maxBudget is set")
+	}
+	return nil
+}
+
+// Create создаёт тендер от имени сотрудника, ответственного за
+// организацию тендера.
+func (s *TenderService) Create(ctx context.Context, employee *models.Employee, tender models.Tender) (models.Tender, error) {
+	tender.CreatorUsername = employee.Username
+
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		if err := responsible(ctx, r, tender, employee, "User is not responsible for the organization"); err != nil {
+			return err
+		}
+
+		if err := tender.Validate(); err != nil {
+			return invalid(err.Error())
+		}
+		if err := validateSchedule(&tender, true); err != nil {
+			return err
+		}
+		if err := validateBudget(&tender); err != nil {
+			return err
+		}
+
+		if err := r.Tenders.Create(ctx, &tender); err != nil {
+			return err
+		}
+		return audit.Write(ctx, r, audit.Event{
+			Action:       audit.ActionCreate,
+			EntityType:   audit.EntityTender,
+			EntityID:     tender.ID,
+			VersionAfter: tender.Version,
+			Actor:        employee,
+		})
+	})
+	return tender, err
+}
+
+// Update меняет переданные поля тендера.
+func (s *TenderService) Update(ctx context.Context, employee *models.Employee, id uuid.UUID, update schemas.TenderUpdateRequest, check VersionCheck) (models.Tender, error) {
+	return s.change(ctx, employee, id, audit.ActionEdit, "Unauthorized to update this tender", check, func(r repository.Repositories, tender *models.Tender) error {
+		if update.Name != nil {
+			tender.Name = *update.Name
+		}
+		if update.Description != nil {
+			tender.Description = *update.Description
+		}
+		if update.ServiceType != nil {
+			tender.ServiceType = *update.ServiceType
+		}
+		if update.MaxBudget != nil {
+			tender.MaxBudget = update.MaxBudget
+		}
+		if update.Currency != nil {
+			tender.Currency = *update.Currency
+		}
+		if update.SubmissionDeadline != nil {
+			tender.SubmissionDeadline = update.SubmissionDeadline
+		}
+		if update.PublishAt != nil {
+			tender.PublishAt = update.PublishAt
+		}
+		if update.Sealed != nil && *update.Sealed != tender.Sealed {
+			// Режим нельзя менять после подачи первого предложения: иначе снятие
+			// запечатывания раскрыло бы предложения до срока.
+			bids, err := r.Bids.Count(ctx, repository.BidFilter{TenderID: &tender.ID})
+			if err != nil {
+				return err
+			}
+			if bids > 0 {
+				return invalid("Sealed mode cannot be changed after bids were submitted")
+			}
+			tender.Sealed = *update.Sealed
+		}
+
+		if err := validateSchedule(tender, update.SubmissionDeadline != nil); err != nil {
+			return err
+		}
+		return validateBudget(tender)
+	})
+}
+
+// checkReopen запрещает выводить из Closed тендер, у которого выбран
+// победитель: иначе по нему можно было бы согласовать второе предложение.
+// Запечатанный тендер, закрытый до срока подачи, тоже не открывается:
+// закрытие вскрыло предложения, и организация видела их цены.
+func checkReopen(ctx context.Context, repositories repository.Repositories, tender models.Tender, status string) error {
+	if tender.Status != models.TenderStatusClosed || status == models.TenderStatusClosed {
+		return nil
+	}
+	if tender.Sealed && !tender.SubmissionClosed(time.Now()) {
+		return invalid("Sealed tender closed before the submission deadline cannot be reopened")
+	}
+	_, err := repositories.Bids.Winner(ctx, tender.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return invalid("Tender with a selected winner cannot be reopened")
+}
+
+// ChangeStatus переводит тендер в статус status.
+func (s *TenderService) ChangeStatus(ctx context.Context, employee *models.Employee, id uuid.UUID, status string, check VersionCheck) (models.Tender, error) {
+	switch status {
+	case models.TenderStatusCreated, models.TenderStatusPublished, models.TenderStatusClosed:
+	default:
+		return models.Tender{}, invalid("Invalid status")
+	}
+
+	return s.change(ctx, employee, id, audit.ActionStatus, "Unauthorized to update this tender", check, func(r repository.Repositories, tender *models.Tender) error {
+		if err := checkReopen(ctx, r, *tender, status); err != nil {
+			return err
+		}
+		tender.Status = status
+		return nil
+	})
+}
+
+// Rollback восстанавливает поля тендера из версии version. Откат
+// записывается как новая версия.
+func (s *TenderService) Rollback(ctx context.Context, employee *models.Employee, id uuid.UUID, version int, check VersionCheck) (models.Tender, error) {
+	return s.change(ctx, employee, id, audit.ActionRollback, "Unauthorized to rollback this tender", check, func(r repository.Repositories, tender *models.Tender) error {
+		history, err := r.Tenders.FindHistory(ctx, tender.ID, version)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("Tender version not found")
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkReopen(ctx, r, *tender, history.Status); err != nil {
+			return err
+		}
+
+		tender.Name = history.Name
+		tender.Description = history.Description
+		tender.ServiceType = history.ServiceType
+		tender.Status = history.Status
+		tender.MaxBudget = history.MaxBudget
+		tender.Currency = history.Currency
+		tender.SubmissionDeadline = history.SubmissionDeadline
+		tender.PublishAt = history.PublishAt
+		return nil
+	})
+}
+
+// SetOwners заменяет список владельцев тендера и возвращает новый список.
+// Владельцами могут быть только активные ответственные за организацию;
+// пустой список возвращает управление всем ответственным.
+func (s *TenderService) SetOwners(ctx context.Context, employee *models.Employee, id uuid.UUID, usernames []string) ([]models.Employee, error) {
+	var owners []models.Employee
+	err := s.repositories.Transaction(ctx, func(r repository.Repositories) error {
+		tender, err := lockTender(ctx, r, id)
+		if err != nil {
+			return err
+		}
+
+		if err := manageable(ctx, r, tender, employee, "Unauthorized to update this tender"); err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(usernames))
+		for _, username := range usernames {
+			owner, err := activeResponsible(ctx, r, tender, username)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, owner.ID)
+		}
+
+		if err := r.Tenders.SetOwners(ctx, tender.ID, ids); err != nil {
+			return err
+		}
+		if err := audit.Write(ctx, r, audit.Event{
+			Action:     audit.ActionOwners,
+			EntityType: audit.EntityTender,
+			EntityID:   tender.ID,
+			Actor:      employee,
+		}); err != nil {
+			return err
+		}
+
+		owners, err = r.Tenders.Owners(ctx, tender.ID)
+		return err
+	})
+	return owners, err
+}
+
+// TransferCreator передаёт роль создателя тендера другому активному
+// ответственному за организацию. Передача записывается как новая версия.
+func (s *TenderService) TransferCreator(ctx context.Context, employee *models.Employee, id uuid.UUID, username string, check VersionCheck) (models.Tender, error) {
+	return s.change(ctx, employee, id, audit.ActionTransfer, "Unauthorized to update this tender", check, func(r repository.Repositories, tender *models.Tender) error {
+		creator, err := activeResponsible(ctx, r, *tender, username)
+		if err != nil {
+			return err
+		}
+		tender.CreatorUsername = creator.Username
+		return nil
+	})
+}
+
+// activeResponsible находит сотрудника по имени и проверяет, что он
+// активен и отвечает за организацию тендера.
+func activeResponsible(ctx context.Context, repositories repository.Repositories, tender models.Tender, username string) (models.Employee, error) {
+	employee, err := repositories.Employees.FindByUsername(ctx, username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return employee, invalid("Employee " + username + " not found")
+	}
+	if err != nil {
+		return employee, err
+	}
+
+	responsible, err := repositories.Organizations.IsResponsible(ctx, tender.OrganizationID, employee.ID)
+	if err != nil {
+		return employee, err
+	}
+	if !responsible || !employee.IsActive() {
+		return employee, invalid("Employee " + username + " must be an active responsible for the organization")
+	}
+	return employee, nil
+}
@@ -0,0 +1,108 @@
+// Package storage хранит файлы вложений тендеров и предложений.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Ошибки Limits.Check.
+var (
+	ErrTooLarge       = errors.New("file is too large")
+	ErrEmpty          = errors.New("file is empty")
+	ErrTypeNotAllowed = errors.New("file type is not allowed")
+)
+
+// Storage — хранилище файлов по ключу. Ключи формирует вызывающий код,
+// например "tenders/<tenderId>/<attachmentId>".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv создаёт хранилище по переменной STORAGE_BACKEND: "local"
+// (по умолчанию, каталог STORAGE_LOCAL_DIR) или "s3" (S3_ENDPOINT,
+// S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY, S3_REGION).
+func FromEnv() (Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./data/attachments"
+		}
+		return NewLocal(dir)
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Region:    region,
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
+
+// Limits — ограничения на загружаемые файлы.
+type Limits struct {
+	MaxSize      int64
+	AllowedTypes map[string]bool
+}
+
+func LimitsFromEnv() Limits {
+	limits := Limits{
+		MaxSize: 20 << 20,
+		AllowedTypes: map[string]bool{
+			"application/pdf":          true,
+			"image/png":                true,
+			"image/jpeg":               true,
+			"text/plain":               true,
+			"text/csv":                 true,
+			"application/vnd.ms-excel": true,
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true,
+			"application/msword": true,
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+			"application/zip": true,
+		},
+	}
+
+	if size, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_SIZE"), 10, 64); err == nil && size > 0 {
+		limits.MaxSize = size
+	}
+
+	return limits
+}
+
+// Check проверяет размер файла и его тип, определённый по первым байтам
+// head, а не по имени или заголовку клиента. Тип возвращается и при
+// ErrTypeNotAllowed, чтобы его можно было назвать в ответе.
+func (l Limits) Check(size int64, head []byte) (*mimetype.MIME, error) {
+	if size > l.MaxSize {
+		return nil, ErrTooLarge
+	}
+	if size == 0 {
+		return nil, ErrEmpty
+	}
+
+	contentType := mimetype.Detect(head)
+	for allowed := range l.AllowedTypes {
+		if contentType.Is(allowed) {
+			return contentType, nil
+		}
+	}
+	return contentType, ErrTypeNotAllowed
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var ctx = context.Background()
+
+func TestLocalRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocal(filepath.Join(root, "attachments"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "tenders/1/2"
+	content := []byte("%PDF-1.4 tender documentation")
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() []byte {
+		t.Helper()
+		file, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	if got := read(); !bytes.Equal(got, content) {
+		t.Fatalf("read %q, want %q", got, content)
+	}
+
+	// Повторная запись заменяет файл целиком и не оставляет временных.
+	replaced := []byte("replaced")
+	if err := store.Put(ctx, key, bytes.NewReader(replaced), int64(len(replaced)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); !bytes.Equal(got, replaced) {
+		t.Fatalf("read %q after replace, want %q", got, replaced)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, "attachments", "tenders", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d files, want 1", len(entries))
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get after delete: %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("second delete: %v", err)
+	}
+}
+
+func TestLocalRejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "/", "../secret", "tenders/../../secret"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Fatalf("Put(%q) succeeded", key)
+		}
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get(%q) = %v, want invalid key", key, err)
+		}
+	}
+}
+
+func TestLimitsCheck(t *testing.T) {
+	limits := Limits{
+		MaxSize:      1024,
+		AllowedTypes: map[string]bool{"application/pdf": true, "image/png": true, "text/plain": true},
+	}
+	pdf := []byte("%PDF-1.4\n%âãÏÓ\n")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	elf := []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+	tests := []struct {
+		name string
+		size int64
+		head []byte
+		want string
+		err  error
+	}{
+		{"pdf", int64(len(pdf)), pdf, "application/pdf", nil},
+		{"png", int64(len(png)), png, "image/png", nil},
+		{"text", 5, []byte("hello"), "text/plain; charset=utf-8", nil},
+		{"size at the limit", 1024, pdf, "application/pdf", nil},
+		{"too large", 1025, pdf, "", ErrTooLarge},
+		{"empty", 0, nil, "", ErrEmpty},
+		{"executable", int64(len(elf)), elf, "application/x-elf", ErrTypeNotAllowed},
+		{"type is detected by content", 4, []byte("PK\x03\x04"), "application/zip", ErrTypeNotAllowed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contentType, err := limits.Check(test.size, test.head)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+			if test.want != "" && contentType.String() != test.want {
+				t.Fatalf("content type %s, want %s", contentType, test.want)
+			}
+		})
+	}
+}
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv("ATTACHMENT_MAX_SIZE", "")
+	if limits := LimitsFromEnv(); limits.MaxSize != 20<<20 || !limits.AllowedTypes["application/pdf"] {
+		t.Fatalf("default limits %+v", limits)
+	}
+
+	t.Setenv("ATTACHMENT_MAX_SIZE", "4096")
+	if limits := LimitsFromEnv(); limits.MaxSize != 4096 {
+		t.Fatalf("max size %d, want 4096", limits.MaxSize)
+	}
+
+	t.Setenv("ATTACHMENT_MAX_SIZE", "-1")
+	if limits := LimitsFromEnv(); limits.MaxSize != 20<<20 {
+		t.Fatalf("max size %d for an invalid value, want the default", limits.MaxSize)
+	}
+}
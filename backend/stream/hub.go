@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Hub слушает Channel на отдельном соединении и будит подписчиков
+// тендера, для которого пришло уведомление.
+type Hub struct {
+	postgresConn string
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan struct{}]struct{}
+}
+
+func NewHub(postgresConn string) *Hub {
+	return &Hub{
+		postgresConn: postgresConn,
+		subscribers:  make(map[uuid.UUID]map[chan struct{}]struct{}),
+	}
+}
+
+// Start слушает уведомления до отмены ctx, переподключаясь при обрыве
+// соединения.
+func (h *Hub) Start(ctx context.Context) {
+	delay := time.Second
+	for {
+		err := h.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Tender event listener failed: %v", err)
+
+		// Пока соединения не было, уведомления могли потеряться:
+		// подписчики перечитают события из таблицы.
+		h.wakeAll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 30*time.Second)
+	}
+}
+
+func (h *Hub) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, h.postgresConn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		tenderID, err := uuid.Parse(notification.Payload)
+		if err != nil {
+			continue
+		}
+		h.wake(tenderID)
+	}
+}
+
+// Subscribe возвращает канал, в который приходит сигнал о новых событиях
+// тендера, и функцию отписки. Сигналы не накапливаются: после сигнала
+// подписчик должен сам дочитать все новые события.
+func (h *Hub) Subscribe(tenderID uuid.UUID) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subscribers[tenderID] == nil {
+		h.subscribers[tenderID] = make(map[chan struct{}]struct{})
+	}
+	h.subscribers[tenderID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers[tenderID], ch)
+		if len(h.subscribers[tenderID]) == 0 {
+			delete(h.subscribers, tenderID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *Hub) wake(tenderID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[tenderID] {
+		signal(ch)
+	}
+}
+
+func (h *Hub) wakeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subscribers := range h.subscribers {
+		for ch := range subscribers {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,80 @@
+// Package stream ведёт ленту активности тендеров для Server-Sent Events.
+//
+// События пишутся в таблицу tender_event в транзакции изменения (Publish),
+// и в той же транзакции отправляется pg_notify. Postgres доставляет
+// уведомление только после фиксации, поэтому Hub каждой реплики будит
+// подписчиков тендера, когда событие уже можно прочитать. Сами события
+// подписчики читают из таблицы по Seq, так что пропущенное уведомление
+// не теряет событий. Seq идёт подряд внутри тендера и выдаётся под
+// блокировкой тендера, поэтому события фиксируются в порядке номеров.
+package stream
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channel — канал LISTEN/NOTIFY, полезная нагрузка — ID тендера.
+const Channel = repository.EventChannel
+
+const (
+	EventBidCreated   = "bid.created"
+	EventBidEdited    = "bid.edited"
+	EventBidStatus    = "bid.status"
+	EventTenderStatus = "tender.status"
+)
+
+type tenderStatusData struct {
+	ID             uuid.UUID `json:"id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previousStatus"`
+	Version        int       `json:"version"`
+}
+
+// Publish сохраняет событие и уведомляет реплики. Вызывается внутри
+// транзакции изменения, заблокировавшей тендер.
+func Publish(ctx context.Context, repositories repository.Repositories, event models.TenderEvent, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event.Payload = payload
+	event.CreatedAt = time.Now()
+
+	return repositories.Events.Publish(ctx, &event)
+}
+
+// TenderStatusChanged публикует смену статуса тендера. Событие видно всем,
+// если тендер был или стал опубликованным: участники узнают и о закрытии.
+func TenderStatusChanged(ctx context.Context, repositories repository.Repositories, tender models.Tender, previousStatus string) error {
+	if tender.Status == previousStatus {
+		return nil
+	}
+
+	return Publish(ctx, repositories, models.TenderEvent{
+		TenderID: tender.ID,
+		Type:     EventTenderStatus,
+		Public:   tender.Status == models.TenderStatusPublished || previousStatus == models.TenderStatusPublished,
+	}, tenderStatusData{
+		ID:             tender.ID,
+		Status:         tender.Status,
+		PreviousStatus: previousStatus,
+		Version:        tender.Version,
+	})
+}
+
+// BidChanged публикует событие предложения. data — представление
+// предложения в ответе API.
+func BidChanged(ctx context.Context, repositories repository.Repositories, eventType string, bid models.Bid, data interface{}) error {
+	return Publish(ctx, repositories, models.TenderEvent{
+		TenderID:    bid.TenderID,
+		Type:        eventType,
+		BidID:       &bid.ID,
+		BidAuthorID: &bid.AuthorID,
+	}, data)
+}
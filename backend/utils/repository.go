@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"ZADANIE-6105/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetRepositories(c *gin.Context) (repository.Repositories, bool) {
+	value, exists := c.Get("repositories")
+	if !exists {
+		c.JSON(500, gin.H{"reason": "repositories not found"})
+		return repository.Repositories{}, false
+	}
+
+	repositories, ok := value.(repository.Repositories)
+	if !ok {
+		c.JSON(500, gin.H{"reason": "invalid repositories"})
+		return repository.Repositories{}, false
+	}
+
+	return repositories, true
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"ZADANIE-6105/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetServices(c *gin.Context) (service.Services, bool) {
+	value, exists := c.Get("services")
+	if !exists {
+		c.JSON(500, gin.H{"reason": "services not found"})
+		return service.Services{}, false
+	}
+
+	services, ok := value.(service.Services)
+	if !ok {
+		c.JSON(500, gin.H{"reason": "invalid services"})
+		return service.Services{}, false
+	}
+
+	return services, true
+}
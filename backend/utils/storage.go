@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"ZADANIE-6105/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetStorage(c *gin.Context) (storage.Storage, bool) {
+	value, exists := c.Get("storage")
+	if !exists {
+		c.JSON(500, gin.H{"reason": "file storage not found"})
+		return nil, false
+	}
+
+	store, ok := value.(storage.Storage)
+	if !ok {
+		c.JSON(500, gin.H{"reason": "invalid file storage"})
+		return nil, false
+	}
+
+	return store, true
+}
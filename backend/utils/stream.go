@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"ZADANIE-6105/stream"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetHub(c *gin.Context) (*stream.Hub, bool) {
+	value, exists := c.Get("hub")
+	if !exists {
+		c.JSON(500, gin.H{"reason": "event hub not found"})
+		return nil, false
+	}
+
+	hub, ok := value.(*stream.Hub)
+	if !ok {
+		c.JSON(500, gin.H{"reason": "invalid event hub"})
+		return nil, false
+	}
+
+	return hub, true
+}
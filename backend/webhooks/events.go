@@ -0,0 +1,126 @@
+// Package webhooks доставляет события тендеров и предложений на вебхуки
+// организаций.
+//
+// События пишутся в таблицу outbox_event в той же транзакции, что и само
+// изменение (Enqueue), поэтому событие не теряется при откате и не
+// появляется без изменения. Worker раскладывает события по подпискам и
+// отправляет их с подписью HMAC-SHA256 и повторами с экспоненциальной
+// задержкой. Доставки, исчерпавшие попытки, остаются в dead-letter.
+package webhooks
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/repository"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventTenderPublished = "tender.published"
+	EventTenderClosed    = "tender.closed"
+	EventBidCreated      = "bid.created"
+	EventBidDecided      = "bid.decided"
+)
+
+// EventTypes — события, на которые можно подписать вебхук.
+var EventTypes = []string{EventTenderPublished, EventTenderClosed, EventBidCreated, EventBidDecided}
+
+// Envelope — тело запроса доставки.
+type Envelope struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+type tenderData struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+	Status         string    `json:"status"`
+	Version        int       `json:"version"`
+}
+
+// В событиях предложений нет названия и цены: тендер может быть
+// запечатан, и организация не должна видеть их до срока.
+type bidData struct {
+	ID       uuid.UUID `json:"id"`
+	TenderID uuid.UUID `json:"tenderId"`
+	Status   string    `json:"status"`
+	Version  int       `json:"version"`
+	Decision *string   `json:"decision,omitempty"`
+}
+
+// Enqueue записывает событие в outbox. Вызывается внутри транзакции
+// изменения.
+func Enqueue(ctx context.Context, repositories repository.Repositories, eventType string, organizationID uuid.UUID, data interface{}) error {
+	event := models.OutboxEvent{
+		ID:             uuid.New(),
+		EventType:      eventType,
+		OrganizationID: organizationID,
+		CreatedAt:      time.Now(),
+	}
+
+	payload, err := json.Marshal(Envelope{ID: event.ID, Type: eventType, CreatedAt: event.CreatedAt, Data: data})
+	if err != nil {
+		return err
+	}
+	event.Payload = payload
+
+	return repositories.Webhooks.Enqueue(ctx, &event)
+}
+
+// TenderStatusChanged ставит в очередь tender.published или tender.closed,
+// если тендер перешёл в соответствующий статус из другого.
+func TenderStatusChanged(ctx context.Context, repositories repository.Repositories, tender models.Tender, previousStatus string) error {
+	if tender.Status == previousStatus {
+		return nil
+	}
+
+	var eventType string
+	switch tender.Status {
+	case models.TenderStatusPublished:
+		eventType = EventTenderPublished
+	case models.TenderStatusClosed:
+		eventType = EventTenderClosed
+	default:
+		return nil
+	}
+
+	return Enqueue(ctx, repositories, eventType, tender.OrganizationID, tenderData{
+		ID:             tender.ID,
+		OrganizationID: tender.OrganizationID,
+		Name:           tender.Name,
+		Status:         tender.Status,
+		Version:        tender.Version,
+	})
+}
+
+// BidCreated ставит в очередь bid.created для организации тендера.
+func BidCreated(ctx context.Context, repositories repository.Repositories, bid models.Bid, tender models.Tender) error {
+	return Enqueue(ctx, repositories, EventBidCreated, tender.OrganizationID, bidData{
+		ID:       bid.ID,
+		TenderID: bid.TenderID,
+		Status:   bid.Status,
+		Version:  bid.Version,
+	})
+}
+
+// BidDecided ставит в очередь bid.decided, когда итоговое решение по
+// предложению появилось или изменилось.
+func BidDecided(ctx context.Context, repositories repository.Repositories, bid models.Bid, tender models.Tender, previousDecision *string) error {
+	if bid.Decision == nil || (previousDecision != nil && *previousDecision == *bid.Decision) {
+		return nil
+	}
+
+	return Enqueue(ctx, repositories, EventBidDecided, tender.OrganizationID, bidData{
+		ID:       bid.ID,
+		TenderID: bid.TenderID,
+		Status:   bid.Status,
+		Version:  bid.Version,
+		Decision: bid.Decision,
+	})
+}
@@ -0,0 +1,89 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidURL = errors.New("url must be an absolute http or https URL")
+	// ErrForbiddenTarget — адрес вебхука ведёт во внутреннюю сеть: на
+	// loopback, link-local, частные и служебные адреса доставки не идут,
+	// иначе через вебхук можно было бы обращаться к внутренним сервисам.
+	ErrForbiddenTarget = errors.New("url must point to a public address")
+)
+
+// reserved — служебные диапазоны, которые не покрывают методы netip.Addr.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicAddress сообщает, можно ли доставлять вебхуки на адрес ip.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL проверяет адрес вебхука при регистрации: схему http или https
+// и то, что все адреса хоста публичные.
+func CheckURL(ctx context.Context, raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	addresses, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil || len(addresses) == 0 {
+		return ErrForbiddenTarget
+	}
+	for _, address := range addresses {
+		if !publicAddress(address) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// dialControl проверяет адрес уже при подключении, после разрешения имени:
+// имя, прошедшее проверку при регистрации, позже может указывать на
+// внутренний адрес, и так же ведут себя перенаправления.
+func dialControl(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+// newClient возвращает HTTP-клиент доставок, который подключается только к
+// публичным адресам. Прокси из окружения не используется: подключение к
+// нему обошло бы проверку адреса получателя.
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
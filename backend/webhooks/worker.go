@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"ZADANIE-6105/repository"
+	"ZADANIE-6105/retry"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign возвращает подпись доставки: HMAC-SHA256 от "<timestamp>.<body>"
+// на секрете вебхука. Получатель проверяет подпись и отбрасывает старые
+// отметки времени, чтобы защититься от повторов.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IntervalFromEnv возвращает период обработчика доставок из
+// WEBHOOK_INTERVAL (по умолчанию 5 секунд).
+func IntervalFromEnv() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("WEBHOOK_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 5 * time.Second
+	}
+	return interval
+}
+
+// Worker раскладывает события outbox по вебхукам и доставляет их с
+// повторами при ошибках.
+type Worker struct {
+	retry.Worker[repository.PendingDelivery]
+	Repositories repository.Repositories
+	// Client по умолчанию подключается только к публичным адресам.
+	Client *http.Client
+}
+
+// NewWorker создаёт обработчик с настройками по умолчанию.
+func NewWorker(repositories repository.Repositories) *Worker {
+	w := &Worker{
+		Repositories: repositories,
+		Client:       newClient(),
+	}
+	w.Worker = retry.Worker[repository.PendingDelivery]{
+		Name:        "webhook delivery",
+		Queue:       queue{repositories.Webhooks},
+		Run:         w.send,
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    time.Hour,
+		Lease:       time.Minute,
+		BatchSize:   50,
+	}
+	return w
+}
+
+// Start запускает цикл доставки и возвращается после отмены ctx.
+func (w *Worker) Start(ctx context.Context, interval time.Duration) {
+	retry.Every(ctx, interval, "Webhook worker", w.RunOnce)
+}
+
+// RunOnce раскладывает новые события по подпискам и отправляет доставки,
+// срок которых наступил.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	if err := w.Repositories.Webhooks.Dispatch(ctx, w.BatchSize); err != nil {
+		return err
+	}
+	return w.Worker.RunOnce(ctx)
+}
+
+// send выполняет один HTTP-запрос доставки. Успехом считается любой 2xx.
+func (w *Worker) send(ctx context.Context, delivery repository.PendingDelivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(EventHeader, delivery.EventType)
+	request.Header.Set(DeliveryHeader, delivery.ID.String())
+
+	response, err := w.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("receiver responded with %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// queue — очередь доставок в хранилище.
+type queue struct {
+	webhooks repository.WebhookRepository
+}
+
+func (q queue) Lease(ctx context.Context, now time.Time, until time.Time, limit int) ([]repository.PendingDelivery, error) {
+	return q.webhooks.Lease(ctx, now, until, limit)
+}
+
+func (q queue) Record(ctx context.Context, delivery repository.PendingDelivery, attempt repository.Attempt) error {
+	return q.webhooks.Record(ctx, delivery.ID, attempt)
+}
+
+func (queue) Attempts(delivery repository.PendingDelivery) int {
+	return delivery.Attempts
+}
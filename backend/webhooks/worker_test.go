@@ -0,0 +1,275 @@
+package webhooks
+
+import (
+	"ZADANIE-6105/models"
+	"ZADANIE-6105/pagination"
+	"ZADANIE-6105/repository"
+	"context"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ctx = context.Background()
+
+const secret = "receiver-secret"
+
+// receiver — получатель вебхуков, который проверяет подпись и отвечает
+// кодами из statuses по очереди, а затем 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	verified []bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	timestamp, err := strconv.ParseInt(request.Header.Get(TimestampHeader), 10, 64)
+	verified := err == nil && hmac.Equal([]byte(request.Header.Get(SignatureHeader)), []byte(Sign(secret, timestamp, body)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+	r.bodies = append(r.bodies, body)
+	r.verified = append(r.verified, verified)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// newWorker подписывает получателя на EventBidCreated организации и
+// ставит в outbox подходящее событие, событие другого типа и событие
+// другой организации.
+func newWorker(t *testing.T, statuses ...int) (*Worker, *receiver, models.Webhook) {
+	t.Helper()
+	target := &receiver{statuses: statuses}
+	server := httptest.NewServer(target)
+	t.Cleanup(server.Close)
+
+	repositories := repository.NewMemory()
+	webhook := models.Webhook{OrganizationID: uuid.New(), URL: server.URL, Secret: secret, EventTypes: []string{EventBidCreated}}
+	if err := repositories.Webhooks.Create(ctx, &webhook); err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range []models.OutboxEvent{
+		{EventType: EventBidCreated, OrganizationID: webhook.OrganizationID, Payload: []byte(`{"type":"bid.created"}`)},
+		{EventType: EventTenderClosed, OrganizationID: webhook.OrganizationID, Payload: []byte(`{"type":"tender.closed"}`)},
+		{EventType: EventBidCreated, OrganizationID: uuid.New(), Payload: []byte(`{"type":"bid.created"}`)},
+	} {
+		if err := repositories.Webhooks.Enqueue(ctx, &event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	worker := NewWorker(repositories)
+	// Получатель слушает loopback, куда клиент по умолчанию не подключается.
+	worker.Client = server.Client()
+	worker.BaseDelay = time.Minute
+	worker.MaxDelay = time.Hour
+	return worker, target, webhook
+}
+
+// due возвращает доставки, срок которых наступит к at, и делает их
+// доступными сейчас, как будто задержка уже прошла.
+func due(t *testing.T, worker *Worker, at time.Time) []repository.PendingDelivery {
+	t.Helper()
+	deliveries, err := worker.Repositories.Webhooks.Lease(ctx, at, time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+// later — момент, к которому наступит срок любой доставки.
+func later() time.Time {
+	return time.Now().Add(24 * time.Hour)
+}
+
+func TestWorkerSignsDeliveries(t *testing.T) {
+	worker, target, webhook := newWorker(t)
+
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if target.count() != 1 {
+		t.Fatalf("receiver got %d requests, want only the subscribed event", target.count())
+	}
+	request, body := target.requests[0], target.bodies[0]
+	if !target.verified[0] {
+		t.Fatal("receiver could not verify the signature")
+	}
+	if string(body) != `{"type":"bid.created"}` || request.Header.Get(EventHeader) != EventBidCreated || request.Header.Get(DeliveryHeader) == "" {
+		t.Fatalf("delivery %s with headers %v", body, request.Header)
+	}
+
+	// Подпись с другим секретом, по изменённому телу или с другой
+	// отметкой времени не проходит.
+	timestamp, _ := strconv.ParseInt(request.Header.Get(TimestampHeader), 10, 64)
+	signature := request.Header.Get(SignatureHeader)
+	if signature == Sign("other-secret", timestamp, body) || signature == Sign(webhook.Secret, timestamp, []byte(`{}`)) || signature == Sign(webhook.Secret, timestamp+1, body) {
+		t.Fatal("signature does not depend on secret, body and timestamp")
+	}
+
+	if deliveries := due(t, worker, later()); len(deliveries) != 0 {
+		t.Fatalf("delivered event is queued again: %+v", deliveries)
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	worker, target, _ := newWorker(t, http.StatusServiceUnavailable, http.StatusInternalServerError)
+
+	start := time.Now()
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// До истечения задержки доставка не повторяется.
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if target.count() != 1 {
+		t.Fatalf("receiver got %d requests before the backoff elapsed, want 1", target.count())
+	}
+
+	if deliveries := due(t, worker, start.Add(worker.BaseDelay-time.Second)); len(deliveries) != 0 {
+		t.Fatal("delivery is due before the first backoff")
+	}
+	deliveries := due(t, worker, start.Add(worker.BaseDelay+time.Second))
+	if len(deliveries) != 1 {
+		t.Fatalf("%d due deliveries after the first backoff, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Attempts != 1 || delivery.LastStatus != http.StatusServiceUnavailable || delivery.LastError == "" {
+		t.Fatalf("delivery after the first attempt %+v", delivery.WebhookDelivery)
+	}
+
+	// Вторая неудача удваивает задержку.
+	start = time.Now()
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if deliveries := due(t, worker, start.Add(2*worker.BaseDelay-time.Second)); len(deliveries) != 0 {
+		t.Fatal("delivery is due before the second backoff")
+	}
+	deliveries = due(t, worker, start.Add(2*worker.BaseDelay+time.Second))
+	if len(deliveries) != 1 {
+		t.Fatalf("%d due deliveries after the second backoff, want 1", len(deliveries))
+	}
+	if delivery := deliveries[0]; delivery.Attempts != 2 || delivery.LastStatus != http.StatusInternalServerError {
+		t.Fatalf("delivery after the second attempt %+v", delivery.WebhookDelivery)
+	}
+
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if target.count() != 3 {
+		t.Fatalf("receiver got %d requests, want 3", target.count())
+	}
+	for i, verified := range target.verified {
+		if !verified {
+			t.Fatalf("request %d has an invalid signature", i)
+		}
+	}
+	if deliveries := due(t, worker, later()); len(deliveries) != 0 {
+		t.Fatalf("delivered event is queued again: %+v", deliveries)
+	}
+}
+
+func TestWorkerMovesExhaustedDeliveriesToDeadLetters(t *testing.T) {
+	worker, target, webhook := newWorker(t, http.StatusBadGateway, http.StatusBadGateway)
+	worker.MaxAttempts = 2
+
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	due(t, worker, later())
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if target.count() != 2 {
+		t.Fatalf("receiver got %d requests, want 2", target.count())
+	}
+	if deliveries := due(t, worker, later()); len(deliveries) != 0 {
+		t.Fatalf("exhausted delivery is queued again: %+v", deliveries)
+	}
+
+	letters, err := worker.Repositories.Webhooks.DeadLetters(ctx, webhook.OrganizationID, nil, pagination.Page{Limit: pagination.MaxLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters.Items) != 1 {
+		t.Fatalf("%d dead letters, want 1", len(letters.Items))
+	}
+	letter := letters.Items[0]
+	if letter.Attempts != 2 || letter.LastStatus != http.StatusBadGateway || letter.EventType != EventBidCreated {
+		t.Fatalf("dead letter %+v", letter)
+	}
+}
+
+func TestWorkerRefusesInternalAddresses(t *testing.T) {
+	worker, target, webhook := newWorker(t)
+	worker.Client = NewWorker(worker.Repositories).Client
+
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if target.count() != 0 {
+		t.Fatalf("receiver on %s got %d requests", webhook.URL, target.count())
+	}
+
+	deliveries := due(t, worker, later())
+	if len(deliveries) != 1 || !strings.Contains(deliveries[0].LastError, ErrForbiddenTarget.Error()) {
+		t.Fatalf("deliveries after the refused attempt %+v", deliveries)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		err error
+	}{
+		{"https://93.184.216.34/hooks", nil},
+		{"http://93.184.216.34:8080/hooks", nil},
+		{"ftp://93.184.216.34/hooks", ErrInvalidURL},
+		{"/hooks", ErrInvalidURL},
+		{"http://127.0.0.1:8080/hooks", ErrForbiddenTarget},
+		{"http://localhost/hooks", ErrForbiddenTarget},
+		{"http://[::1]/hooks", ErrForbiddenTarget},
+		{"http://[::ffff:127.0.0.1]/hooks", ErrForbiddenTarget},
+		{"http://0.0.0.0/hooks", ErrForbiddenTarget},
+		{"http://10.1.2.3/hooks", ErrForbiddenTarget},
+		{"http://172.16.0.1/hooks", ErrForbiddenTarget},
+		{"http://192.168.1.1/hooks", ErrForbiddenTarget},
+		{"http://100.64.0.1/hooks", ErrForbiddenTarget},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenTarget},
+		{"http://[fe80::1]/hooks", ErrForbiddenTarget},
+		{"http://[fd00::1]/hooks", ErrForbiddenTarget},
+	}
+
+	for _, test := range tests {
+		if err := CheckURL(ctx, test.url); !errors.Is(err, test.err) {
+			t.Errorf("CheckURL(%q) = %v, want %v", test.url, err, test.err)
+		}
+	}
+}